@@ -0,0 +1,233 @@
+package i2c
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+const am2320Address = 0x5C
+
+const am2320FunctionRead = 0x03
+const am2320RegisterHumidityMSB = 0x00
+
+// the humidity and temperature registers, 2 bytes each.
+const am2320MeasurementRegisters = 4
+
+// the sensor sleeps between measurements: it is woken by a write, which it
+// does not acknowledge, and must be sent the command within 3ms.
+const am2320WakeDelay = 1 * time.Millisecond
+
+// the sensor answers at least 1.5ms after the command.
+const am2320ResponseDelay = 2 * time.Millisecond
+
+// the sign bit of the temperature, which is not two's complement.
+const am2320TempNegative = 0x8000
+
+// AM2320Driver is the gobot driver for the Aosong AM2320 temperature and
+// humidity sensor, the i2c variant of the DHT family.
+// Device datasheet: https://cdn-shop.adafruit.com/product-files/3721/AM2320.pdf
+//
+// The sensor sleeps between measurements, and wakes up for each one. It
+// measures at most every 2 seconds: the values read sooner are the ones of
+// the previous measurement. The values are measured on demand, or polled
+// and published as Temperature and Humidity events when a poll interval is
+// set.
+type AM2320Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	gobot.Eventer
+	interval time.Duration
+	poller   poller
+	mutex    *sync.Mutex
+}
+
+// NewAM2320Driver creates a new driver with the i2c interface for the AM2320 device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithAM2320PollInterval(time.Duration):	interval of the Temperature and Humidity events, defaults to 0 that is no polling
+//
+func NewAM2320Driver(c Connector, options ...func(Config)) *AM2320Driver {
+	a := &AM2320Driver{
+		name:      gobot.DefaultName("AM2320"),
+		connector: c,
+		Config:    NewConfig(),
+		Eventer:   gobot.NewEventer(),
+		mutex:     &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	a.AddEvent(Temperature)
+	a.AddEvent(Humidity)
+	a.AddEvent(Error)
+	return a
+}
+
+// WithAM2320PollInterval option sets the interval at which the driver
+// measures after Start, and publishes the values in Temperature and Humidity
+// events, or an Error event. Intervals under the 2 seconds of the sensor
+// publish the same values again.
+func WithAM2320PollInterval(val time.Duration) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*AM2320Driver)
+		if ok && val > 0 {
+			d.interval = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *AM2320Driver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *AM2320Driver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *AM2320Driver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start connects to the device, which sleeps until the first measurement,
+// and starts polling it if a poll interval is set. It returns
+// ErrAlreadyStarted until Halt once started.
+func (d *AM2320Driver) Start() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.poller.started {
+		return ErrAlreadyStarted
+	}
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(am2320Address)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	return d.poller.start(d.interval, d.poll)
+}
+
+// Halt stops polling the device, if it was, after which the driver can be
+// started again.
+func (d *AM2320Driver) Halt() (err error) {
+	d.mutex.Lock()
+	wait := d.poller.stop()
+	d.mutex.Unlock()
+
+	// the poll locks the mutex to measure, thus is waited for without it.
+	wait()
+	return nil
+}
+
+// SupportsHumidity returns true, the AM2320 measures the relative humidity.
+func (d *AM2320Driver) SupportsHumidity() bool {
+	return true
+}
+
+// Temperature returns the current temperature, in celsius degrees.
+func (d *AM2320Driver) Temperature() (temp float32, err error) {
+	temp, _, err = d.Sample()
+	return temp, err
+}
+
+// Humidity returns the current relative humidity, in percent.
+func (d *AM2320Driver) Humidity() (humidity float32, err error) {
+	_, humidity, err = d.Sample()
+	return humidity, err
+}
+
+// Sample returns the current temperature, in celsius degrees, and relative
+// humidity, in percent, read together.
+func (d *AM2320Driver) Sample() (temp float32, humidity float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	if data, err = d.readRegisters(am2320RegisterHumidityMSB, am2320MeasurementRegisters); err != nil {
+		return 0, 0, err
+	}
+	humidity = float32(uint16(data[0])<<8|uint16(data[1])) / 10
+	return am2320DecodeTemp(uint16(data[2])<<8 | uint16(data[3])), humidity, nil
+}
+
+// readRegisters wakes the sensor up, and reads n registers from the
+// register reg, checking the crc of the response.
+func (d *AM2320Driver) readRegisters(reg byte, n byte) ([]byte, error) {
+	// the sensor does not acknowledge the write waking it up.
+	d.connection.Write([]byte{0x00})
+	time.Sleep(am2320WakeDelay)
+
+	if _, err := d.connection.Write([]byte{am2320FunctionRead, reg, n}); err != nil {
+		return nil, err
+	}
+	time.Sleep(am2320ResponseDelay)
+
+	// the function code and the number of bytes, the registers, and the
+	// crc, low byte first.
+	buf := make([]byte, 2+int(n)+2)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if bytesRead != len(buf) {
+		return nil, ErrNotEnoughBytes
+	}
+	end := 2 + int(n)
+	if crc := uint16(buf[end+1])<<8 | uint16(buf[end]); crc != am2320Crc16(buf[:end]) {
+		return nil, ErrInvalidCrc
+	}
+	if buf[0] != am2320FunctionRead || buf[1] != n {
+		return nil, fmt.Errorf("AM2320 unexpected response (function 0x%02X, length %d)", buf[0], buf[1])
+	}
+	return buf[2:end], nil
+}
+
+func (d *AM2320Driver) poll() {
+	temp, humidity, err := d.Sample()
+	if err != nil {
+		d.Publish(d.Event(Error), err)
+	} else {
+		d.Publish(d.Event(Temperature), temp)
+		d.Publish(d.Event(Humidity), humidity)
+	}
+}
+
+// am2320DecodeTemp returns the temperature, in celsius degrees, of the value
+// of the temperature registers, in tenths of degree with a sign bit.
+func am2320DecodeTemp(val uint16) float32 {
+	temp := float32(val&^am2320TempNegative) / 10
+	if val&am2320TempNegative != 0 {
+		return -temp
+	}
+	return temp
+}
+
+// am2320Crc16 returns the CRC-16/MODBUS of the data.
+func am2320Crc16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&0x0001 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
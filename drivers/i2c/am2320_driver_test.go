@@ -0,0 +1,235 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*AM2320Driver)(nil)
+
+// --------- HELPERS
+func initTestAM2320Driver() (driver *AM2320Driver) {
+	driver, _ = initTestAM2320DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestAM2320DriverWithStubbedAdaptor(options ...func(Config)) (*AM2320Driver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewAM2320Driver(adaptor, options...), adaptor
+}
+
+// am2320TestResponse is the response to the read of the humidity and
+// temperature registers: 50% and 25 degrees.
+var am2320TestResponse = []byte{0x03, 0x04, 0x01, 0xF4, 0x00, 0xFA, 0x31, 0xA5}
+
+func am2320TestReadImpl(response []byte) func([]byte) (int, error) {
+	return func(b []byte) (int, error) {
+		copy(b, response)
+		return len(b), nil
+	}
+}
+
+// --------- TESTS
+
+func TestNewAM2320Driver(t *testing.T) {
+	// Does it return a pointer to an instance of AM2320Driver?
+	var am2320 interface{} = NewAM2320Driver(newI2cTestAdaptor())
+	_, ok := am2320.(*AM2320Driver)
+	if !ok {
+		t.Errorf("NewAM2320Driver() should have returned a *AM2320Driver")
+	}
+
+	d := NewAM2320Driver(newI2cTestAdaptor())
+	gobottest.Refute(t, d.Connection(), nil)
+	gobottest.Assert(t, d.SupportsHumidity(), true)
+}
+
+func TestAM2320DriverOptions(t *testing.T) {
+	d := NewAM2320Driver(newI2cTestAdaptor(), WithBus(2), WithAM2320PollInterval(3*time.Second))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.interval, 3*time.Second)
+}
+
+func TestAM2320DriverSetName(t *testing.T) {
+	d := initTestAM2320Driver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "TESTME"), true)
+}
+
+func TestAM2320DriverStart(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, adaptor.address, 0x5C)
+	// the sensor sleeps until the first measurement.
+	gobottest.Assert(t, len(adaptor.written), 0)
+	gobottest.Assert(t, d.Start(), ErrAlreadyStarted)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestAM2320DriverStartConnectError(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestAM2320DriverSample(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = am2320TestReadImpl(am2320TestResponse)
+	d.Start()
+	temp, humidity, err := d.Sample()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(25))
+	gobottest.Assert(t, humidity, float32(50))
+	// the wake-up write, then the read of 4 registers from the humidity.
+	gobottest.Assert(t, adaptor.written, []byte{0x00, 0x03, 0x00, 0x04})
+
+	temp, err = d.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(25))
+	humidity, err = d.Humidity()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, humidity, float32(50))
+}
+
+func TestAM2320DriverSampleNegative(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	// 30% and -10.1 degrees, with the sign bit.
+	adaptor.i2cReadImpl = am2320TestReadImpl([]byte{0x03, 0x04, 0x01, 0x2C, 0x80, 0x65, 0x90, 0x36})
+	d.Start()
+	temp, humidity, err := d.Sample()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(-10.1))
+	gobottest.Assert(t, humidity, float32(30))
+}
+
+func TestAM2320DecodeTemp(t *testing.T) {
+	gobottest.Assert(t, am2320DecodeTemp(0x00FA), float32(25))
+	gobottest.Assert(t, am2320DecodeTemp(0x0000), float32(0))
+	gobottest.Assert(t, am2320DecodeTemp(0x8000), float32(0))
+	gobottest.Assert(t, am2320DecodeTemp(0x8001), float32(-0.1))
+	gobottest.Assert(t, am2320DecodeTemp(0x8190), float32(-40))
+	gobottest.Assert(t, am2320DecodeTemp(0x0320), float32(80))
+}
+
+func TestAM2320Crc16(t *testing.T) {
+	gobottest.Assert(t, am2320Crc16([]byte{0x03, 0x04, 0x01, 0xF4, 0x00, 0xFA}), uint16(0xA531))
+	gobottest.Assert(t, am2320Crc16([]byte{0x03, 0x04, 0x03, 0xE8, 0x80, 0x00}), uint16(0x5810))
+	// the check value of CRC-16/MODBUS.
+	gobottest.Assert(t, am2320Crc16([]byte("123456789")), uint16(0x4B37))
+	gobottest.Assert(t, am2320Crc16([]byte{}), uint16(0xFFFF))
+}
+
+func TestAM2320DriverSampleCrcError(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	response := append([]byte{}, am2320TestResponse...)
+	response[3] = 0xF5
+	adaptor.i2cReadImpl = am2320TestReadImpl(response)
+	d.Start()
+	_, _, err := d.Sample()
+	gobottest.Assert(t, err, ErrInvalidCrc)
+
+	// the crc is low byte first.
+	response = append([]byte{}, am2320TestResponse...)
+	response[6], response[7] = response[7], response[6]
+	adaptor.i2cReadImpl = am2320TestReadImpl(response)
+	_, _, err = d.Sample()
+	gobottest.Assert(t, err, ErrInvalidCrc)
+}
+
+func TestAM2320DriverSampleUnexpectedResponse(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	// an exception response, with a valid crc.
+	response := []byte{0x83, 0x04, 0x00, 0x00, 0x00, 0x00, 0, 0}
+	crc := am2320Crc16(response[:6])
+	response[6], response[7] = byte(crc), byte(crc>>8)
+	adaptor.i2cReadImpl = am2320TestReadImpl(response)
+	d.Start()
+	_, _, err := d.Sample()
+	gobottest.Assert(t, err, errors.New("AM2320 unexpected response (function 0x83, length 4)"))
+}
+
+func TestAM2320DriverSampleError(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	d.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 4, nil
+	}
+	_, _, err := d.Sample()
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, _, err = d.Sample()
+	gobottest.Assert(t, err, errors.New("read error"))
+
+	// only the error of the command write is returned.
+	writes := 0
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		writes++
+		return 0, errors.New("write error")
+	}
+	_, _, err = d.Sample()
+	gobottest.Assert(t, err, errors.New("write error"))
+	gobottest.Assert(t, writes, 2)
+}
+
+func TestAM2320DriverWakeUp(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor()
+	var times []time.Time
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			// no acknowledge while asleep.
+			return 0, errors.New("write error")
+		}
+		return len(b), nil
+	}
+	readImpl := am2320TestReadImpl(am2320TestResponse)
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		times = append(times, time.Now())
+		return readImpl(b)
+	}
+	d.Start()
+	_, _, err := d.Sample()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(times), 3)
+	// the command is sent at least 0.8ms after the wake-up, and the response
+	// read at least 1.5ms after the command.
+	gobottest.Assert(t, times[1].Sub(times[0]) >= 800*time.Microsecond, true)
+	gobottest.Assert(t, times[2].Sub(times[1]) >= 1500*time.Microsecond, true)
+}
+
+func TestAM2320DriverPoll(t *testing.T) {
+	d, adaptor := initTestAM2320DriverWithStubbedAdaptor(WithAM2320PollInterval(time.Millisecond))
+	adaptor.i2cReadImpl = am2320TestReadImpl(am2320TestResponse)
+
+	temps := make(chan float32, 1)
+	humidities := make(chan float32, 1)
+	d.Once(d.Event(Temperature), func(data interface{}) {
+		temps <- data.(float32)
+	})
+	d.Once(d.Event(Humidity), func(data interface{}) {
+		humidities <- data.(float32)
+	})
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case temp := <-temps:
+		gobottest.Assert(t, temp, float32(25))
+	case <-time.After(1 * time.Second):
+		t.Errorf("AM2320 Event \"temperature\" was not published")
+	}
+	select {
+	case humidity := <-humidities:
+		gobottest.Assert(t, humidity, float32(50))
+	case <-time.After(1 * time.Second):
+		t.Errorf("AM2320 Event \"humidity\" was not published")
+	}
+}
@@ -83,6 +83,11 @@ func (d *BME280Driver) Humidity() (humidity float32, err error) {
 	return
 }
 
+// SupportsHumidity returns true, the BME280 measures the humidity.
+func (d *BME280Driver) SupportsHumidity() bool {
+	return true
+}
+
 // read the humidity calibration coefficients.
 func (d *BME280Driver) initHumidity() (err error) {
 	var coefficients []byte
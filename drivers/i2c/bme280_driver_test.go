@@ -76,6 +76,12 @@ func TestBME280DriverHalt(t *testing.T) {
 	gobottest.Assert(t, bme280.Halt(), nil)
 }
 
+func TestBME280DriverSupportsHumidity(t *testing.T) {
+	bme280 := initTestBME280Driver()
+
+	gobottest.Assert(t, bme280.SupportsHumidity(), true)
+}
+
 func TestBME280DriverMeasurements(t *testing.T) {
 	bme280, adaptor := initTestBME280DriverWithStubbedAdaptor()
 	adaptor.i2cReadImpl = func(b []byte) (int, error) {
@@ -3,6 +3,11 @@ package i2c
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -12,12 +17,75 @@ const bmp180Address = 0x77
 
 const bmp180RegisterAC1MSB = 0xAA
 
+const bmp180RegisterChipID = 0xD0
+const bmp180ChipID = 0x55
+
+const bmp180RegisterSoftReset = 0xE0
+const bmp180CmdSoftReset = 0xB6
+
 const bmp180RegisterCtl = 0xF4
 const bmp180CmdTemp = 0x2E
 const bmp180RegisterTempMSB = 0xF6
 const bmp180CmdPressure = 0x34
 const bmp180RegisterPressureMSB = 0xF6
 
+// bmp180Registers are the registers of the BMP180, named as in the
+// datasheet. The calibration coefficients, from 0xAA, are read in a single
+// transaction by initialization, and the 3 bytes of the raw pressure, from
+// out_msb, with the read function.
+var bmp180Registers = NewRegisterMap(
+	Register{Name: "AC1", Address: bmp180RegisterAC1MSB, Width: 2},
+	Register{Name: "AC2", Address: bmp180RegisterAC1MSB + 2, Width: 2},
+	Register{Name: "AC3", Address: bmp180RegisterAC1MSB + 4, Width: 2},
+	Register{Name: "AC4", Address: bmp180RegisterAC1MSB + 6, Width: 2},
+	Register{Name: "AC5", Address: bmp180RegisterAC1MSB + 8, Width: 2},
+	Register{Name: "AC6", Address: bmp180RegisterAC1MSB + 10, Width: 2},
+	Register{Name: "B1", Address: bmp180RegisterAC1MSB + 12, Width: 2},
+	Register{Name: "B2", Address: bmp180RegisterAC1MSB + 14, Width: 2},
+	Register{Name: "MB", Address: bmp180RegisterAC1MSB + 16, Width: 2},
+	Register{Name: "MC", Address: bmp180RegisterAC1MSB + 18, Width: 2},
+	Register{Name: "MD", Address: bmp180RegisterAC1MSB + 20, Width: 2},
+	Register{Name: "id", Address: bmp180RegisterChipID, Width: 1},
+	Register{Name: "soft_reset", Address: bmp180RegisterSoftReset, Width: 1},
+	Register{Name: "ctrl_meas", Address: bmp180RegisterCtl, Width: 1},
+	Register{Name: "out", Address: bmp180RegisterTempMSB, Width: 2},
+)
+
+const bmp180SeaLevelPressure = 101325
+
+const bmp180FeetPerMeter = 3.28084
+
+const pascalsPerInchOfMercury = 3386.389
+const pascalsPerAtmosphere = 101325
+
+const bmp180MaxPressure = 215000
+
+// the operating range of the datasheet.
+const bmp180MinTemp = -40
+const bmp180MaxTemp = 85
+const bmp180MinPlausiblePressure = 30000
+const bmp180MaxPlausiblePressure = 110000
+
+// the typical RMS noise of the pressure in each oversampling mode, in
+// pascals, from the "Overview of BMP180 modes" table of the datasheet.
+var bmp180PressureNoise = [...]float32{6, 5, 4, 3}
+
+const bmp180ReadRetryDelay = 2 * time.Millisecond
+
+// the delay before the first repetition of the initialization, doubled at
+// each of the next ones.
+const bmp180InitRetryDelay = 10 * time.Millisecond
+
+// the default window and deadband of PressureTrend: the 3 hours of the
+// barometric tendency of the weather reports, and 10 Pa an hour.
+const bmp180TrendWindow = 3 * time.Hour
+const bmp180TrendDeadband = 10
+
+// the SCO bit of the control register is set while a conversion runs.
+const bmp180CtlSCO = 0x20
+const bmp180PollInterval = 500 * time.Microsecond
+const bmp180ConversionTimeout = 50 * time.Millisecond
+
 const (
 	// BMP180UltraLowPower is the lowest oversampling mode of the pressure measurement.
 	BMP180UltraLowPower BMP180OversamplingMode = iota
@@ -29,32 +97,275 @@ const (
 	BMP180UltraHighResolution
 )
 
+const (
+	// BMP180WaitFixedDelay waits for the conversion delays before reading
+	// a measurement.
+	BMP180WaitFixedDelay BMP180WaitMode = iota
+	// BMP180WaitPolling reads the control register until the conversion is
+	// complete before reading a measurement.
+	BMP180WaitPolling
+)
+
+const (
+	// BMP180TrendSteady is a pressure changing within the deadband.
+	BMP180TrendSteady BMP180Trend = iota
+	// BMP180TrendRising is a pressure rising faster than the deadband.
+	BMP180TrendRising
+	// BMP180TrendFalling is a pressure falling faster than the deadband.
+	BMP180TrendFalling
+)
+
+const (
+	// BMP180Pascal is the SI unit of pressure, the default of the BMP180.
+	BMP180Pascal BMP180PressureUnit = iota
+	// BMP180Hectopascal is 100 pascals.
+	BMP180Hectopascal
+	// BMP180Millibar is 100 pascals, the same as the hectopascal.
+	BMP180Millibar
+	// BMP180InchOfMercury is 3386.389 pascals, used in aviation in the US.
+	BMP180InchOfMercury
+	// BMP180Atmosphere is the standard atmosphere, 101325 pascals.
+	BMP180Atmosphere
+)
+
+// ErrInvalidCalibration is returned when the calibration coefficients read
+// from the BMP180 are not valid, which usually means a faulty i2c read.
+var ErrInvalidCalibration = errors.New("Invalid calibration data")
+
+// ErrInvalidOversamplingMode is returned when the BMP180 is set to an
+// oversampling mode above BMP180UltraHighResolution.
+var ErrInvalidOversamplingMode = errors.New("Invalid oversampling mode")
+
+// ErrPressureOutOfRange is returned when the raw measurements of the BMP180
+// cannot give a valid pressure, or give one outside of the plausible range,
+// which usually means a faulty i2c read or bad calibration data.
+var ErrPressureOutOfRange = errors.New("Pressure out of range")
+
+// ErrBMP180ConversionTimeout is returned when a conversion of the BMP180
+// does not complete in time, when polling for it.
+var ErrBMP180ConversionTimeout = errors.New("BMP180 conversion timeout")
+
+// ErrInvalidPressureUnit is returned when a pressure is requested in an
+// unknown BMP180PressureUnit.
+var ErrInvalidPressureUnit = errors.New("Invalid pressure unit")
+
+// ErrInvalidMedianWindow is returned when the window of the pressure median
+// filter is not an odd size.
+var ErrInvalidMedianWindow = errors.New("Invalid median filter window")
+
+// ErrInvalidSeaLevelPressure is returned when the pressure at sea level is
+// not positive.
+var ErrInvalidSeaLevelPressure = errors.New("Invalid sea level pressure")
+
+// ErrInvalidAltitude is returned when a sea level pressure is requested for
+// an altitude at or above 44330 m, where the barometric formula gives no
+// pressure.
+var ErrInvalidAltitude = errors.New("Invalid altitude")
+
+// ErrBMP180NoPressure is returned when the altitude is requested before the
+// BMP180 measured a pressure.
+var ErrBMP180NoPressure = errors.New("BMP180 pressure not measured")
+
+// ErrBMP180OperationTimeout is returned when an i2c transaction with the
+// BMP180 does not complete within the operation timeout, or while the
+// abandoned transaction is still pending.
+var ErrBMP180OperationTimeout = errors.New("BMP180 operation timeout")
+
+// ErrTemperatureOutOfRange is returned when the BMP180 measures a
+// temperature outside of the plausible range, which usually means a faulty
+// i2c read or bad calibration data.
+var ErrTemperatureOutOfRange = errors.New("Temperature out of range")
+
+// BMP180PressureUnit is the unit in which a pressure is returned.
+type BMP180PressureUnit uint8
+
+// fromPascals converts the pressure, in pascals, to the unit.
+func (u BMP180PressureUnit) fromPascals(p float32) float32 {
+	switch u {
+	case BMP180Hectopascal, BMP180Millibar:
+		return float32(float64(p) / 100)
+	case BMP180InchOfMercury:
+		return float32(float64(p) / pascalsPerInchOfMercury)
+	case BMP180Atmosphere:
+		return float32(float64(p) / pascalsPerAtmosphere)
+	}
+	return p
+}
+
+// toPascals converts the pressure, in the unit, to pascals.
+func (u BMP180PressureUnit) toPascals(p float32) float32 {
+	switch u {
+	case BMP180Hectopascal, BMP180Millibar:
+		return float32(float64(p) * 100)
+	case BMP180InchOfMercury:
+		return float32(float64(p) * pascalsPerInchOfMercury)
+	case BMP180Atmosphere:
+		return float32(float64(p) * pascalsPerAtmosphere)
+	}
+	return p
+}
+
+// BMP180Trend is the tendency of the pressure, as returned by PressureTrend.
+type BMP180Trend uint8
+
+// String returns the name of the trend, e.g. "Rising".
+func (t BMP180Trend) String() string {
+	switch t {
+	case BMP180TrendSteady:
+		return "Steady"
+	case BMP180TrendRising:
+		return "Rising"
+	case BMP180TrendFalling:
+		return "Falling"
+	}
+	return fmt.Sprintf("BMP180Trend(%d)", uint8(t))
+}
+
+// symbol returns the symbol of the unit, e.g. "hPa".
+func (u BMP180PressureUnit) symbol() string {
+	switch u {
+	case BMP180Hectopascal:
+		return "hPa"
+	case BMP180Millibar:
+		return "mbar"
+	case BMP180InchOfMercury:
+		return "inHg"
+	case BMP180Atmosphere:
+		return "atm"
+	}
+	return "Pa"
+}
+
 // BMP180OversamplingMode is the oversampling ratio of the pressure measurement.
 type BMP180OversamplingMode uint
 
-type calibrationCoefficients struct {
-	ac1 int16
-	ac2 int16
-	ac3 int16
-	ac4 uint16
-	ac5 uint16
-	ac6 uint16
-	b1  int16
-	b2  int16
-	mb  int16
-	mc  int16
-	md  int16
+// String returns the name of the oversampling mode, e.g. "UltraLowPower".
+func (m BMP180OversamplingMode) String() string {
+	switch m {
+	case BMP180UltraLowPower:
+		return "UltraLowPower"
+	case BMP180Standard:
+		return "Standard"
+	case BMP180HighResolution:
+		return "HighResolution"
+	case BMP180UltraHighResolution:
+		return "UltraHighResolution"
+	}
+	return fmt.Sprintf("BMP180OversamplingMode(%d)", uint(m))
+}
+
+// BMP180WaitMode is how the BMP180 waits for the end of a conversion.
+type BMP180WaitMode uint8
+
+// BMP180CalibrationCoefficients are the factory calibration coefficients
+// stored in the EEPROM of each BMP180, as named in the datasheet.
+type BMP180CalibrationCoefficients struct {
+	AC1 int16
+	AC2 int16
+	AC3 int16
+	AC4 uint16
+	AC5 uint16
+	AC6 uint16
+	B1  int16
+	B2  int16
+	MB  int16
+	MC  int16
+	MD  int16
+}
+
+// BMP180CalibrationCache stores the calibration coefficients of BMP180
+// devices by address, e.g. in a file or in memory, so that starting many
+// devices does not need to read them from each device every time.
+type BMP180CalibrationCache interface {
+	// Load returns the coefficients stored for the address, and whether
+	// there are any.
+	Load(address int) (BMP180CalibrationCoefficients, bool)
+	// Store stores the coefficients read from the device at the address.
+	Store(address int, c BMP180CalibrationCoefficients)
+}
+
+// BMP180DeviceInfo identifies the silicon of a BMP180.
+type BMP180DeviceInfo struct {
+	// ChipID is always 0x55 on a BMP180.
+	ChipID uint8
+	// Version is the raw version register, holding both versions below.
+	Version uint8
+	// MLVersion is the version of the mask (low nibble of Version).
+	MLVersion uint8
+	// ALVersion is the version of the ASIC logic (high nibble of Version).
+	ALVersion uint8
+}
+
+// BMP180Reading is a consistent sample of all the BMP180 measurements,
+// taken together at Time. It marshals to JSON as
+// {"temperature":..,"pressure":..,"altitude":..,"timestamp":..}.
+type BMP180Reading struct {
+	Temperature float32   `json:"temperature"`
+	Pressure    float32   `json:"pressure"`
+	Altitude    float32   `json:"altitude"`
+	Time        time.Time `json:"timestamp"`
 }
 
 // BMP180Driver is the gobot driver for the Bosch pressure sensor BMP180.
 // Device datasheet: https://cdn-shop.adafruit.com/datasheets/BST-BMP180-DS000-09.pdf
+//
+// The driver does no background work: each measurement starts a single
+// conversion, after which the BMP180 goes back to standby by itself, drawing
+// about 0.1µA. The consumption of battery powered projects thus only depends
+// on how often they measure, and on the oversampling mode.
 type BMP180Driver struct {
 	name       string
 	Mode       BMP180OversamplingMode
 	connector  Connector
 	connection Connection
 	Config
-	calibrationCoefficients *calibrationCoefficients
+	gobot.Commander
+	calibrationCoefficients *BMP180CalibrationCoefficients
+	calibrationCache        BMP180CalibrationCache
+	calibrationOrder        binary.ByteOrder
+	seaLevelPressure        float32
+	tempReadInterval        int
+	pressureReads           int
+	lastRawTemp             uint16
+	lastTempTime            time.Time
+	lastTemp                float32
+	lastPressure            float32
+	hasLastTemp             bool
+	hasLastPressure         bool
+	tempMaxAge              time.Duration
+	readRetries             int
+	initRetries             int
+	tempSlope               float32
+	tempOffset              float32
+	pressureSlope           float32
+	pressureOffset          float32
+	pressureUnit            BMP180PressureUnit
+	medianWindow            int
+	medianPressures         []float32
+	averageWindow           int
+	averagePressures        []float32
+	tempDelay               time.Duration
+	pressureDelays          [4]time.Duration
+	waitMode                BMP180WaitMode
+	operationTimeout        time.Duration
+	abandoned               chan struct{}
+	busLock                 sync.Locker
+	sampleCount             uint64
+	lastReadTime            time.Time
+	now                     func() time.Time
+	tempSource              func() (float32, error)
+	minTemp                 float32
+	maxTemp                 float32
+	minPressure             float32
+	maxPressure             float32
+	history                 []BMP180Reading
+	historyNext             int
+	historyLen              int
+	trendWindow             time.Duration
+	trendDeadband           float32
+	readingHook             func(BMP180Reading)
+	rawOversampling         int
+	mutex                   *sync.Mutex
 }
 
 // NewBMP180Driver creates a new driver with the i2c interface for the BMP180 device.
@@ -64,6 +375,7 @@ type BMP180Driver struct {
 // Optional params:
 //		i2c.WithBus(int):	bus to use with this driver
 //		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithBMP180Mode(BMP180OversamplingMode):	oversampling mode, defaults to BMP180UltraLowPower
 //
 func NewBMP180Driver(c Connector, options ...func(Config)) *BMP180Driver {
 	b := &BMP180Driver{
@@ -71,17 +383,89 @@ func NewBMP180Driver(c Connector, options ...func(Config)) *BMP180Driver {
 		connector:               c,
 		Mode:                    BMP180UltraLowPower,
 		Config:                  NewConfig(),
-		calibrationCoefficients: &calibrationCoefficients{},
+		Commander:               gobot.NewCommander(),
+		calibrationCoefficients: &BMP180CalibrationCoefficients{},
+		calibrationOrder:        binary.BigEndian,
+		seaLevelPressure:        bmp180SeaLevelPressure,
+		tempReadInterval:        1,
+		rawOversampling:         1,
+		tempSlope:               1,
+		pressureSlope:           1,
+		tempDelay:               5 * time.Millisecond,
+		now:                     time.Now,
+		minTemp:                 bmp180MinTemp,
+		maxTemp:                 bmp180MaxTemp,
+		minPressure:             bmp180MinPlausiblePressure,
+		maxPressure:             bmp180MaxPlausiblePressure,
+		trendWindow:             bmp180TrendWindow,
+		trendDeadband:           bmp180TrendDeadband,
+		mutex:                   &sync.Mutex{},
+	}
+	for mode := range b.pressureDelays {
+		b.pressureDelays[mode] = pauseForReading(BMP180OversamplingMode(mode))
 	}
 
 	for _, option := range options {
 		option(b)
 	}
 
-	// TODO: expose commands to API
+	b.AddCommand("Temperature", func(params map[string]interface{}) interface{} {
+		temp, err := b.Temperature()
+		return map[string]interface{}{"temperature": temp, "err": err}
+	})
+
+	b.AddCommand("Pressure", func(params map[string]interface{}) interface{} {
+		pressure, err := b.Pressure()
+		return map[string]interface{}{"pressure": pressure, "err": err}
+	})
+
+	b.AddCommand("Altitude", func(params map[string]interface{}) interface{} {
+		alt, err := b.Altitude()
+		return map[string]interface{}{"altitude": alt, "err": err}
+	})
+
 	return b
 }
 
+// WithBMP180Mode option sets the oversampling mode of the pressure
+// measurement. Modes above BMP180UltraHighResolution are ignored.
+func WithBMP180Mode(val BMP180OversamplingMode) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*BMP180Driver)
+		if ok && val <= BMP180UltraHighResolution {
+			d.Mode = val
+		}
+	}
+}
+
+// SetMode sets the oversampling mode of the pressure measurement, or
+// returns ErrInvalidOversamplingMode for a mode above
+// BMP180UltraHighResolution.
+func (d *BMP180Driver) SetMode(mode BMP180OversamplingMode) error {
+	if mode > BMP180UltraHighResolution {
+		return ErrInvalidOversamplingMode
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.Mode = mode
+	return nil
+}
+
+// PressureNoiseEstimate returns the typical RMS noise of the pressure, in
+// pascals, in the current oversampling mode, as given by the datasheet: from
+// 6 Pa in BMP180UltraLowPower down to 3 Pa in BMP180UltraHighResolution. Its
+// square is the variance of the measurement, e.g. for a Kalman filter.
+func (d *BMP180Driver) PressureNoiseEstimate() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if int(d.Mode) >= len(bmp180PressureNoise) {
+		return 0
+	}
+	return bmp180PressureNoise[d.Mode]
+}
+
 // Name returns the name of the device.
 func (d *BMP180Driver) Name() string {
 	return d.name
@@ -97,113 +481,1218 @@ func (d *BMP180Driver) Connection() gobot.Connection {
 	return d.connector.(gobot.Connection)
 }
 
+// String returns a description of the driver for logging, with its name,
+// its oversampling mode and the last temperature and pressure it measured,
+// e.g. "BMP180(name=BMP180-1234, mode=Standard, temp=21.3C, pressure=101325Pa)".
+func (d *BMP180Driver) String() string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	s := fmt.Sprintf("BMP180(name=%s, mode=%s", d.name, d.Mode)
+	if d.hasLastTemp {
+		s += fmt.Sprintf(", temp=%.1fC", d.lastTemp)
+	}
+	if d.hasLastPressure {
+		s += fmt.Sprintf(", pressure=%.0fPa", d.lastPressure)
+	}
+	return s + ")"
+}
+
 // Start initializes the BMP180 and loads the calibration coefficients.
 func (d *BMP180Driver) Start() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
 	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
 	address := d.GetAddressOrDefault(bmp180Address)
 
-	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+	var connection Connection
+	if connection, err = d.connector.GetConnection(address, bus); err != nil {
 		return err
 	}
-	if err := d.initialization(); err != nil {
-		return err
+	d.connection = &bmp180TimeoutConnection{Connection: connection, driver: d}
+	delay := bmp180InitRetryDelay
+	for attempt := 0; ; attempt++ {
+		if err = d.initialization(); err == nil || attempt >= d.initRetries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
 	}
-	return nil
 }
 
 func (d *BMP180Driver) initialization() (err error) {
+	var id uint32
+	if id, err = d.readReg("id"); err != nil {
+		return err
+	}
+	if id != bmp180ChipID {
+		return fmt.Errorf("BMP180 device not found (chip id 0x%02X)", id)
+	}
+
+	address := d.GetAddressOrDefault(bmp180Address)
+	if d.calibrationCache != nil {
+		// a cached entry is checked as a read one, and read again when not
+		// valid, e.g. once saved from a faulty read.
+		if c, ok := d.calibrationCache.Load(address); ok {
+			var cached bytes.Buffer
+			binary.Write(&cached, binary.BigEndian, c)
+			if bmp180ValidCoefficients(cached.Bytes()) {
+				*d.calibrationCoefficients = c
+				return nil
+			}
+		}
+	}
+
 	var coefficients []byte
 	// read the 11 calibration coefficients.
 	if coefficients, err = d.read(bmp180RegisterAC1MSB, 22); err != nil {
 		return err
 	}
+	if !bmp180ValidCoefficients(coefficients) {
+		return ErrInvalidCalibration
+	}
 	buf := bytes.NewBuffer(coefficients)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.ac1)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.ac2)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.ac3)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.ac4)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.ac5)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.ac6)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.b1)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.b2)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.mb)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.mc)
-	binary.Read(buf, binary.BigEndian, &d.calibrationCoefficients.md)
+	order := d.calibrationOrder
+	binary.Read(buf, order, &d.calibrationCoefficients.AC1)
+	binary.Read(buf, order, &d.calibrationCoefficients.AC2)
+	binary.Read(buf, order, &d.calibrationCoefficients.AC3)
+	binary.Read(buf, order, &d.calibrationCoefficients.AC4)
+	binary.Read(buf, order, &d.calibrationCoefficients.AC5)
+	binary.Read(buf, order, &d.calibrationCoefficients.AC6)
+	binary.Read(buf, order, &d.calibrationCoefficients.B1)
+	binary.Read(buf, order, &d.calibrationCoefficients.B2)
+	binary.Read(buf, order, &d.calibrationCoefficients.MB)
+	binary.Read(buf, order, &d.calibrationCoefficients.MC)
+	binary.Read(buf, order, &d.calibrationCoefficients.MD)
 
+	if d.calibrationCache != nil {
+		d.calibrationCache.Store(address, *d.calibrationCoefficients)
+	}
 	return nil
 }
 
+// SetCalibrationByteOrder sets the byte order in which Start decodes the
+// calibration coefficients read from the BMP180, for the rare clones
+// returning them little-endian. It only applies to the next Start. Defaults
+// to binary.BigEndian, as in the datasheet; a nil order restores it.
+func (d *BMP180Driver) SetCalibrationByteOrder(order binary.ByteOrder) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if order == nil {
+		order = binary.BigEndian
+	}
+	d.calibrationOrder = order
+}
+
+// SetCalibrationCache sets the cache of the calibration coefficients. When
+// it holds the coefficients of the address, Start loads them from the cache
+// instead of reading them from the BMP180, otherwise it stores the ones it
+// reads. The chip id is still checked, and cached coefficients that are not
+// valid are read again and replaced. Defaults to nil, that is no cache.
+func (d *BMP180Driver) SetCalibrationCache(cache BMP180CalibrationCache) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.calibrationCache = cache
+}
+
+// DeviceInfo reads the chip id and the version registers of the BMP180.
+// Diagnostic tools can use them to report exactly which silicon is
+// attached, e.g. to tell a clone from a genuine part.
+func (d *BMP180Driver) DeviceInfo() (info BMP180DeviceInfo, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	// the version register 0xD1 follows the chip id.
+	if data, err = d.read(bmp180RegisterChipID, 2); err != nil {
+		return info, err
+	}
+	info.ChipID = data[0]
+	info.Version = data[1]
+	info.MLVersion = data[1] & 0x0F
+	info.ALVersion = data[1] >> 4
+	return info, nil
+}
+
+// Info returns the description of the driver: the BMP180 measuring the
+// temperature, in celsius degrees, the pressure, in the pressure unit, and
+// the altitude, in meters, on the bus and at the address it is configured
+// for.
+func (d *BMP180Driver) Info() DriverInfo {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return DriverInfo{
+		Type: "BMP180",
+		Quantities: []DriverQuantity{
+			{Name: "temperature", Unit: "°C"},
+			{Name: "pressure", Unit: d.pressureUnit.symbol()},
+			{Name: "altitude", Unit: "m"},
+		},
+		Bus:     d.GetBusOrDefault(d.connector.GetDefaultBus()),
+		Address: d.GetAddressOrDefault(bmp180Address),
+	}
+}
+
+// DumpRegisters reads the registers of the BMP180 worth attaching to a bug
+// report, and returns their bytes by the address of their first register:
+// the 22 bytes of the calibration coefficients at 0xAA, the chip id and
+// version at 0xD0, and the control register at 0xF4. It only reads them.
+func (d *BMP180Driver) DumpRegisters() (map[byte][]byte, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	blocks := []struct {
+		address byte
+		n       int
+	}{
+		{bmp180RegisterAC1MSB, 22},
+		{bmp180RegisterChipID, 2},
+		{bmp180RegisterCtl, 1},
+	}
+	dump := make(map[byte][]byte, len(blocks))
+	for _, block := range blocks {
+		data, err := d.read(block.address, block.n)
+		if err != nil {
+			return nil, err
+		}
+		dump[block.address] = data
+	}
+	return dump, nil
+}
+
+// SelfTest checks that the BMP180 started by Start works, e.g. for a health
+// check: it reads the chip id, checks the calibration coefficients in use,
+// and measures the temperature, without the calibration of
+// SetTemperatureCalibration, which must be within the operating range of
+// the datasheet, -40 to 85 degrees. It returns nil when all the checks
+// pass, or an error naming the first one failing. The state of the driver
+// is left unchanged, so it can run between measurements.
+func (d *BMP180Driver) SelfTest() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	id, err := d.readReg("id")
+	if err != nil {
+		return fmt.Errorf("BMP180 self-test failed (chip id: %v)", err)
+	}
+	if id != bmp180ChipID {
+		return fmt.Errorf("BMP180 self-test failed (chip id 0x%02X)", id)
+	}
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, d.calibrationCoefficients)
+	if !bmp180ValidCoefficients(buf.Bytes()) {
+		return fmt.Errorf("BMP180 self-test failed (calibration: %v)", ErrInvalidCalibration)
+	}
+	rawTemp, err := d.rawTemp()
+	if err != nil {
+		return fmt.Errorf("BMP180 self-test failed (temperature: %v)", err)
+	}
+	if temp := d.calculateTemp(rawTemp); temp < bmp180MinTemp || temp > bmp180MaxTemp {
+		return fmt.Errorf("BMP180 self-test failed (temperature %v: %v)", temp, ErrTemperatureOutOfRange)
+	}
+	return nil
+}
+
+// CalibrationCoefficients returns a copy of the calibration coefficients
+// loaded from the BMP180 by Start.
+func (d *BMP180Driver) CalibrationCoefficients() BMP180CalibrationCoefficients {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return *d.calibrationCoefficients
+}
+
+// SetCalibrationCoefficients replaces the calibration coefficients used by
+// the measurements, e.g. to check the math against known values. Note that
+// Start and SoftReset load them from the BMP180 again.
+func (d *BMP180Driver) SetCalibrationCoefficients(c BMP180CalibrationCoefficients) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.calibrationCoefficients = &c
+}
+
+// SoftReset performs the same sequence as a power-on reset, then reloads
+// the calibration coefficients.
+func (d *BMP180Driver) SoftReset() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err = d.writeReg("soft_reset", bmp180CmdSoftReset); err != nil {
+		return err
+	}
+	// start-up time after reset, see datasheet.
+	time.Sleep(10 * time.Millisecond)
+	return d.initialization()
+}
+
 // Halt halts the device.
 func (d *BMP180Driver) Halt() (err error) {
 	return nil
 }
 
+// Close halts the device, as Halt does, so that the driver implements
+// io.Closer, e.g. for a defer d.Close() after Start. As the driver does no
+// background work, there is nothing else to release, and it can be closed
+// more than once.
+func (d *BMP180Driver) Close() error {
+	return d.Halt()
+}
+
+// SupportsHumidity returns false, the BMP180 has no humidity sensor, unlike
+// the BME280. Generic code handling several environmental drivers can use it
+// before asserting a Humidity method.
+func (d *BMP180Driver) SupportsHumidity() bool {
+	return false
+}
+
 // Temperature returns the current temperature, in celsius degrees.
+// It is safe to call concurrently with the other measurement methods.
 func (d *BMP180Driver) Temperature() (temp float32, err error) {
-	var rawTemp int16
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var rawTemp uint16
 	if rawTemp, err = d.rawTemp(); err != nil {
 		return 0, err
 	}
-	return d.calculateTemp(rawTemp), nil
+	temp = d.tempSlope*d.calculateTemp(rawTemp) + d.tempOffset
+	if !d.plausibleTemp(temp) {
+		return 0, ErrTemperatureOutOfRange
+	}
+	d.lastTemp, d.hasLastTemp = temp, true
+	d.sampled()
+	return temp, nil
 }
 
-// Pressure returns the current pressure, in pascals.
+// Pressure returns the current pressure, in pascals or in the unit set by
+// SetPressureUnit.
+// It is safe to call concurrently with the other measurement methods.
 func (d *BMP180Driver) Pressure() (pressure float32, err error) {
-	var rawTemp int16
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if pressure, err = d.pressure(); err != nil {
+		return 0, err
+	}
+	return d.pressureUnit.fromPascals(pressure), nil
+}
+
+// SetPressureUnit sets the unit of the pressures returned by Pressure and
+// Reading, or returns ErrInvalidPressureUnit for an unknown unit. Defaults
+// to BMP180Pascal. The pressures are still computed in pascals, and only
+// converted when returned.
+func (d *BMP180Driver) SetPressureUnit(unit BMP180PressureUnit) error {
+	if unit > BMP180Atmosphere {
+		return ErrInvalidPressureUnit
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.pressureUnit = unit
+	return nil
+}
+
+// PressureUnit returns the unit of the pressures returned by Pressure and
+// Reading, see SetPressureUnit.
+func (d *BMP180Driver) PressureUnit() BMP180PressureUnit {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.pressureUnit
+}
+
+// pressure returns the current pressure, in pascals.
+func (d *BMP180Driver) pressure() (pressure float32, err error) {
+	if d.tempSource != nil {
+		return d.pressureFromSource()
+	}
 	var rawPressure int32
+	stale := d.tempMaxAge > 0 && d.now().Sub(d.lastTempTime) > d.tempMaxAge
+	if d.pressureReads == 0 || stale {
+		var rawTemp uint16
+		if rawTemp, err = d.rawTemp(); err != nil {
+			return 0, err
+		}
+		d.lastRawTemp = rawTemp
+		d.lastTempTime = d.now()
+		d.pressureReads = 0
+	}
+	if rawPressure, err = d.averageRawPressure(d.Mode); err != nil {
+		return 0, err
+	}
+	d.pressureReads = (d.pressureReads + 1) % d.tempReadInterval
+	if pressure, err = d.calculatePressure(d.lastRawTemp, rawPressure, d.Mode); err != nil {
+		return 0, err
+	}
+	return d.pressureSample(pressure)
+}
+
+// pressureFromSource returns the current pressure, in pascals, compensated
+// with the temperature of the temperature source.
+func (d *BMP180Driver) pressureFromSource() (pressure float32, err error) {
+	var temp float32
+	if temp, err = d.tempSource(); err != nil {
+		return 0, err
+	}
+	var rawPressure int32
+	if rawPressure, err = d.averageRawPressure(d.Mode); err != nil {
+		return 0, err
+	}
+	b5 := bmp180TempToB5(temp)
+	if pressure, err = bmp180CalculatePressureB5(d.calibrationCoefficients, b5, rawPressure, d.Mode); err != nil {
+		return 0, err
+	}
+	return d.pressureSample(pressure)
+}
+
+// pressureSample calibrates and filters a new pressure, in pascals, and
+// records it as the last sample, unless it is not plausible.
+func (d *BMP180Driver) pressureSample(pressure float32) (float32, error) {
+	pressure = d.pressureSlope*pressure + d.pressureOffset
+	if !d.plausiblePressure(pressure) {
+		return 0, ErrPressureOutOfRange
+	}
+	pressure = d.averagePressure(d.medianPressure(pressure))
+	d.lastPressure, d.hasLastPressure = pressure, true
+	d.sampled()
+	return pressure, nil
+}
+
+// plausibleTemp returns whether the temperature is in the plausible range,
+// and not NaN.
+func (d *BMP180Driver) plausibleTemp(temp float32) bool {
+	return temp >= d.minTemp && temp <= d.maxTemp
+}
+
+// plausiblePressure returns whether the pressure is in the plausible range,
+// and not NaN.
+func (d *BMP180Driver) plausiblePressure(pressure float32) bool {
+	return pressure >= d.minPressure && pressure <= d.maxPressure
+}
+
+// SampleCount returns the number of samples measured since the driver was
+// created: each temperature or pressure, or each Reading, counts as one.
+// Together with LastReadTime, it lets a logger detect the missed samples and
+// compute the sampling rate.
+func (d *BMP180Driver) SampleCount() uint64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.sampleCount
+}
+
+// LastReadTime returns when the last sample was measured, or the zero time
+// when none has been.
+func (d *BMP180Driver) LastReadTime() time.Time {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.lastReadTime
+}
+
+// sampled counts a new sample, and returns its time.
+func (d *BMP180Driver) sampled() time.Time {
+	d.sampleCount++
+	d.lastReadTime = d.now()
+	return d.lastReadTime
+}
+
+// RawTemperature returns the uncompensated temperature (UT) as read from the
+// BMP180, before the datasheet conversion.
+func (d *BMP180Driver) RawTemperature() (uint16, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.rawTemp()
+}
+
+// RawPressure returns the uncompensated pressure (UP) as read from the
+// BMP180 in the current oversampling mode, before the datasheet conversion.
+func (d *BMP180Driver) RawPressure() (int32, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.rawPressure(d.Mode)
+}
+
+// Reading measures the temperature and the pressure in one locked sequence
+// of 6 i2c transactions, 3 more for each pressure averaged with
+// SetRawOversampling, and returns them together with the altitude and the
+// time of the sample. Unlike separate calls to Temperature and Pressure, the
+// values cannot tear across concurrent readings. The reading hook, if set,
+// is called with each successful reading before it is returned.
+func (d *BMP180Driver) Reading() (r BMP180Reading, err error) {
+	d.mutex.Lock()
+	r, err = d.reading()
+	hook := d.readingHook
+	d.mutex.Unlock()
+
+	if err == nil && hook != nil {
+		hook(r)
+	}
+	return r, err
+}
+
+func (d *BMP180Driver) reading() (r BMP180Reading, err error) {
+	var rawTemp uint16
 	if rawTemp, err = d.rawTemp(); err != nil {
+		return r, err
+	}
+	d.lastRawTemp = rawTemp
+	d.lastTempTime = d.now()
+	var rawPressure int32
+	if rawPressure, err = d.averageRawPressure(d.Mode); err != nil {
+		return r, err
+	}
+	d.pressureReads = 1 % d.tempReadInterval
+
+	var pressure float32
+	if pressure, err = d.calculatePressure(rawTemp, rawPressure, d.Mode); err != nil {
+		return r, err
+	}
+	r.Temperature = d.tempSlope*d.calculateTemp(rawTemp) + d.tempOffset
+	if !d.plausibleTemp(r.Temperature) {
+		return BMP180Reading{}, ErrTemperatureOutOfRange
+	}
+	pressure = d.pressureSlope*pressure + d.pressureOffset
+	if !d.plausiblePressure(pressure) {
+		return BMP180Reading{}, ErrPressureOutOfRange
+	}
+	pressure = d.averagePressure(d.medianPressure(pressure))
+	d.lastTemp, d.hasLastTemp = r.Temperature, true
+	d.lastPressure, d.hasLastPressure = pressure, true
+	r.Pressure = pressure
+	r.Altitude = d.altitude(pressure)
+	r.Time = d.sampled()
+	d.addHistory(r)
+	r.Pressure = d.pressureUnit.fromPascals(pressure)
+	return r, nil
+}
+
+// SetReadingHook sets a function called with each reading returned by
+// Reading, e.g. to log, filter or forward the readings, as a lighter
+// alternative to wrapping every call. The hook runs synchronously, in the
+// goroutine calling Reading and after the driver is unlocked, so it may call
+// the driver; a slow hook delays the return of Reading, and should hand the
+// readings over to a goroutine of its own. A nil hook removes it.
+func (d *BMP180Driver) SetReadingHook(hook func(BMP180Reading)) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.readingHook = hook
+}
+
+// SetTemperatureCalibration sets a linear correction applied to the
+// temperatures returned by Temperature, to compensate for the bias of an
+// individual sensor: slope*t + offset. Defaults to a slope of 1 and an
+// offset of 0, that is no correction.
+func (d *BMP180Driver) SetTemperatureCalibration(slope, offset float32) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.tempSlope = slope
+	d.tempOffset = offset
+}
+
+// SetPressureCalibration sets a linear correction applied to the pressures
+// returned by Pressure and used by Altitude: slope*p + offset. Defaults to
+// a slope of 1 and an offset of 0, that is no correction.
+func (d *BMP180Driver) SetPressureCalibration(slope, offset float32) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.pressureSlope = slope
+	d.pressureOffset = offset
+}
+
+// CalibrateToReferencePressure sets the offset of the pressure calibration
+// so that the pressure measured by the BMP180 matches ref, a known pressure
+// in pascals, e.g. from a nearby weather station. The pressure is the average
+// of the given number of readings, at least one, which are taken with the
+// slope already set by SetPressureCalibration. The readings are not added to
+// the median filter of SetPressureMedianFilter nor to the moving average of
+// SetPressureAveraging, whose pressures are moved by the new offset, as is
+// the last pressure used by Altitude. When a reading
+// fails, the calibration is left unchanged and the error is returned.
+func (d *BMP180Driver) CalibrateToReferencePressure(ref float32, samples int) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if samples < 1 {
+		samples = 1
+	}
+	medianWindow, averageWindow := d.medianWindow, d.averageWindow
+	d.medianWindow, d.averageWindow = 1, 1
+	defer func() { d.medianWindow, d.averageWindow = medianWindow, averageWindow }()
+	var sum float64
+	for i := 0; i < samples; i++ {
+		pressure, err := d.pressure()
+		if err != nil {
+			return err
+		}
+		sum += float64(pressure)
+	}
+	offset := ref - float32(sum/float64(samples))
+	d.pressureOffset += offset
+	for i := range d.medianPressures {
+		d.medianPressures[i] += offset
+	}
+	for i := range d.averagePressures {
+		d.averagePressures[i] += offset
+	}
+	if d.hasLastPressure {
+		d.lastPressure += offset
+	}
+	return nil
+}
+
+// SetPressureMedianFilter sets the number of the last pressures of which
+// Pressure, Reading and Altitude return the median, rejecting the single
+// spikes that electrical noise puts on a reading, where an average would be
+// skewed by them. Until window pressures have been measured, the median of
+// the ones measured so far is returned. The window must be odd, or
+// ErrInvalidMedianWindow is returned. Defaults to 1, that is no filtering.
+func (d *BMP180Driver) SetPressureMedianFilter(window int) error {
+	if window < 1 || window%2 == 0 {
+		return ErrInvalidMedianWindow
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.medianWindow = window
+	d.medianPressures = nil
+	return nil
+}
+
+// SetPressureAveraging sets the number of the last pressures of which
+// Pressure, Reading and Altitude return the moving average, smoothing the
+// noise of a low oversampling mode, e.g. for the altitude hold of a drone.
+// The average is taken after the median filter of SetPressureMedianFilter.
+// It delays a change of pressure by roughly n times the interval between
+// two measurements. Until n pressures have been measured, the average of the
+// ones measured so far is returned. Defaults to 1, that is no averaging.
+func (d *BMP180Driver) SetPressureAveraging(n int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if n < 1 {
+		n = 1
+	}
+	d.averageWindow = n
+	d.averagePressures = nil
+}
+
+// SetHistorySize sets how many of the last readings returned by Reading are
+// kept in memory, for History and HistorySince. The oldest readings are
+// dropped once n are kept, so that the memory stays bounded; when the size
+// is reduced, the most recent readings are kept. Defaults to 0, that is no
+// history.
+func (d *BMP180Driver) SetHistorySize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	kept := d.historyReadings()
+	if len(kept) > n {
+		kept = kept[len(kept)-n:]
+	}
+	d.history = make([]BMP180Reading, n)
+	copy(d.history, kept)
+	d.historyLen = len(kept)
+	d.historyNext = 0
+	if n > 0 {
+		d.historyNext = len(kept) % n
+	}
+}
+
+// History returns the readings kept in the history, from the oldest to the
+// most recent, with their pressures in the current pressure unit.
+func (d *BMP180Driver) History() []BMP180Reading {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.inPressureUnit(d.historyReadings())
+}
+
+// HistorySince returns the readings of the history taken at t or after,
+// from the oldest to the most recent, like History.
+func (d *BMP180Driver) HistorySince(t time.Time) []BMP180Reading {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	readings := d.historyReadings()
+	// the readings are in time order.
+	i := sort.Search(len(readings), func(i int) bool { return !readings[i].Time.Before(t) })
+	return d.inPressureUnit(readings[i:])
+}
+
+// SetPressureTrend sets the window of the last readings of the history over
+// which PressureTrend fits the pressure, and the deadband of its rate, in
+// pascals an hour whatever the pressure unit, within which the trend is
+// steady. They default to 3 hours and 10 Pa an hour.
+func (d *BMP180Driver) SetPressureTrend(window time.Duration, deadband float32) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.trendWindow = window
+	d.trendDeadband = deadband
+}
+
+// PressureTrend returns the tendency of the pressure, and its rate of
+// change per hour, in the pressure unit, e.g. Pa an hour. The rate is the
+// slope of a linear fit of the pressures of the history, see
+// SetHistorySize, taken within the trend window before the most recent one.
+// The history must thus be large enough to hold the readings of the window.
+// With fewer than 2 readings in the window, the trend is steady with a rate
+// of 0.
+func (d *BMP180Driver) PressureTrend() (BMP180Trend, float32) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	readings := d.historyReadings()
+	if len(readings) == 0 {
+		return BMP180TrendSteady, 0
+	}
+	from := readings[len(readings)-1].Time.Add(-d.trendWindow)
+	i := sort.Search(len(readings), func(i int) bool { return !readings[i].Time.Before(from) })
+	rate := float32(bmp180PressureSlope(readings[i:]))
+	switch {
+	case rate > d.trendDeadband:
+		return BMP180TrendRising, d.pressureUnit.fromPascals(rate)
+	case rate < -d.trendDeadband:
+		return BMP180TrendFalling, d.pressureUnit.fromPascals(rate)
+	}
+	return BMP180TrendSteady, d.pressureUnit.fromPascals(rate)
+}
+
+// SetRawOversampling sets how many uncompensated pressures Pressure,
+// Reading and the methods built on them average before compensating the
+// pressure once, on top of the oversampling mode of the BMP180. Averaging the
+// raw values rather than the compensated pressures keeps the fractions of
+// digit they carry, which reduces the quantization noise, and compensates
+// only once. Each more pressure costs a conversion and its delay: averaging
+// n pressures in BMP180UltraLowPower takes about as long as one in a higher
+// mode, which reduces the noise inside the BMP180 equally well, so this pays
+// off mainly beyond BMP180UltraHighResolution. The temperature is measured
+// once. Defaults to 1, that is no averaging.
+func (d *BMP180Driver) SetRawOversampling(n int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if n < 1 {
+		n = 1
+	}
+	d.rawOversampling = n
+}
+
+// SetReadRetries sets how many more times a failed register read is
+// repeated before the error is returned, which helps on long or noisy
+// buses. Defaults to 0, that is no retry.
+func (d *BMP180Driver) SetReadRetries(n int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if n < 0 {
+		n = 0
+	}
+	d.readRetries = n
+}
+
+// SetInitRetries sets how many more times Start repeats the initialization,
+// the chip id and calibration reads, while it fails, waiting 10ms before the
+// first repetition and twice as long before each next one. This lets the
+// driver start on marginal hardware whose first reads after power-up fail.
+// Defaults to 0, that is no retry.
+func (d *BMP180Driver) SetInitRetries(n int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if n < 0 {
+		n = 0
+	}
+	d.initRetries = n
+}
+
+// SetTemperatureReadInterval sets how often Pressure measures the
+// temperature it needs for compensation: once every n pressure readings.
+// In between, the last measured temperature is reused, which saves one
+// conversion per reading. Defaults to 1, that is on every reading.
+func (d *BMP180Driver) SetTemperatureReadInterval(n int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if n < 1 {
+		n = 1
+	}
+	d.tempReadInterval = n
+	d.pressureReads = 0
+}
+
+// SetTemperatureMaxAge sets how long Pressure may reuse the last measured
+// temperature, whatever the temperature read interval, so that a drifting
+// temperature does not bias slow pressure-only readings. Defaults to 0, that
+// is no limit.
+func (d *BMP180Driver) SetTemperatureMaxAge(age time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.tempMaxAge = age
+}
+
+// SetTemperatureSource sets a function returning the temperature, in
+// celsius degrees, with which Pressure and the methods built on it
+// compensate the pressure, instead of measuring the temperature of this
+// BMP180. In an array of sensors sharing the same environment, a single one
+// then needs to measure the temperature, e.g.:
+//		b.SetTemperatureSource(a.Temperature)
+// The source temperature is used rather than its raw value, which only
+// makes sense with the calibration coefficients of its own sensor. Reading
+// still measures the temperature. The source must not lock this driver, e.g.
+// be its own Temperature method. A nil source restores the default.
+func (d *BMP180Driver) SetTemperatureSource(source func() (float32, error)) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.tempSource = source
+}
+
+// SetPlausibleRanges sets the ranges out of which the measurements are
+// rejected, with ErrTemperatureOutOfRange or ErrPressureOutOfRange, instead
+// of returned: the temperatures, in celsius degrees, from minTemp to maxTemp,
+// and the pressures, in pascals, from minPressure to maxPressure, after the
+// calibration. They default to the operating range of the datasheet, -40 to
+// 85 degrees and 30000 to 110000 Pa.
+func (d *BMP180Driver) SetPlausibleRanges(minTemp, maxTemp, minPressure, maxPressure float32) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.minTemp, d.maxTemp = minTemp, maxTemp
+	d.minPressure, d.maxPressure = minPressure, maxPressure
+}
+
+// SetConversionDelays sets how long the measurements wait for a conversion
+// to complete before reading its result: temp for the temperature, and
+// pressure for the pressure in each oversampling mode, from
+// BMP180UltraLowPower to BMP180UltraHighResolution. They default to the
+// maximum conversion times of the datasheet; shorter delays risk reading
+// the result of a previous conversion.
+func (d *BMP180Driver) SetConversionDelays(temp time.Duration, pressure [4]time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.tempDelay = temp
+	d.pressureDelays = pressure
+}
+
+// SetWaitMode sets how the measurements wait for the end of a conversion.
+// BMP180WaitFixedDelay, the default, sleeps for the conversion delays.
+// BMP180WaitPolling instead reads the SCO bit of the control register until
+// it clears, and returns ErrBMP180ConversionTimeout if it does not within
+// 50ms: the measurement completes as soon as possible, at the cost of more
+// i2c transactions.
+func (d *BMP180Driver) SetWaitMode(mode BMP180WaitMode) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.waitMode = mode
+}
+
+// SetOperationTimeout sets how long an i2c transaction with the BMP180 may
+// take before it is abandoned and ErrBMP180OperationTimeout returned, for the
+// adaptors which would otherwise block for as long as a device stretches the
+// clock. Until the abandoned transaction completes on the bus, the next ones
+// also return ErrBMP180OperationTimeout, without using the bus. Defaults to
+// 0, that is no timeout.
+func (d *BMP180Driver) SetOperationTimeout(timeout time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.operationTimeout = timeout
+}
+
+// SetBusLock sets a lock of the whole bus, e.g. a process-wide mutex shared
+// with the drivers of the other devices, held around each complete
+// transaction with the BMP180. A register read is not atomic: it writes the
+// register address then reads the data, and another master of the bus, or
+// another user of the OS i2c device, could come in between and move the
+// register pointer. With the lock, the write and the read happen together.
+// The lock is not held during the conversions, nor by the retries of
+// SetReadRetries when waiting. Defaults to nil, that is no lock.
+func (d *BMP180Driver) SetBusLock(lock sync.Locker) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.busLock = lock
+}
+
+// SetSeaLevelPressure sets the pressure at sea level, in pascals, used as
+// the reference by Altitude, or returns ErrInvalidSeaLevelPressure for a
+// pressure that is not positive. It defaults to the standard 101325 Pa.
+func (d *BMP180Driver) SetSeaLevelPressure(p float32) error {
+	if p <= 0 {
+		return ErrInvalidSeaLevelPressure
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.seaLevelPressure = p
+	return nil
+}
+
+// Altitude returns the altitude in meters, based on the last measured
+// barometric pressure and the reference pressure at sea level, without a new
+// measurement. It returns ErrBMP180NoPressure before the first pressure.
+func (d *BMP180Driver) Altitude() (alt float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.hasLastPressure {
+		return 0, ErrBMP180NoPressure
+	}
+	return d.altitude(d.lastPressure), nil
+}
+
+// SeaLevelPressure returns the pressure at sea level, in pascals, equivalent
+// to the current pressure at the given known altitude, in meters. This is
+// the QNH reported by weather stations, and can be passed to
+// SetSeaLevelPressure. It returns ErrInvalidAltitude for an altitude at or
+// above 44330 m.
+func (d *BMP180Driver) SeaLevelPressure(altitude float32) (p float32, err error) {
+	// also true for NaN
+	if !(altitude < 44330) {
+		return 0, ErrInvalidAltitude
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var pressure float32
+	if pressure, err = d.pressure(); err != nil {
 		return 0, err
 	}
-	if rawPressure, err = d.rawPressure(d.Mode); err != nil {
+	return float32(float64(pressure) / math.Pow(1.0-float64(altitude)/44330.0, 5.255)), nil
+}
+
+// AltitudeFeet returns the altitude in feet, like Altitude does in
+// meters.
+func (d *BMP180Driver) AltitudeFeet() (alt float32, err error) {
+	if alt, err = d.Altitude(); err != nil {
 		return 0, err
 	}
-	return d.calculatePressure(rawTemp, rawPressure, d.Mode), nil
+	return alt * bmp180FeetPerMeter, nil
 }
 
-func (d *BMP180Driver) rawTemp() (int16, error) {
-	if _, err := d.connection.Write([]byte{bmp180RegisterCtl, bmp180CmdTemp}); err != nil {
+// medianPressure adds the pressure to the window of the median filter, and
+// returns the median of the window.
+func (d *BMP180Driver) medianPressure(pressure float32) float32 {
+	if d.medianWindow <= 1 {
+		return pressure
+	}
+	d.medianPressures = append(d.medianPressures, pressure)
+	if len(d.medianPressures) > d.medianWindow {
+		d.medianPressures = d.medianPressures[1:]
+	}
+	sorted := append([]float32{}, d.medianPressures...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// averagePressure adds the pressure to the window of the moving average,
+// and returns the average of the window.
+func (d *BMP180Driver) averagePressure(pressure float32) float32 {
+	if d.averageWindow <= 1 {
+		return pressure
+	}
+	d.averagePressures = append(d.averagePressures, pressure)
+	if len(d.averagePressures) > d.averageWindow {
+		d.averagePressures = d.averagePressures[1:]
+	}
+	var sum float64
+	for _, p := range d.averagePressures {
+		sum += float64(p)
+	}
+	return float32(sum / float64(len(d.averagePressures)))
+}
+
+// addHistory adds the reading, with its pressure in pascals, to the history,
+// in place of the oldest one when it is full.
+func (d *BMP180Driver) addHistory(r BMP180Reading) {
+	if len(d.history) == 0 {
+		return
+	}
+	d.history[d.historyNext] = r
+	d.historyNext = (d.historyNext + 1) % len(d.history)
+	if d.historyLen < len(d.history) {
+		d.historyLen++
+	}
+}
+
+// inPressureUnit converts the pressures of the readings of the history, in
+// pascals, to the pressure unit.
+func (d *BMP180Driver) inPressureUnit(readings []BMP180Reading) []BMP180Reading {
+	for i := range readings {
+		readings[i].Pressure = d.pressureUnit.fromPascals(readings[i].Pressure)
+	}
+	return readings
+}
+
+// historyReadings returns a copy of the history, from the oldest reading,
+// with the pressures in pascals.
+func (d *BMP180Driver) historyReadings() []BMP180Reading {
+	readings := make([]BMP180Reading, 0, d.historyLen)
+	oldest := d.historyNext - d.historyLen
+	if oldest < 0 {
+		oldest += len(d.history)
+	}
+	for i := 0; i < d.historyLen; i++ {
+		readings = append(readings, d.history[(oldest+i)%len(d.history)])
+	}
+	return readings
+}
+
+// bmp180PressureSlope returns the slope, per hour, of the least squares fit
+// of the pressures of the readings, or 0 when they do not span any time.
+func bmp180PressureSlope(readings []BMP180Reading) float64 {
+	if len(readings) < 2 {
+		return 0
+	}
+	n := float64(len(readings))
+	var sumT, sumP float64
+	for _, r := range readings {
+		sumT += r.Time.Sub(readings[0].Time).Hours()
+		sumP += float64(r.Pressure)
+	}
+	meanT, meanP := sumT/n, sumP/n
+	var cov, variance float64
+	for _, r := range readings {
+		dt := r.Time.Sub(readings[0].Time).Hours() - meanT
+		cov += dt * (float64(r.Pressure) - meanP)
+		variance += dt * dt
+	}
+	if variance == 0 {
+		return 0
+	}
+	return cov / variance
+}
+
+// bmp180ValidCoefficients returns whether the calibration coefficients, as
+// read from the EEPROM, are valid: none can be 0x0000 or 0xFFFF, see
+// datasheet.
+func bmp180ValidCoefficients(coefficients []byte) bool {
+	for i := 0; i+1 < len(coefficients); i += 2 {
+		if c := binary.BigEndian.Uint16(coefficients[i:]); c == 0x0000 || c == 0xFFFF {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *BMP180Driver) altitude(pressure float32) float32 {
+	return float32(44330.0 * (1.0 - math.Pow(float64(pressure/d.seaLevelPressure), 1/5.255)))
+}
+
+func (d *BMP180Driver) rawTemp() (uint16, error) {
+	if err := d.writeReg("ctrl_meas", bmp180CmdTemp); err != nil {
+		return 0, err
+	}
+	if err := d.waitForConversion(d.tempDelay); err != nil {
 		return 0, err
 	}
-	time.Sleep(5 * time.Millisecond)
-	ret, err := d.read(bmp180RegisterTempMSB, 2)
+	rawTemp, err := d.readReg("out")
 	if err != nil {
 		return 0, err
 	}
-	buf := bytes.NewBuffer(ret)
-	var rawTemp int16
-	binary.Read(buf, binary.BigEndian, &rawTemp)
-	return rawTemp, nil
+	return uint16(rawTemp), nil
 }
 
-func (d *BMP180Driver) read(address byte, n int) ([]byte, error) {
-	if _, err := d.connection.Write([]byte{address}); err != nil {
-		return nil, err
+// readReg reads the register of bmp180Registers with the name, with the
+// retries of read.
+func (d *BMP180Driver) readReg(name string) (val uint32, err error) {
+	err = d.retry(func() error {
+		return d.transaction(func() (err error) {
+			val, err = bmp180Registers.ReadReg(d.connection, name)
+			return err
+		})
+	})
+	return val, err
+}
+
+// writeReg writes the value to the register of bmp180Registers with the
+// name.
+func (d *BMP180Driver) writeReg(name string, val uint32) error {
+	return d.transaction(func() error {
+		return bmp180Registers.WriteReg(d.connection, name, val)
+	})
+}
+
+// transaction runs a complete transaction with the BMP180, holding the bus
+// lock if one is set.
+func (d *BMP180Driver) transaction(f func() error) error {
+	if d.busLock != nil {
+		d.busLock.Lock()
+		defer d.busLock.Unlock()
 	}
-	buf := make([]byte, n)
-	bytesRead, err := d.connection.Read(buf)
-	if bytesRead != n || err != nil {
+	return f()
+}
+
+func (d *BMP180Driver) read(address byte, n int) (buf []byte, err error) {
+	err = d.retry(func() error {
+		return d.transaction(func() (err error) {
+			if _, err = d.connection.Write([]byte{address}); err != nil {
+				return err
+			}
+			buf = make([]byte, n)
+			bytesRead, err := d.connection.Read(buf)
+			if err != nil {
+				return err
+			}
+			if bytesRead != n {
+				return ErrNotEnoughBytes
+			}
+			return nil
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return buf, nil
 }
 
-func (d *BMP180Driver) calculateTemp(rawTemp int16) float32 {
-	b5 := d.calculateB5(rawTemp)
-	t := (b5 + 8) >> 4
-	return float32(t) / 10
+// waitForConversion waits for the end of the conversion, for the given delay
+// or until the SCO bit clears, depending on the wait mode.
+func (d *BMP180Driver) waitForConversion(delay time.Duration) error {
+	if d.waitMode != BMP180WaitPolling {
+		time.Sleep(delay)
+		return nil
+	}
+	deadline := time.Now().Add(bmp180ConversionTimeout)
+	for {
+		ctl, err := d.readReg("ctrl_meas")
+		if err != nil {
+			return err
+		}
+		if ctl&bmp180CtlSCO == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrBMP180ConversionTimeout
+		}
+		time.Sleep(bmp180PollInterval)
+	}
 }
 
-func (d *BMP180Driver) calculateB5(rawTemp int16) int32 {
-	x1 := (int32(rawTemp) - int32(d.calibrationCoefficients.ac6)) * int32(d.calibrationCoefficients.ac5) >> 15
-	x2 := int32(d.calibrationCoefficients.mc) << 11 / (x1 + int32(d.calibrationCoefficients.md))
-	return x1 + x2
+// retry runs a register read, repeating it up to readRetries more times
+// with an increasing delay while it fails.
+func (d *BMP180Driver) retry(read func() error) (err error) {
+	for attempt := 0; ; attempt++ {
+		if err = read(); err == nil || attempt >= d.readRetries {
+			return err
+		}
+		time.Sleep(time.Duration(attempt+1) * bmp180ReadRetryDelay)
+	}
+}
+
+// bmp180TimeoutConnection is a connection whose reads and writes give up
+// after the operation timeout of the driver. The transactions then run in a
+// goroutine, on a copy of the data, one at a time: the driver keeps the one
+// abandoned, and fails the next ones until it completes. It is used with the
+// driver locked.
+type bmp180TimeoutConnection struct {
+	Connection
+	driver *BMP180Driver
+}
+
+func (c *bmp180TimeoutConnection) Read(b []byte) (int, error) {
+	if c.driver.operationTimeout <= 0 {
+		return c.Connection.Read(b)
+	}
+	buf := make([]byte, len(b))
+	n, err := c.withTimeout(func() (int, error) { return c.Connection.Read(buf) })
+	if err == ErrBMP180OperationTimeout {
+		// the read may still be writing to buf.
+		return 0, err
+	}
+	copy(b, buf)
+	return n, err
+}
+
+func (c *bmp180TimeoutConnection) Write(b []byte) (int, error) {
+	if c.driver.operationTimeout <= 0 {
+		return c.Connection.Write(b)
+	}
+	buf := append([]byte{}, b...)
+	return c.withTimeout(func() (int, error) { return c.Connection.Write(buf) })
+}
+
+func (c *bmp180TimeoutConnection) withTimeout(transaction func() (int, error)) (int, error) {
+	if c.driver.abandoned != nil {
+		select {
+		case <-c.driver.abandoned:
+			c.driver.abandoned = nil
+		default:
+			return 0, ErrBMP180OperationTimeout
+		}
+	}
+
+	var n int
+	var err error
+	done := make(chan struct{})
+	go func() {
+		n, err = transaction()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return n, err
+	case <-time.After(c.driver.operationTimeout):
+		c.driver.abandoned = done
+		return 0, ErrBMP180OperationTimeout
+	}
+}
+
+func (d *BMP180Driver) calculateTemp(rawTemp uint16) float32 {
+	return bmp180CalculateTemp(d.calibrationCoefficients, rawTemp)
+}
+
+func (d *BMP180Driver) calculateB5(rawTemp uint16) int32 {
+	return bmp180CalculateB5(d.calibrationCoefficients, rawTemp)
+}
+
+// averageRawPressure returns the average of the raw oversampling number of
+// uncompensated pressures, rounded to the nearest.
+func (d *BMP180Driver) averageRawPressure(mode BMP180OversamplingMode) (int32, error) {
+	if d.rawOversampling <= 1 {
+		return d.rawPressure(mode)
+	}
+	var sum int64
+	for i := 0; i < d.rawOversampling; i++ {
+		rawPressure, err := d.rawPressure(mode)
+		if err != nil {
+			return 0, err
+		}
+		sum += int64(rawPressure)
+	}
+	n := int64(d.rawOversampling)
+	return int32((sum + n/2) / n), nil
 }
 
 func (d *BMP180Driver) rawPressure(mode BMP180OversamplingMode) (rawPressure int32, err error) {
-	if _, err = d.connection.Write([]byte{bmp180RegisterCtl, bmp180CmdPressure + byte(mode<<6)}); err != nil {
+	// the mode is shifted into the command byte, it must fit in 2 bits.
+	if mode > BMP180UltraHighResolution {
+		return 0, ErrInvalidOversamplingMode
+	}
+	if err = d.writeReg("ctrl_meas", bmp180CmdPressure+uint32(mode)<<6); err != nil {
+		return 0, err
+	}
+	if err = d.waitForConversion(d.pressureDelays[mode]); err != nil {
 		return 0, err
 	}
-	time.Sleep(pauseForReading(mode))
 	var ret []byte
 	if ret, err = d.read(bmp180RegisterPressureMSB, 3); err != nil {
 		return 0, err
@@ -212,28 +1701,75 @@ func (d *BMP180Driver) rawPressure(mode BMP180OversamplingMode) (rawPressure int
 	return rawPressure, nil
 }
 
-func (d *BMP180Driver) calculatePressure(rawTemp int16, rawPressure int32, mode BMP180OversamplingMode) float32 {
-	b5 := d.calculateB5(rawTemp)
+func (d *BMP180Driver) calculatePressure(rawTemp uint16, rawPressure int32, mode BMP180OversamplingMode) (float32, error) {
+	return bmp180CalculatePressure(d.calibrationCoefficients, rawTemp, rawPressure, mode)
+}
+
+// bmp180CalculateTemp returns the temperature, in celsius degrees, for the
+// given calibration coefficients and uncompensated temperature.
+func bmp180CalculateTemp(c *BMP180CalibrationCoefficients, rawTemp uint16) float32 {
+	b5 := bmp180CalculateB5(c, rawTemp)
+	t := (b5 + 8) >> 4
+	return float32(t) / 10
+}
+
+// bmp180CalculateB5 returns the B5 term shared by the temperature and
+// pressure compensation.
+func bmp180CalculateB5(c *BMP180CalibrationCoefficients, rawTemp uint16) int32 {
+	x1 := ((int32(rawTemp) - int32(c.AC6)) * int32(c.AC5)) >> 15
+	x2 := (int32(c.MC) << 11) / (x1 + int32(c.MD))
+	return x1 + x2
+}
+
+// bmp180CalculatePressure returns the pressure, in pascals, for the given
+// calibration coefficients, uncompensated temperature and pressure, and
+// the oversampling mode the pressure was measured with. It returns
+// ErrPressureOutOfRange when the inputs would overflow the integer
+// arithmetic of the datasheet, instead of a meaningless value.
+func bmp180CalculatePressure(c *BMP180CalibrationCoefficients, rawTemp uint16, rawPressure int32, mode BMP180OversamplingMode) (float32, error) {
+	return bmp180CalculatePressureB5(c, bmp180CalculateB5(c, rawTemp), rawPressure, mode)
+}
+
+// bmp180TempToB5 returns the B5 term of a temperature in celsius degrees,
+// the inverse of the temperature compensation: the middle of the B5 range
+// giving the temperature rounded to 0.1 degree.
+func bmp180TempToB5(temp float32) int32 {
+	return int32(math.Floor(float64(temp)*10+0.5)) << 4
+}
+
+// bmp180CalculatePressureB5 is bmp180CalculatePressure, for the B5 term of
+// the uncompensated temperature.
+func bmp180CalculatePressureB5(c *BMP180CalibrationCoefficients, b5 int32, rawPressure int32, mode BMP180OversamplingMode) (float32, error) {
 	b6 := b5 - 4000
-	x1 := (int32(d.calibrationCoefficients.b2) * (b6 * b6 >> 12)) >> 11
-	x2 := (int32(d.calibrationCoefficients.ac2) * b6) >> 11
+	x1 := (int32(c.B2) * ((b6 * b6) >> 12)) >> 11
+	x2 := (int32(c.AC2) * b6) >> 11
 	x3 := x1 + x2
-	b3 := (((int32(d.calibrationCoefficients.ac1)*4 + x3) << uint(mode)) + 2) >> 2
-	x1 = (int32(d.calibrationCoefficients.ac3) * b6) >> 13
-	x2 = (int32(d.calibrationCoefficients.b1) * ((b6 * b6) >> 12)) >> 16
+	b3 := (((int32(c.AC1)*4 + x3) << uint(mode)) + 2) >> 2
+	x1 = (int32(c.AC3) * b6) >> 13
+	x2 = (int32(c.B1) * ((b6 * b6) >> 12)) >> 16
 	x3 = ((x1 + x2) + 2) >> 2
-	b4 := (uint32(d.calibrationCoefficients.ac4) * uint32(x3+32768)) >> 15
+	b4 := (uint32(c.AC4) * uint32(x3+32768)) >> 15
+	// below b3 the unsigned b7 wraps around, and p is unbounded for a null
+	// or tiny b4.
+	if rawPressure < b3 || rawPressure>>(16+uint(mode)) != 0 || b4 == 0 {
+		return 0, ErrPressureOutOfRange
+	}
 	b7 := (uint32(rawPressure-b3) * (50000 >> uint(mode)))
-	var p int32
+	var q uint32
 	if b7 < 0x80000000 {
-		p = int32((b7 << 1) / b4)
+		q = (b7 << 1) / b4
 	} else {
-		p = int32((b7 / b4) << 1)
+		q = (b7 / b4) << 1
+	}
+	// beyond, the second order compensation below overflows.
+	if q > bmp180MaxPressure {
+		return 0, ErrPressureOutOfRange
 	}
+	p := int32(q)
 	x1 = (p >> 8) * (p >> 8)
 	x1 = (x1 * 3038) >> 16
 	x2 = (-7357 * p) >> 16
-	return float32(p + ((x1 + x2 + 3791) >> 4))
+	return float32(p + ((x1 + x2 + 3791) >> 4)), nil
 }
 
 func pauseForReading(mode BMP180OversamplingMode) time.Duration {
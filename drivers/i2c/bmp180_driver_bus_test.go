@@ -0,0 +1,387 @@
+package i2c_test
+
+import (
+	"math"
+	"testing"
+
+	"gobot.io/x/gobot/drivers/i2c"
+	"gobot.io/x/gobot/drivers/i2c/i2ctest"
+	"gobot.io/x/gobot/gobottest"
+)
+
+const bmp180TestAddress = 0x77
+
+// newBMP180TestBus returns a simulated bus with a BMP180 answering with the
+// values from the datasheet example.
+func newBMP180TestBus() *i2ctest.Adaptor {
+	bus := i2ctest.NewAdaptor()
+	// chip id
+	bus.SetRegisters(bmp180TestAddress, 0xD0, 0x55)
+	// calibration coefficients, AC1 to MD
+	bus.SetRegisters(bmp180TestAddress, 0xAA,
+		0x01, 0x98, 0xFF, 0xB8, 0xC7, 0xD1, 0x7F, 0xE5, 0x7F, 0xF5, 0x5A, 0x71,
+		0x18, 0x2E, 0x00, 0x04, 0x80, 0x00, 0xDD, 0xF9, 0x0B, 0x34)
+	bus.OnWrite(func(address int, data []byte) {
+		if len(data) != 2 || data[0] != 0xF4 {
+			return
+		}
+		switch data[1] {
+		case 0x2E:
+			// UT = 27898
+			bus.SetRegisters(address, 0xF6, 0x6C, 0xFA)
+		case 0x34:
+			// UP = 23843, in ultra low power mode
+			bus.SetRegisters(address, 0xF6, 0x5D, 0x23, 0x00)
+		}
+	})
+	return bus
+}
+
+func TestBMP180DriverBusMeasurements(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	gobottest.Assert(t, bmp180.Start(), nil)
+
+	bus.ResetTransactions()
+	pressure, err := bmp180.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+	gobottest.Assert(t, bus.Transactions(), []i2ctest.Transaction{
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF4, 0x2E}},
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF6}},
+		{Address: bmp180TestAddress, Data: []byte{0x6C, 0xFA}},
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF4, 0x34}},
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF6}},
+		{Address: bmp180TestAddress, Data: []byte{0x5D, 0x23, 0x00}},
+	})
+}
+
+// bmp180TestConnector counts the connections opened on the bus.
+type bmp180TestConnector struct {
+	*i2ctest.Adaptor
+	connections int
+}
+
+func (c *bmp180TestConnector) GetConnection(address int, bus int) (i2c.Connection, error) {
+	c.connections++
+	return c.Adaptor.GetConnection(address, bus)
+}
+
+func TestBMP180DriverReadingTransactions(t *testing.T) {
+	bus := newBMP180TestBus()
+	connector := &bmp180TestConnector{Adaptor: bus}
+	bmp180 := i2c.NewBMP180Driver(connector)
+	gobottest.Assert(t, bmp180.Start(), nil)
+
+	bus.ResetTransactions()
+	_, err := bmp180.Reading()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, bus.Transactions(), []i2ctest.Transaction{
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF4, 0x2E}},
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF6}},
+		{Address: bmp180TestAddress, Data: []byte{0x6C, 0xFA}},
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF4, 0x34}},
+		{Address: bmp180TestAddress, Write: true, Data: []byte{0xF6}},
+		{Address: bmp180TestAddress, Data: []byte{0x5D, 0x23, 0x00}},
+	})
+	// the connection opened by Start is used.
+	gobottest.Assert(t, connector.connections, 1)
+
+	// separate measurements each measure the temperature.
+	bus.ResetTransactions()
+	bmp180.Temperature()
+	bmp180.Pressure()
+	gobottest.Assert(t, len(bus.Transactions()), 9)
+	gobottest.Assert(t, connector.connections, 1)
+}
+
+func TestBMP180DriverStartInvalidCalibration(t *testing.T) {
+	bus := i2ctest.NewAdaptor()
+	bus.SetRegisters(bmp180TestAddress, 0xD0, 0x55)
+	bmp180 := i2c.NewBMP180Driver(bus)
+	// the calibration read returns 0xFF everywhere, like a floating bus.
+	bus.OnWrite(func(address int, data []byte) {
+		if data[0] == 0xAA {
+			bus.CorruptNextRead()
+		}
+	})
+	gobottest.Assert(t, bmp180.Start(), i2c.ErrInvalidCalibration)
+}
+
+func TestBMP180DriverReadRetries(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	bmp180.Start()
+
+	bus.FailNextRead(1)
+	_, err := bmp180.Temperature()
+	gobottest.Assert(t, err, i2ctest.ErrInjected)
+
+	bmp180.SetReadRetries(2)
+	bus.FailNextRead(2)
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+
+	bus.FailNextRead(3)
+	_, err = bmp180.Temperature()
+	gobottest.Assert(t, err, i2ctest.ErrInjected)
+}
+
+func TestBMP180DriverInitRetries(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	// the first read after power-up fails.
+	bus.FailNextRead(1)
+	gobottest.Assert(t, bmp180.Start(), i2ctest.ErrInjected)
+
+	bmp180.SetInitRetries(2)
+	bus.FailNextRead(1)
+	gobottest.Assert(t, bmp180.Start(), nil)
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+
+	bus.FailNextRead(3)
+	gobottest.Assert(t, bmp180.Start(), i2ctest.ErrInjected)
+}
+
+func TestBMP180DriverPressureMedianFilter(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	bmp180.Start()
+	gobottest.Assert(t, bmp180.SetPressureMedianFilter(2), i2c.ErrInvalidMedianWindow)
+	gobottest.Assert(t, bmp180.SetPressureMedianFilter(0), i2c.ErrInvalidMedianWindow)
+	gobottest.Assert(t, bmp180.SetPressureMedianFilter(3), nil)
+
+	// the second of four pressure conversions returns a spike.
+	conversions := 0
+	bus.OnWrite(func(address int, data []byte) {
+		if len(data) != 2 || data[0] != 0xF4 {
+			return
+		}
+		switch data[1] {
+		case 0x2E:
+			bus.SetRegisters(address, 0xF6, 0x6C, 0xFA)
+		case 0x34:
+			conversions++
+			if conversions == 2 {
+				bus.SetRegisters(address, 0xF6, 0x7F, 0xFF, 0x00)
+			} else {
+				bus.SetRegisters(address, 0xF6, 0x5D, 0x23, 0x00)
+			}
+		}
+	})
+	pressures := []float32{}
+	for i := 0; i < 4; i++ {
+		pressure, err := bmp180.Pressure()
+		gobottest.Assert(t, err, nil)
+		pressures = append(pressures, pressure)
+	}
+	gobottest.Assert(t, pressures[0], float32(69964))
+	// with two pressures, the higher one is returned.
+	gobottest.Assert(t, pressures[1] > float32(69964), true)
+	gobottest.Assert(t, pressures[2], float32(69964))
+	gobottest.Assert(t, pressures[3], float32(69964))
+
+	// a window of 1 disables the filter.
+	gobottest.Assert(t, bmp180.SetPressureMedianFilter(1), nil)
+	conversions = 1
+	pressure, _ := bmp180.Pressure()
+	gobottest.Assert(t, pressure > float32(69964), true)
+}
+
+func TestBMP180DriverPressureAveraging(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	bmp180.Start()
+
+	// the raw pressure alternates between two values.
+	conversions := 0
+	bus.OnWrite(func(address int, data []byte) {
+		if len(data) != 2 || data[0] != 0xF4 {
+			return
+		}
+		switch data[1] {
+		case 0x2E:
+			bus.SetRegisters(address, 0xF6, 0x6C, 0xFA)
+		case 0x34:
+			bus.SetRegisters(address, 0xF6, 0x5D, 0x23+byte(conversions%2)*0x10, 0x00)
+			conversions++
+		}
+	})
+	low, _ := bmp180.Pressure()
+	high, _ := bmp180.Pressure()
+	gobottest.Assert(t, high > low, true)
+
+	bmp180.SetPressureAveraging(3)
+	want := []float32{low, (low + high) / 2, (2*low + high) / 3, (low + 2*high) / 3, (2*low + high) / 3}
+	for i, w := range want {
+		pressure, err := bmp180.Pressure()
+		gobottest.Assert(t, err, nil)
+		if math.Abs(float64(pressure-w)) > 0.01 {
+			t.Errorf("pressure %d = %v, want %v", i, pressure, w)
+		}
+	}
+
+	// 1 disables the averaging.
+	bmp180.SetPressureAveraging(1)
+	pressure, _ := bmp180.Pressure()
+	gobottest.Assert(t, pressure, high)
+}
+
+func TestBMP180DriverCalibrateToReferencePressure(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	bmp180.Start()
+
+	// the pressure alternates between two values.
+	conversions := 0
+	bus.OnWrite(func(address int, data []byte) {
+		if len(data) != 2 || data[0] != 0xF4 {
+			return
+		}
+		switch data[1] {
+		case 0x2E:
+			bus.SetRegisters(address, 0xF6, 0x6C, 0xFA)
+		case 0x34:
+			bus.SetRegisters(address, 0xF6, 0x5D, 0x23+byte(conversions%2)*0x10, 0x00)
+			conversions++
+		}
+	})
+	low, _ := bmp180.Pressure()
+	high, _ := bmp180.Pressure()
+	gobottest.Assert(t, low, float32(69964))
+	gobottest.Assert(t, high > low, true)
+
+	gobottest.Assert(t, bmp180.CalibrateToReferencePressure(101325, 4), nil)
+	offset := 101325 - (low+high)/2
+	pressure, _ := bmp180.Pressure()
+	gobottest.Assert(t, pressure, low+offset)
+
+	// a failed calibration keeps the offset.
+	bus.FailNextRead(1)
+	gobottest.Assert(t, bmp180.CalibrateToReferencePressure(90000, 4), i2ctest.ErrInjected)
+	pressure, _ = bmp180.Pressure()
+	gobottest.Assert(t, pressure, high+offset)
+}
+
+func TestBMP180DriverCalibrateToReferencePressureAltitude(t *testing.T) {
+	bmp180 := i2c.NewBMP180Driver(newBMP180TestBus())
+	bmp180.Start()
+
+	// the last pressure is calibrated too: at the reference pressure of the
+	// sea level, the altitude is 0.
+	gobottest.Assert(t, bmp180.CalibrateToReferencePressure(101325, 4), nil)
+	alt, err := bmp180.Altitude()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt, float32(0))
+}
+
+func TestBMP180DriverCalibrateToReferencePressureMedianFilter(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	bmp180.Start()
+	gobottest.Assert(t, bmp180.SetPressureMedianFilter(3), nil)
+
+	// the pressure alternates between two values.
+	conversions := 0
+	bus.OnWrite(func(address int, data []byte) {
+		if len(data) != 2 || data[0] != 0xF4 {
+			return
+		}
+		switch data[1] {
+		case 0x2E:
+			bus.SetRegisters(address, 0xF6, 0x6C, 0xFA)
+		case 0x34:
+			bus.SetRegisters(address, 0xF6, 0x5D, 0x23+byte(conversions%2)*0x10, 0x00)
+			conversions++
+		}
+	})
+	low, _ := bmp180.Pressure()
+	high, _ := bmp180.Pressure()
+	pressure, _ := bmp180.Pressure()
+	gobottest.Assert(t, pressure, low)
+
+	gobottest.Assert(t, bmp180.CalibrateToReferencePressure(101325, 4), nil)
+	offset := 101325 - (low+high)/2
+	// the filter keeps low, high, low, moved by the offset: a high then
+	// a low pressure give the low median, where a new filter would give
+	// the high one.
+	pressure, _ = bmp180.Pressure()
+	gobottest.Assert(t, pressure, high+offset)
+	pressure, _ = bmp180.Pressure()
+	gobottest.Assert(t, pressure, low+offset)
+}
+
+func TestBMP180DriverTemperatureSource(t *testing.T) {
+	bmp180 := i2c.NewBMP180Driver(newBMP180TestBus())
+	bmp180.Start()
+	bus := newBMP180TestBus()
+	other := i2c.NewBMP180Driver(bus)
+	other.Start()
+
+	// the pressure of the other sensor is compensated with the temperature
+	// of the first one, and it measures no temperature.
+	other.SetTemperatureSource(bmp180.Temperature)
+	bus.ResetTransactions()
+	pressure, err := other.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+	for _, tr := range bus.Transactions() {
+		gobottest.Refute(t, tr.Data, []byte{0xF4, 0x2E})
+	}
+
+	other.SetTemperatureSource(func() (float32, error) {
+		return 0, i2ctest.ErrInjected
+	})
+	_, err = other.Pressure()
+	gobottest.Assert(t, err, i2ctest.ErrInjected)
+
+	other.SetTemperatureSource(nil)
+	pressure, _ = other.Pressure()
+	gobottest.Assert(t, pressure, float32(69964))
+}
+
+func TestBMP180DriverPlausibleRanges(t *testing.T) {
+	bus := newBMP180TestBus()
+	bmp180 := i2c.NewBMP180Driver(bus)
+	bmp180.Start()
+
+	// UT = 49152, about 159 celsius degrees.
+	hot := func(address int, data []byte) {
+		if len(data) == 2 && data[0] == 0xF4 && data[1] == 0x2E {
+			bus.SetRegisters(address, 0xF6, 0xC0, 0x00)
+		}
+	}
+	bus.OnWrite(hot)
+	_, err := bmp180.Temperature()
+	gobottest.Assert(t, err, i2c.ErrTemperatureOutOfRange)
+	_, err = bmp180.Reading()
+	gobottest.Assert(t, err, i2c.ErrTemperatureOutOfRange)
+	gobottest.Assert(t, bmp180.SampleCount(), uint64(0))
+
+	bmp180.SetPlausibleRanges(-40, 200, 30000, 110000)
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp > 85, true)
+
+	bus = newBMP180TestBus()
+	bmp180 = i2c.NewBMP180Driver(bus)
+	bmp180.Start()
+	bmp180.SetPressureCalibration(1, 50000)
+	_, err = bmp180.Pressure()
+	gobottest.Assert(t, err, i2c.ErrPressureOutOfRange)
+	_, err = bmp180.Reading()
+	gobottest.Assert(t, err, i2c.ErrPressureOutOfRange)
+	// NaN is never plausible.
+	bmp180.SetPressureCalibration(float32(math.NaN()), 0)
+	_, err = bmp180.Pressure()
+	gobottest.Assert(t, err, i2c.ErrPressureOutOfRange)
+
+	bmp180.SetPressureCalibration(1, 50000)
+	bmp180.SetPlausibleRanges(-40, 85, 30000, 130000)
+	pressure, err := bmp180.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(119964))
+}
@@ -3,7 +3,13 @@ package i2c
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +18,9 @@ import (
 )
 
 var _ gobot.Driver = (*BMP180Driver)(nil)
+var _ Config = (*BMP180Driver)(nil)
+var _ io.Closer = (*BMP180Driver)(nil)
+var _ fmt.Stringer = (*BMP180Driver)(nil)
 
 // --------- HELPERS
 func initTestBMP180Driver() (driver *BMP180Driver) {
@@ -24,6 +33,36 @@ func initTestBMP180DriverWithStubbedAdaptor() (*BMP180Driver, *i2cTestAdaptor) {
 	return NewBMP180Driver(adaptor), adaptor
 }
 
+// bmp180TestReadImpl answers reads with the values from the datasheet example.
+func bmp180TestReadImpl(adaptor *i2cTestAdaptor) func([]byte) (int, error) {
+	return func(b []byte) (int, error) {
+		buf := new(bytes.Buffer)
+		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterChipID {
+			buf.WriteByte(bmp180ChipID)
+		} else if adaptor.written[len(adaptor.written)-1] == bmp180RegisterAC1MSB {
+			binary.Write(buf, binary.BigEndian, int16(408))
+			binary.Write(buf, binary.BigEndian, int16(-72))
+			binary.Write(buf, binary.BigEndian, int16(-14383))
+			binary.Write(buf, binary.BigEndian, uint16(32741))
+			binary.Write(buf, binary.BigEndian, uint16(32757))
+			binary.Write(buf, binary.BigEndian, uint16(23153))
+			binary.Write(buf, binary.BigEndian, int16(6190))
+			binary.Write(buf, binary.BigEndian, int16(4))
+			binary.Write(buf, binary.BigEndian, int16(-32768))
+			binary.Write(buf, binary.BigEndian, int16(-8711))
+			binary.Write(buf, binary.BigEndian, int16(2868))
+		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdTemp && adaptor.written[len(adaptor.written)-1] == bmp180RegisterTempMSB {
+			binary.Write(buf, binary.BigEndian, int16(27898))
+		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdPressure && adaptor.written[len(adaptor.written)-1] == bmp180RegisterPressureMSB {
+			binary.Write(buf, binary.BigEndian, int16(23843))
+			// XLSB, not used in this test.
+			buf.WriteByte(0)
+		}
+		copy(b, buf.Bytes())
+		return buf.Len(), nil
+	}
+}
+
 // --------- TESTS
 
 func TestNewBMP180Driver(t *testing.T) {
@@ -41,10 +80,45 @@ func TestBMP180Driver(t *testing.T) {
 }
 
 func TestBMP180DriverStart(t *testing.T) {
-	bmp180, _ := initTestBMP180DriverWithStubbedAdaptor()
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
 	gobottest.Assert(t, bmp180.Start(), nil)
 }
 
+func TestBMP180DriverStartNoGoroutine(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	gobottest.Assert(t, bmp180.Start(), nil)
+	bmp180.Pressure()
+
+	// the BMP180 does not poll: it has no events to publish, and no channel
+	// to stop a goroutine with.
+	var d interface{} = bmp180
+	_, ok := d.(gobot.Eventer)
+	gobottest.Assert(t, ok, false)
+	v := reflect.ValueOf(bmp180).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).Kind() == reflect.Chan {
+			gobottest.Assert(t, v.Field(i).IsNil(), true)
+		}
+	}
+}
+
+func TestBMP180DriverStartChipIDError(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		// BMP280 chip id
+		b[0] = 0x58
+		return 1, nil
+	}
+	gobottest.Assert(t, bmp180.Start(), errors.New("BMP180 device not found (chip id 0x58)"))
+}
+
+func TestBMP180DriverStartNotEnoughBytes(t *testing.T) {
+	bmp180, _ := initTestBMP180DriverWithStubbedAdaptor()
+	gobottest.Assert(t, bmp180.Start(), ErrNotEnoughBytes)
+}
+
 func TestBMP180StartConnectError(t *testing.T) {
 	d, adaptor := initTestBMP180DriverWithStubbedAdaptor()
 	adaptor.Testi2cConnectErr(true)
@@ -65,68 +139,708 @@ func TestBMP180DriverHalt(t *testing.T) {
 	gobottest.Assert(t, bmp180.Halt(), nil)
 }
 
+func TestBMP180DriverClose(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	gobottest.Assert(t, bmp180.Start(), nil)
+
+	gobottest.Assert(t, bmp180.Close(), nil)
+	gobottest.Assert(t, bmp180.Close(), nil)
+	// the driver can still be started again.
+	gobottest.Assert(t, bmp180.Start(), nil)
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+}
+
+func TestBMP180DriverSupportsHumidity(t *testing.T) {
+	bmp180 := initTestBMP180Driver()
+
+	gobottest.Assert(t, bmp180.SupportsHumidity(), false)
+}
+
+func TestBMP180DriverInterfaces(t *testing.T) {
+	var bmp180 interface{} = initTestBMP180Driver()
+
+	// the options need the driver to be a Config.
+	_, ok := bmp180.(Config)
+	gobottest.Assert(t, ok, true)
+	_, ok = bmp180.(fmt.Stringer)
+	gobottest.Assert(t, ok, true)
+	// the driver measures on demand, and has no events.
+	_, ok = bmp180.(gobot.Eventer)
+	gobottest.Assert(t, ok, false)
+	// the measurements are API commands.
+	_, ok = bmp180.(gobot.Commander)
+	gobottest.Assert(t, ok, true)
+}
+
+func TestBMP180DriverCommands(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+
+	result := bmp180.Command("Temperature")(map[string]interface{}{})
+	gobottest.Assert(t, result.(map[string]interface{})["temperature"], float32(15.0))
+	gobottest.Assert(t, result.(map[string]interface{})["err"], nil)
+
+	result = bmp180.Command("Pressure")(map[string]interface{}{})
+	pressure, _ := bmp180.Pressure()
+	gobottest.Assert(t, result.(map[string]interface{})["pressure"], pressure)
+
+	result = bmp180.Command("Altitude")(map[string]interface{}{})
+	alt, _ := bmp180.Altitude()
+	gobottest.Assert(t, result.(map[string]interface{})["altitude"], alt)
+	gobottest.Assert(t, result.(map[string]interface{})["err"], nil)
+}
+
+func TestBMP180DriverCommandsError(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	adaptor.i2cReadImpl = func([]byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+
+	result := bmp180.Command("Temperature")(map[string]interface{}{})
+	gobottest.Assert(t, result.(map[string]interface{})["err"], errors.New("read error"))
+}
+
 func TestBMP180DriverMeasurements(t *testing.T) {
 	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+	pressure, err := bmp180.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+}
+
+func TestBMP180DriverLinearCalibration(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	bmp180.SetTemperatureCalibration(2, -1.5)
+	bmp180.SetPressureCalibration(1, 250)
+
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(28.5))
+	pressure, err := bmp180.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(70214))
+
+	// the datasheet conversion itself is untouched.
+	gobottest.Assert(t, bmp180.calculateTemp(27898), float32(15.0))
+	pressure, err = bmp180.calculatePressure(27898, 23843, BMP180UltraLowPower)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+}
+
+func TestBMP180DriverReading(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	before := time.Now()
+	r, err := bmp180.Reading()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, r.Temperature, float32(15.0))
+	gobottest.Assert(t, r.Pressure, float32(69964))
+	gobottest.Assert(t, r.Altitude, float32(3016.6592))
+	gobottest.Assert(t, r.Time.Before(before), false)
+}
+
+func TestBMP180DriverReadingHook(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	clock := time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	bmp180.now = func() time.Time { return clock }
+
+	var hooked []BMP180Reading
+	bmp180.SetReadingHook(func(r BMP180Reading) {
+		hooked = append(hooked, r)
+		// the driver is not locked.
+		bmp180.SampleCount()
+	})
+	var returned []BMP180Reading
+	for i := 0; i < 3; i++ {
+		clock = clock.Add(time.Second)
+		r, err := bmp180.Reading()
+		gobottest.Assert(t, err, nil)
+		returned = append(returned, r)
+	}
+	gobottest.Assert(t, hooked, returned)
+	gobottest.Assert(t, hooked[2].Time, time.Date(2017, 4, 1, 12, 30, 3, 0, time.UTC))
+
+	// not called with the failed readings, nor once removed.
 	adaptor.i2cReadImpl = func(b []byte) (int, error) {
-		buf := new(bytes.Buffer)
-		// Values from the datasheet example.
-		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterAC1MSB {
-			binary.Write(buf, binary.BigEndian, int16(408))
-			binary.Write(buf, binary.BigEndian, int16(-72))
-			binary.Write(buf, binary.BigEndian, int16(-14383))
-			binary.Write(buf, binary.BigEndian, uint16(32741))
-			binary.Write(buf, binary.BigEndian, uint16(32757))
-			binary.Write(buf, binary.BigEndian, uint16(23153))
-			binary.Write(buf, binary.BigEndian, int16(6190))
-			binary.Write(buf, binary.BigEndian, int16(4))
-			binary.Write(buf, binary.BigEndian, int16(-32768))
-			binary.Write(buf, binary.BigEndian, int16(-8711))
-			binary.Write(buf, binary.BigEndian, int16(2868))
-		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdTemp && adaptor.written[len(adaptor.written)-1] == bmp180RegisterTempMSB {
-			binary.Write(buf, binary.BigEndian, int16(27898))
-		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdPressure && adaptor.written[len(adaptor.written)-1] == bmp180RegisterPressureMSB {
-			binary.Write(buf, binary.BigEndian, int16(23843))
-			// XLSB, not used in this test.
-			buf.WriteByte(0)
+		return 0, errors.New("read error")
+	}
+	bmp180.Reading()
+	gobottest.Assert(t, len(hooked), 3)
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.SetReadingHook(nil)
+	bmp180.Reading()
+	gobottest.Assert(t, len(hooked), 3)
+}
+
+func TestBMP180DriverSampleCount(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	clock := time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	bmp180.now = func() time.Time { return clock }
+	gobottest.Assert(t, bmp180.SampleCount(), uint64(0))
+	gobottest.Assert(t, bmp180.LastReadTime().IsZero(), true)
+
+	for i := 1; i <= 3; i++ {
+		clock = clock.Add(time.Second)
+		bmp180.Temperature()
+		gobottest.Assert(t, bmp180.SampleCount(), uint64(i))
+		gobottest.Assert(t, bmp180.LastReadTime(), clock)
+	}
+	clock = clock.Add(time.Second)
+	bmp180.Pressure()
+	gobottest.Assert(t, bmp180.SampleCount(), uint64(4))
+	clock = clock.Add(time.Second)
+	r, _ := bmp180.Reading()
+	gobottest.Assert(t, bmp180.SampleCount(), uint64(5))
+	gobottest.Assert(t, bmp180.LastReadTime(), time.Date(2017, 4, 1, 12, 30, 5, 0, time.UTC))
+	gobottest.Assert(t, r.Time, bmp180.LastReadTime())
+
+	// a failed reading is not a sample.
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	clock = clock.Add(time.Second)
+	bmp180.Pressure()
+	gobottest.Assert(t, bmp180.SampleCount(), uint64(5))
+	gobottest.Assert(t, bmp180.LastReadTime(), time.Date(2017, 4, 1, 12, 30, 5, 0, time.UTC))
+}
+
+func TestBMP180DriverHistory(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	clock := time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	bmp180.now = func() time.Time { return clock }
+
+	// no history by default.
+	bmp180.Reading()
+	gobottest.Assert(t, len(bmp180.History()), 0)
+
+	bmp180.SetHistorySize(3)
+	for i := 1; i <= 5; i++ {
+		clock = clock.Add(time.Minute)
+		bmp180.Reading()
+	}
+	// the 2 oldest readings are dropped.
+	history := bmp180.History()
+	gobottest.Assert(t, len(history), 3)
+	for i, r := range history {
+		gobottest.Assert(t, r.Time, time.Date(2017, 4, 1, 12, 33+i, 0, 0, time.UTC))
+		gobottest.Assert(t, r.Temperature, float32(15.0))
+	}
+	// a measurement other than Reading is not kept.
+	bmp180.Temperature()
+	gobottest.Assert(t, len(bmp180.History()), 3)
+
+	since := bmp180.HistorySince(time.Date(2017, 4, 1, 12, 34, 0, 0, time.UTC))
+	gobottest.Assert(t, len(since), 2)
+	gobottest.Assert(t, since[0].Time, time.Date(2017, 4, 1, 12, 34, 0, 0, time.UTC))
+	gobottest.Assert(t, len(bmp180.HistorySince(clock.Add(time.Second))), 0)
+	gobottest.Assert(t, len(bmp180.HistorySince(time.Time{})), 3)
+
+	// the history returned is a copy.
+	history[0].Temperature = 0
+	gobottest.Assert(t, bmp180.History()[0].Temperature, float32(15.0))
+
+	// the pressures are kept in pascals, and returned in the current unit.
+	gobottest.Assert(t, history[0].Pressure, float32(69964))
+	bmp180.SetPressureUnit(BMP180Hectopascal)
+	gobottest.Assert(t, bmp180.History()[0].Pressure, float32(699.64))
+	gobottest.Assert(t, bmp180.HistorySince(time.Time{})[0].Pressure, float32(699.64))
+}
+
+func TestBMP180DriverSetHistorySize(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	clock := time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	bmp180.now = func() time.Time { return clock }
+	bmp180.SetHistorySize(4)
+	for i := 1; i <= 6; i++ {
+		clock = clock.Add(time.Minute)
+		bmp180.Reading()
+	}
+
+	// the most recent readings are kept.
+	bmp180.SetHistorySize(2)
+	history := bmp180.History()
+	gobottest.Assert(t, len(history), 2)
+	gobottest.Assert(t, history[0].Time, time.Date(2017, 4, 1, 12, 35, 0, 0, time.UTC))
+	gobottest.Assert(t, history[1].Time, time.Date(2017, 4, 1, 12, 36, 0, 0, time.UTC))
+
+	bmp180.SetHistorySize(5)
+	clock = clock.Add(time.Minute)
+	bmp180.Reading()
+	history = bmp180.History()
+	gobottest.Assert(t, len(history), 3)
+	gobottest.Assert(t, history[2].Time, time.Date(2017, 4, 1, 12, 37, 0, 0, time.UTC))
+
+	bmp180.SetHistorySize(0)
+	bmp180.Reading()
+	gobottest.Assert(t, len(bmp180.History()), 0)
+}
+
+// bmp180TestPressureSeries fills the history with readings every 15 minutes
+// from start, whose pressures are returned by pressure at their hour.
+func bmp180TestPressureSeries(d *BMP180Driver, start time.Time, n int, pressure func(hours float64) float32) {
+	d.SetHistorySize(n)
+	for i := 0; i < n; i++ {
+		hours := float64(i) / 4
+		d.addHistory(BMP180Reading{
+			Pressure: pressure(hours),
+			Time:     start.Add(time.Duration(hours * float64(time.Hour))),
+		})
+	}
+}
+
+func TestBMP180DriverPressureTrend(t *testing.T) {
+	bmp180 := initTestBMP180Driver()
+	start := time.Date(2017, 4, 1, 6, 0, 0, 0, time.UTC)
+
+	// steady without history.
+	trend, rate := bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendSteady)
+	gobottest.Assert(t, rate, float32(0))
+
+	bmp180TestPressureSeries(bmp180, start, 13, func(h float64) float32 { return float32(100000 + 50*h) })
+	trend, rate = bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendRising)
+	gobottest.Assert(t, rate, float32(50))
+
+	bmp180TestPressureSeries(bmp180, start, 13, func(h float64) float32 { return float32(101000 - 120*h) })
+	trend, rate = bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendFalling)
+	gobottest.Assert(t, rate, float32(-120))
+
+	// the noise of the readings is averaged out by the fit.
+	bmp180TestPressureSeries(bmp180, start, 13, func(h float64) float32 {
+		if int(h*4)%2 == 0 {
+			return 100000 + 30
 		}
-		copy(b, buf.Bytes())
-		return buf.Len(), nil
+		return 100000 - 30
+	})
+	trend, rate = bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendSteady)
+	gobottest.Assert(t, rate > -10 && rate < 10, true)
+	gobottest.Assert(t, trend.String(), "Steady")
+}
+
+func TestBMP180DriverSetPressureTrend(t *testing.T) {
+	bmp180 := initTestBMP180Driver()
+	start := time.Date(2017, 4, 1, 6, 0, 0, 0, time.UTC)
+	// falling for 3 hours, then rising for the last hour.
+	bmp180TestPressureSeries(bmp180, start, 17, func(h float64) float32 {
+		if h <= 3 {
+			return float32(100000 - 100*h)
+		}
+		return float32(99700 + 20*(h-3))
+	})
+	trend, _ := bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendFalling)
+
+	// only the readings of the last hour.
+	bmp180.SetPressureTrend(time.Hour, 10)
+	trend, rate := bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendRising)
+	gobottest.Assert(t, rate, float32(20))
+
+	// within the deadband.
+	bmp180.SetPressureTrend(time.Hour, 25)
+	trend, rate = bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendSteady)
+	gobottest.Assert(t, rate, float32(20))
+
+	// the deadband stays in pascals, only the rate is converted.
+	bmp180.SetPressureTrend(time.Hour, 10)
+	bmp180.SetPressureUnit(BMP180Hectopascal)
+	trend, rate = bmp180.PressureTrend()
+	gobottest.Assert(t, trend, BMP180TrendRising)
+	gobottest.Assert(t, rate, float32(0.2))
+}
+
+// bmp180TestBusLock is a bus lock counting how many times it is locked.
+type bmp180TestBusLock struct {
+	locked bool
+	locks  int
+}
+
+func (l *bmp180TestBusLock) Lock() {
+	l.locked = true
+	l.locks++
+}
+
+func (l *bmp180TestBusLock) Unlock() {
+	l.locked = false
+}
+
+func TestBMP180DriverSetBusLock(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	readImpl := bmp180TestReadImpl(adaptor)
+	adaptor.i2cReadImpl = readImpl
+	bmp180.Start()
+	lock := &bmp180TestBusLock{}
+	bmp180.SetBusLock(lock)
+
+	// the address write and the data read are done with the lock held.
+	writes, reads := 0, 0
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		gobottest.Assert(t, lock.locked, true)
+		writes++
+		return len(b), nil
+	}
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		gobottest.Assert(t, lock.locked, true)
+		reads++
+		return readImpl(b)
+	}
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+	gobottest.Assert(t, lock.locked, false)
+	// the command write, and the address write with its read.
+	gobottest.Assert(t, writes, 2)
+	gobottest.Assert(t, reads, 1)
+	gobottest.Assert(t, lock.locks, 2)
+
+	// the lock is released on error.
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = bmp180.Temperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+	gobottest.Assert(t, lock.locked, false)
+
+	bmp180.SetBusLock(nil)
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		return len(b), nil
 	}
+	adaptor.i2cReadImpl = readImpl
+	_, err = bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, lock.locks, 4)
+}
+
+func TestBMP180ReadingJSON(t *testing.T) {
+	r := BMP180Reading{
+		Temperature: 15,
+		Pressure:    69964,
+		Altitude:    3016.6592,
+		Time:        time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC),
+	}
+	b, err := json.Marshal(r)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, string(b),
+		`{"temperature":15,"pressure":69964,"altitude":3016.6592,"timestamp":"2017-04-01T12:30:00Z"}`)
+
+	var u BMP180Reading
+	gobottest.Assert(t, json.Unmarshal(b, &u), nil)
+	gobottest.Assert(t, u, r)
+}
+
+func TestBMP180DriverReadingError(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err := bmp180.Reading()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP180DriverConversionDelays(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	gobottest.Assert(t, bmp180.tempDelay, 5*time.Millisecond)
+	gobottest.Assert(t, bmp180.pressureDelays, [4]time.Duration{
+		5 * time.Millisecond, 8 * time.Millisecond, 14 * time.Millisecond, 26 * time.Millisecond,
+	})
+
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
 	bmp180.Start()
+	bmp180.SetConversionDelays(0, [4]time.Duration{})
+	adaptor.written = []byte{}
+	pressure, err := bmp180.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+	gobottest.Assert(t, adaptor.written, []byte{
+		bmp180RegisterCtl, bmp180CmdTemp, bmp180RegisterTempMSB,
+		bmp180RegisterCtl, bmp180CmdPressure, bmp180RegisterPressureMSB,
+	})
+}
+
+// bmp180TestPollingImpl answers the reads of the control register with the
+// last command, whose SCO bit stays set for the first n reads, and counts
+// them in polls. The other reads are answered by bmp180TestReadImpl.
+func bmp180TestPollingImpl(adaptor *i2cTestAdaptor, n int, polls *int) func([]byte) (int, error) {
+	readImpl := bmp180TestReadImpl(adaptor)
+	pending := 0
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		if len(b) == 2 && b[0] == bmp180RegisterCtl {
+			pending = n
+		}
+		return len(b), nil
+	}
+	return func(b []byte) (int, error) {
+		if adaptor.written[len(adaptor.written)-1] != bmp180RegisterCtl {
+			return readImpl(b)
+		}
+		*polls++
+		// hide the poll, bmp180TestReadImpl expects the command last.
+		adaptor.written = adaptor.written[:len(adaptor.written)-1]
+		b[0] = adaptor.written[len(adaptor.written)-1] &^ bmp180CtlSCO
+		if pending > 0 {
+			pending--
+			b[0] |= bmp180CtlSCO
+		}
+		return 1, nil
+	}
+}
+
+func TestBMP180DriverWaitPolling(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	polls := 0
+	adaptor.i2cReadImpl = bmp180TestPollingImpl(adaptor, 3, &polls)
+	bmp180.Start()
+	bmp180.SetWaitMode(BMP180WaitPolling)
+	// without the fixed delays, only the polling waits for the conversions.
+	bmp180.SetConversionDelays(time.Hour, [4]time.Duration{time.Hour, time.Hour, time.Hour, time.Hour})
 	temp, err := bmp180.Temperature()
 	gobottest.Assert(t, err, nil)
 	gobottest.Assert(t, temp, float32(15.0))
+	gobottest.Assert(t, polls, 4)
+
+	polls = 0
 	pressure, err := bmp180.Pressure()
 	gobottest.Assert(t, err, nil)
 	gobottest.Assert(t, pressure, float32(69964))
+	gobottest.Assert(t, polls, 8)
+}
+
+func TestBMP180DriverWaitPollingTimeout(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	polls := 0
+	adaptor.i2cReadImpl = bmp180TestPollingImpl(adaptor, 1000000, &polls)
+	bmp180.Start()
+	bmp180.SetWaitMode(BMP180WaitPolling)
+	_, err := bmp180.Temperature()
+	gobottest.Assert(t, err, ErrBMP180ConversionTimeout)
+
+	adaptor.i2cReadImpl = func([]byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = bmp180.Temperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP180DriverRawMeasurements(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	rawTemp, err := bmp180.RawTemperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, rawTemp, uint16(27898))
+	rawPressure, err := bmp180.RawPressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, rawPressure, int32(23843))
+}
+
+func TestBMP180DriverRawMeasurementsError(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err := bmp180.RawTemperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+	_, err = bmp180.RawPressure()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP180DriverMode(t *testing.T) {
+	bmp180 := NewBMP180Driver(newI2cTestAdaptor(), WithBMP180Mode(BMP180Standard))
+	gobottest.Assert(t, bmp180.Mode, BMP180Standard)
+	bmp180 = NewBMP180Driver(newI2cTestAdaptor(), WithBMP180Mode(7))
+	gobottest.Assert(t, bmp180.Mode, BMP180UltraLowPower)
+
+	gobottest.Assert(t, bmp180.SetMode(BMP180UltraHighResolution), nil)
+	gobottest.Assert(t, bmp180.Mode, BMP180UltraHighResolution)
+	gobottest.Assert(t, bmp180.SetMode(7), ErrInvalidOversamplingMode)
+	gobottest.Assert(t, bmp180.Mode, BMP180UltraHighResolution)
+}
+
+func TestBMP180DriverPressureNoiseEstimate(t *testing.T) {
+	bmp180 := initTestBMP180Driver()
+	gobottest.Assert(t, bmp180.PressureNoiseEstimate(), float32(6))
+	bmp180.SetMode(BMP180Standard)
+	gobottest.Assert(t, bmp180.PressureNoiseEstimate(), float32(5))
+	bmp180.SetMode(BMP180HighResolution)
+	gobottest.Assert(t, bmp180.PressureNoiseEstimate(), float32(4))
+	bmp180.SetMode(BMP180UltraHighResolution)
+	gobottest.Assert(t, bmp180.PressureNoiseEstimate(), float32(3))
+	// an invalid mode is left unchanged.
+	bmp180.SetMode(7)
+	gobottest.Assert(t, bmp180.PressureNoiseEstimate(), float32(3))
+}
+
+func TestBMP180DriverInvalidMode(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	bmp180.Mode = 7
+	adaptor.written = []byte{}
+	_, err := bmp180.Pressure()
+	gobottest.Assert(t, err, ErrInvalidOversamplingMode)
+	// no pressure command is sent with a corrupted mode.
+	gobottest.Assert(t, adaptor.written, []byte{bmp180RegisterCtl, bmp180CmdTemp, bmp180RegisterTempMSB})
+}
+
+func TestBMP180DriverConcurrentMeasurements(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			temp, err := bmp180.Temperature()
+			gobottest.Assert(t, err, nil)
+			gobottest.Assert(t, temp, float32(15.0))
+		}()
+		go func() {
+			defer wg.Done()
+			pressure, err := bmp180.Pressure()
+			gobottest.Assert(t, err, nil)
+			gobottest.Assert(t, pressure, float32(69964))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBMP180DriverCalculations(t *testing.T) {
+	bmp180 := initTestBMP180Driver()
+	// Values from the datasheet example.
+	bmp180.calibrationCoefficients = &BMP180CalibrationCoefficients{
+		AC1: 408,
+		AC2: -72,
+		AC3: -14383,
+		AC4: 32741,
+		AC5: 32757,
+		AC6: 23153,
+		B1:  6190,
+		B2:  4,
+		MB:  -32768,
+		MC:  -8711,
+		MD:  2868,
+	}
+	gobottest.Assert(t, bmp180.calculateTemp(27898), float32(15.0))
+	pressure, err := bmp180.calculatePressure(27898, 23843, BMP180UltraLowPower)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+}
+
+func TestBMP180Calculations(t *testing.T) {
+	// Coefficients from the datasheet example.
+	c := &BMP180CalibrationCoefficients{
+		AC1: 408,
+		AC2: -72,
+		AC3: -14383,
+		AC4: 32741,
+		AC5: 32757,
+		AC6: 23153,
+		B1:  6190,
+		B2:  4,
+		MB:  -32768,
+		MC:  -8711,
+		MD:  2868,
+	}
+	var tests = map[string]struct {
+		rawTemp     uint16
+		rawPressure int32
+		mode        BMP180OversamplingMode
+		temp        float32
+		pressure    float32
+		err         error
+	}{
+		"datasheet": {
+			rawTemp: 27898, rawPressure: 23843, mode: BMP180UltraLowPower,
+			temp: 15.0, pressure: 69964,
+		},
+		"very cold": {
+			rawTemp: 24000, rawPressure: 23843, mode: BMP180UltraLowPower,
+			temp: -24.7, pressure: 63798,
+		},
+		"hot": {
+			rawTemp: 30000, rawPressure: 23843, mode: BMP180UltraLowPower,
+			temp: 31.3, pressure: 72532,
+		},
+		"near vacuum": {
+			rawTemp: 27898, rawPressure: 1000, mode: BMP180UltraLowPower,
+			temp: 15.0, pressure: 1951,
+		},
+		"ultra high resolution": {
+			rawTemp: 24000, rawPressure: 23843 << 3, mode: BMP180UltraHighResolution,
+			temp: -24.7, pressure: 63797,
+		},
+		"above 32767": {
+			rawTemp: 33000, rawPressure: 23843, mode: BMP180UltraLowPower,
+			temp: 52.8, pressure: 75900,
+		},
+		"below b3": {
+			rawTemp: 27898, rawPressure: 100, mode: BMP180UltraLowPower,
+			temp: 15.0, err: ErrPressureOutOfRange,
+		},
+		"negative": {
+			rawTemp: 27898, rawPressure: -1, mode: BMP180UltraLowPower,
+			temp: 15.0, err: ErrPressureOutOfRange,
+		},
+		"too many bits": {
+			rawTemp: 27898, rawPressure: 0x10000, mode: BMP180UltraLowPower,
+			temp: 15.0, err: ErrPressureOutOfRange,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gobottest.Assert(t, bmp180CalculateTemp(c, tc.rawTemp), tc.temp)
+			pressure, err := bmp180CalculatePressure(c, tc.rawTemp, tc.rawPressure, tc.mode)
+			gobottest.Assert(t, err, tc.err)
+			gobottest.Assert(t, pressure, tc.pressure)
+		})
+	}
+
+	// a tiny b4 would overflow the second order compensation.
+	bad := *c
+	bad.AC4 = 1
+	_, err := bmp180CalculatePressure(&bad, 27898, 23843, BMP180UltraLowPower)
+	gobottest.Assert(t, err, ErrPressureOutOfRange)
 }
 
 func TestBMP180DriverTemperatureError(t *testing.T) {
 	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	readImpl := bmp180TestReadImpl(adaptor)
 	adaptor.i2cReadImpl = func(b []byte) (int, error) {
-		buf := new(bytes.Buffer)
-		// Values from the datasheet example.
-		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterAC1MSB {
-			binary.Write(buf, binary.BigEndian, int16(408))
-			binary.Write(buf, binary.BigEndian, int16(-72))
-			binary.Write(buf, binary.BigEndian, int16(-14383))
-			binary.Write(buf, binary.BigEndian, uint16(32741))
-			binary.Write(buf, binary.BigEndian, uint16(32757))
-			binary.Write(buf, binary.BigEndian, uint16(23153))
-			binary.Write(buf, binary.BigEndian, int16(6190))
-			binary.Write(buf, binary.BigEndian, int16(4))
-			binary.Write(buf, binary.BigEndian, int16(-32768))
-			binary.Write(buf, binary.BigEndian, int16(-8711))
-			binary.Write(buf, binary.BigEndian, int16(2868))
-		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdTemp && adaptor.written[len(adaptor.written)-1] == bmp180RegisterTempMSB {
+		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterTempMSB {
 			return 0, errors.New("temp error")
-		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdPressure && adaptor.written[len(adaptor.written)-1] == bmp180RegisterPressureMSB {
-			binary.Write(buf, binary.BigEndian, int16(23843))
-			// XLSB, not used in this test.
-			buf.WriteByte(0)
 		}
-		copy(b, buf.Bytes())
-		return buf.Len(), nil
+		return readImpl(b)
 	}
 	bmp180.Start()
 	_, err := bmp180.Temperature()
@@ -135,28 +849,12 @@ func TestBMP180DriverTemperatureError(t *testing.T) {
 
 func TestBMP180DriverPressureError(t *testing.T) {
 	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	readImpl := bmp180TestReadImpl(adaptor)
 	adaptor.i2cReadImpl = func(b []byte) (int, error) {
-		buf := new(bytes.Buffer)
-		// Values from the datasheet example.
-		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterAC1MSB {
-			binary.Write(buf, binary.BigEndian, int16(408))
-			binary.Write(buf, binary.BigEndian, int16(-72))
-			binary.Write(buf, binary.BigEndian, int16(-14383))
-			binary.Write(buf, binary.BigEndian, uint16(32741))
-			binary.Write(buf, binary.BigEndian, uint16(32757))
-			binary.Write(buf, binary.BigEndian, uint16(23153))
-			binary.Write(buf, binary.BigEndian, int16(6190))
-			binary.Write(buf, binary.BigEndian, int16(4))
-			binary.Write(buf, binary.BigEndian, int16(-32768))
-			binary.Write(buf, binary.BigEndian, int16(-8711))
-			binary.Write(buf, binary.BigEndian, int16(2868))
-		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdTemp && adaptor.written[len(adaptor.written)-1] == bmp180RegisterTempMSB {
-			binary.Write(buf, binary.BigEndian, int16(27898))
-		} else if adaptor.written[len(adaptor.written)-2] == bmp180CmdPressure && adaptor.written[len(adaptor.written)-1] == bmp180RegisterPressureMSB {
+		if len(adaptor.written) > 1 && adaptor.written[len(adaptor.written)-2] == bmp180CmdPressure {
 			return 0, errors.New("press error")
 		}
-		copy(b, buf.Bytes())
-		return buf.Len(), nil
+		return readImpl(b)
 	}
 	bmp180.Start()
 	_, err := bmp180.Pressure()
@@ -175,6 +873,521 @@ func TestBMP180DriverPressureWriteError(t *testing.T) {
 	gobottest.Assert(t, err, errors.New("write error"))
 }
 
+func TestBMP180DriverTemperatureReadInterval(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	bmp180.SetTemperatureReadInterval(3)
+
+	adaptor.written = []byte{}
+	for i := 0; i < 5; i++ {
+		pressure, err := bmp180.Pressure()
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, pressure, float32(69964))
+	}
+	gobottest.Assert(t, bytes.Count(adaptor.written, []byte{bmp180RegisterCtl, bmp180CmdTemp}), 2)
+	gobottest.Assert(t, bytes.Count(adaptor.written, []byte{bmp180RegisterCtl, bmp180CmdPressure}), 5)
+}
+
+func TestBMP180DriverRawOversampling(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	readImpl := bmp180TestReadImpl(adaptor)
+	raw := []uint16{23841, 23843, 23848}
+	reads := 0
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		if len(adaptor.written) > 1 && adaptor.written[len(adaptor.written)-2] == bmp180CmdPressure {
+			binary.BigEndian.PutUint16(b, raw[reads%len(raw)])
+			b[2] = 0
+			reads++
+			return 3, nil
+		}
+		return readImpl(b)
+	}
+	bmp180.Start()
+	bmp180.SetRawOversampling(3)
+
+	adaptor.written = []byte{}
+	pressure, err := bmp180.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, reads, 3)
+	gobottest.Assert(t, bytes.Count(adaptor.written, []byte{bmp180RegisterCtl, bmp180CmdTemp}), 1)
+	gobottest.Assert(t, bytes.Count(adaptor.written, []byte{bmp180RegisterCtl, bmp180CmdPressure}), 3)
+	// the average of the raw pressures, 23844, is compensated once.
+	want, _ := bmp180.calculatePressure(27898, 23844, BMP180UltraLowPower)
+	gobottest.Assert(t, pressure, want)
+
+	r, err := bmp180.Reading()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, reads, 6)
+	gobottest.Assert(t, r.Pressure, want)
+
+	// a single raw pressure.
+	bmp180.SetRawOversampling(0)
+	pressure, _ = bmp180.Pressure()
+	gobottest.Assert(t, reads, 7)
+	want, _ = bmp180.calculatePressure(27898, 23841, BMP180UltraLowPower)
+	gobottest.Assert(t, pressure, want)
+}
+
+func TestBMP180DriverTemperatureMaxAge(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	clock := time.Date(2017, 4, 1, 12, 30, 0, 0, time.UTC)
+	bmp180.now = func() time.Time { return clock }
+	bmp180.SetTemperatureReadInterval(100)
+	bmp180.SetTemperatureMaxAge(time.Hour)
+	tempCmd := []byte{bmp180RegisterCtl, bmp180CmdTemp}
+
+	adaptor.written = []byte{}
+	bmp180.Pressure()
+	clock = clock.Add(time.Hour)
+	bmp180.Pressure()
+	gobottest.Assert(t, bytes.Count(adaptor.written, tempCmd), 1)
+
+	// the temperature is older than the maximum age.
+	clock = clock.Add(time.Second)
+	adaptor.written = []byte{}
+	pressure, err := bmp180.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+	gobottest.Assert(t, bytes.Count(adaptor.written, tempCmd), 1)
+}
+
+func TestBMP180DriverAltitude(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	_, err := bmp180.Altitude()
+	gobottest.Assert(t, err, ErrBMP180NoPressure)
+
+	bmp180.Pressure()
+	// the altitude is of the last pressure, without a measurement.
+	written := len(adaptor.written)
+	alt, err := bmp180.Altitude()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt, float32(3016.6592))
+	gobottest.Assert(t, len(adaptor.written), written)
+
+	gobottest.Assert(t, bmp180.SetSeaLevelPressure(69964), nil)
+	alt, err = bmp180.Altitude()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt, float32(0))
+
+	gobottest.Assert(t, bmp180.SetSeaLevelPressure(0), ErrInvalidSeaLevelPressure)
+	gobottest.Assert(t, bmp180.SetSeaLevelPressure(-1), ErrInvalidSeaLevelPressure)
+	gobottest.Assert(t, bmp180.seaLevelPressure, float32(69964))
+}
+
+func TestBMP180DriverPressureUnit(t *testing.T) {
+	var tests = map[string]struct {
+		unit     BMP180PressureUnit
+		pressure float32
+	}{
+		"pascal":          {unit: BMP180Pascal, pressure: 69964},
+		"hectopascal":     {unit: BMP180Hectopascal, pressure: 699.64},
+		"millibar":        {unit: BMP180Millibar, pressure: 699.64},
+		"inch of mercury": {unit: BMP180InchOfMercury, pressure: 20.660355},
+		"atmosphere":      {unit: BMP180Atmosphere, pressure: 0.690491},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+			adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+			bmp180.Start()
+			gobottest.Assert(t, bmp180.SetPressureUnit(tc.unit), nil)
+			pressure, err := bmp180.Pressure()
+			gobottest.Assert(t, err, nil)
+			gobottest.Assert(t, pressure, tc.pressure)
+			r, err := bmp180.Reading()
+			gobottest.Assert(t, err, nil)
+			gobottest.Assert(t, r.Pressure, tc.pressure)
+			// the altitude is still computed from the pressure in pascals
+			gobottest.Assert(t, r.Altitude, float32(3016.6592))
+		})
+	}
+}
+
+func TestBMP180DriverInvalidPressureUnit(t *testing.T) {
+	bmp180 := initTestBMP180Driver()
+	gobottest.Assert(t, bmp180.SetPressureUnit(BMP180Atmosphere+1), ErrInvalidPressureUnit)
+	gobottest.Assert(t, bmp180.pressureUnit, BMP180Pascal)
+}
+
+func TestBMP180OversamplingModeString(t *testing.T) {
+	gobottest.Assert(t, BMP180UltraLowPower.String(), "UltraLowPower")
+	gobottest.Assert(t, BMP180Standard.String(), "Standard")
+	gobottest.Assert(t, BMP180HighResolution.String(), "HighResolution")
+	gobottest.Assert(t, BMP180UltraHighResolution.String(), "UltraHighResolution")
+	gobottest.Assert(t, BMP180OversamplingMode(4).String(), "BMP180OversamplingMode(4)")
+}
+
+func TestBMP180DriverString(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.SetName("bmp")
+	bmp180.Start()
+	gobottest.Assert(t, bmp180.String(), "BMP180(name=bmp, mode=UltraLowPower)")
+
+	bmp180.Temperature()
+	gobottest.Assert(t, bmp180.String(), "BMP180(name=bmp, mode=UltraLowPower, temp=15.0C)")
+	bmp180.Pressure()
+	gobottest.Assert(t, fmt.Sprint(bmp180), "BMP180(name=bmp, mode=UltraLowPower, temp=15.0C, pressure=69964Pa)")
+
+	bmp180.SetPressureCalibration(1, 100)
+	bmp180.SetTemperatureCalibration(1, 0.5)
+	bmp180.Reading()
+	gobottest.Assert(t, bmp180.String(), "BMP180(name=bmp, mode=UltraLowPower, temp=15.5C, pressure=70064Pa)")
+	bmp180.SetMode(BMP180Standard)
+	gobottest.Assert(t, bmp180.String(), "BMP180(name=bmp, mode=Standard, temp=15.5C, pressure=70064Pa)")
+}
+
+func TestBMP180DriverAltitudeFeet(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	bmp180.Pressure()
+	alt, err := bmp180.AltitudeFeet()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt, float32(3016.6592)*3.28084)
+
+	bmp180.SetSeaLevelPressure(69964)
+	alt, err = bmp180.AltitudeFeet()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt, float32(0))
+
+	bmp180, adaptor = initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	_, err = bmp180.AltitudeFeet()
+	gobottest.Assert(t, err, ErrBMP180NoPressure)
+}
+
+func TestBMP180DriverSeaLevelPressure(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	p, err := bmp180.SeaLevelPressure(0)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, p, float32(69964))
+
+	// 69964 Pa at 3016.6592 m is the standard atmosphere.
+	p, err = bmp180.SeaLevelPressure(3016.6592)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, math.Abs(float64(p)-101325) < 1, true)
+
+	// no pressure at or above 44330 m.
+	_, err = bmp180.SeaLevelPressure(44330)
+	gobottest.Assert(t, err, ErrInvalidAltitude)
+	_, err = bmp180.SeaLevelPressure(50000)
+	gobottest.Assert(t, err, ErrInvalidAltitude)
+	_, err = bmp180.SeaLevelPressure(float32(math.NaN()))
+	gobottest.Assert(t, err, ErrInvalidAltitude)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = bmp180.SeaLevelPressure(0)
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP180DriverAltitudeError(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	bmp180.Start()
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+
+	bmp180.Pressure()
+	_, err := bmp180.Altitude()
+	gobottest.Assert(t, err, ErrBMP180NoPressure)
+}
+
+func TestBMP180DriverInfo(t *testing.T) {
+	bmp180 := NewBMP180Driver(newI2cTestAdaptor(), WithBus(2))
+	gobottest.Assert(t, bmp180.Info(), DriverInfo{
+		Type: "BMP180",
+		Quantities: []DriverQuantity{
+			{Name: "temperature", Unit: "°C"},
+			{Name: "pressure", Unit: "Pa"},
+			{Name: "altitude", Unit: "m"},
+		},
+		Bus:     2,
+		Address: 0x77,
+	})
+
+	bmp180 = NewBMP180Driver(newI2cTestAdaptor(), WithAddress(0x76))
+	bmp180.SetPressureUnit(BMP180Hectopascal)
+	info := bmp180.Info()
+	gobottest.Assert(t, info.Quantities[1], DriverQuantity{Name: "pressure", Unit: "hPa"})
+	gobottest.Assert(t, info.Address, 0x76)
+	gobottest.Assert(t, info.Bus, 0)
+}
+
+func TestBMP180DriverDumpRegisters(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+
+	adaptor.written = []byte{}
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		for i := range b {
+			b[i] = adaptor.written[len(adaptor.written)-1] + byte(i)
+		}
+		return len(b), nil
+	}
+	dump, err := bmp180.DumpRegisters()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(dump), 3)
+	gobottest.Assert(t, len(dump[0xAA]), 22)
+	gobottest.Assert(t, dump[0xAA][21], uint8(0xBF))
+	gobottest.Assert(t, dump[0xD0], []byte{0xD0, 0xD1})
+	gobottest.Assert(t, dump[0xF4], []byte{0xF4})
+	// nothing is written but the addresses.
+	gobottest.Assert(t, adaptor.written, []byte{0xAA, 0xD0, 0xF4})
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = bmp180.DumpRegisters()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP180DriverSelfTest(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	gobottest.Assert(t, bmp180.SelfTest(), nil)
+	// the state of the driver is unchanged.
+	gobottest.Assert(t, bmp180.SampleCount(), uint64(0))
+	gobottest.Assert(t, bmp180.hasLastTemp, false)
+}
+
+func TestBMP180DriverSelfTestFaults(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	readImpl := bmp180TestReadImpl(adaptor)
+	adaptor.i2cReadImpl = readImpl
+	bmp180.Start()
+
+	// the chip id.
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	gobottest.Assert(t, bmp180.SelfTest(), errors.New("BMP180 self-test failed (chip id: read error)"))
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		b[0] = 0x58
+		return 1, nil
+	}
+	gobottest.Assert(t, bmp180.SelfTest(), errors.New("BMP180 self-test failed (chip id 0x58)"))
+
+	// the calibration.
+	adaptor.i2cReadImpl = readImpl
+	coefficients := bmp180.CalibrationCoefficients()
+	invalid := coefficients
+	invalid.MC = -1
+	bmp180.SetCalibrationCoefficients(invalid)
+	gobottest.Assert(t, bmp180.SelfTest(), errors.New("BMP180 self-test failed (calibration: Invalid calibration data)"))
+	bmp180.SetCalibrationCoefficients(coefficients)
+
+	// the temperature.
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterTempMSB {
+			return 0, errors.New("read error")
+		}
+		return readImpl(b)
+	}
+	gobottest.Assert(t, bmp180.SelfTest(), errors.New("BMP180 self-test failed (temperature: read error)"))
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		if adaptor.written[len(adaptor.written)-1] == bmp180RegisterTempMSB {
+			binary.BigEndian.PutUint16(b, 40000)
+			return 2, nil
+		}
+		return readImpl(b)
+	}
+	temp := bmp180.calculateTemp(40000)
+	gobottest.Assert(t, temp > 85, true)
+	gobottest.Assert(t, bmp180.SelfTest(), fmt.Errorf("BMP180 self-test failed (temperature %v: Temperature out of range)", temp))
+}
+
+func TestBMP180DriverDeviceInfo(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+
+	adaptor.written = []byte{}
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{bmp180ChipID, 0x21})
+		return 2, nil
+	}
+	info, err := bmp180.DeviceInfo()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, info, BMP180DeviceInfo{ChipID: 0x55, Version: 0x21, MLVersion: 0x01, ALVersion: 0x02})
+	// both registers are read at once.
+	gobottest.Assert(t, adaptor.written, []byte{bmp180RegisterChipID})
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = bmp180.DeviceInfo()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP180DriverCalibrationCoefficients(t *testing.T) {
+	datasheet := BMP180CalibrationCoefficients{
+		AC1: 408, AC2: -72, AC3: -14383, AC4: 32741, AC5: 32757, AC6: 23153,
+		B1: 6190, B2: 4, MB: -32768, MC: -8711, MD: 2868,
+	}
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	c := bmp180.CalibrationCoefficients()
+	gobottest.Assert(t, c, datasheet)
+	// the copy does not change the driver.
+	c.AC1 = 0
+	gobottest.Assert(t, bmp180.CalibrationCoefficients().AC1, int16(408))
+
+	bmp180 = initTestBMP180Driver()
+	bmp180.SetCalibrationCoefficients(datasheet)
+	gobottest.Assert(t, bmp180.CalibrationCoefficients(), datasheet)
+	gobottest.Assert(t, bmp180.calculateTemp(27898), float32(15.0))
+	pressure, err := bmp180.calculatePressure(27898, 23843, BMP180UltraLowPower)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+}
+
+// bmp180TestCache is an in-memory BMP180CalibrationCache.
+type bmp180TestCache map[int]BMP180CalibrationCoefficients
+
+func (c bmp180TestCache) Load(address int) (BMP180CalibrationCoefficients, bool) {
+	coefficients, ok := c[address]
+	return coefficients, ok
+}
+
+func (c bmp180TestCache) Store(address int, coefficients BMP180CalibrationCoefficients) {
+	c[address] = coefficients
+}
+
+func TestBMP180DriverSetCalibrationByteOrder(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	big := bmp180.CalibrationCoefficients()
+	gobottest.Assert(t, big.AC1, int16(408))
+	gobottest.Assert(t, big.AC4, uint16(32741))
+
+	// the same bytes, swapped.
+	bmp180.SetCalibrationByteOrder(binary.LittleEndian)
+	bmp180.Start()
+	little := bmp180.CalibrationCoefficients()
+	gobottest.Assert(t, little.AC1, int16(-26623))
+	gobottest.Assert(t, little.AC4, uint16(58751))
+	gobottest.Assert(t, little.MB, int16(0x0080))
+
+	bmp180.SetCalibrationByteOrder(nil)
+	bmp180.Start()
+	gobottest.Assert(t, bmp180.CalibrationCoefficients(), big)
+}
+
+func TestBMP180DriverCalibrationCache(t *testing.T) {
+	cache := bmp180TestCache{}
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.SetCalibrationCache(cache)
+	gobottest.Assert(t, bmp180.Start(), nil)
+	gobottest.Assert(t, cache[bmp180Address], bmp180.CalibrationCoefficients())
+
+	// the second device at the same address only reads its chip id.
+	bmp180, adaptor = initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.SetCalibrationCache(cache)
+	gobottest.Assert(t, bmp180.Start(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{bmp180RegisterChipID})
+	gobottest.Assert(t, bmp180.CalibrationCoefficients(), cache[bmp180Address])
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+
+	// another address is not in the cache.
+	bmp180, adaptor = initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	WithAddress(0x76)(bmp180)
+	bmp180.SetCalibrationCache(cache)
+	gobottest.Assert(t, bmp180.Start(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{bmp180RegisterChipID, bmp180RegisterAC1MSB})
+	gobottest.Assert(t, len(cache), 2)
+	// an invalid entry is read again from the device, and replaced.
+	invalid := cache[bmp180Address]
+	invalid.MC = -1
+	cache[bmp180Address] = invalid
+	bmp180, adaptor = initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.SetCalibrationCache(cache)
+	gobottest.Assert(t, bmp180.Start(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{bmp180RegisterChipID, bmp180RegisterAC1MSB})
+	gobottest.Assert(t, bmp180.CalibrationCoefficients().MC, int16(-8711))
+	gobottest.Assert(t, cache[bmp180Address].MC, int16(-8711))
+}
+
+func TestBMP180DriverOperationTimeout(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	bmp180.SetOperationTimeout(10 * time.Millisecond)
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+
+	// the device stretches the clock until released.
+	release := make(chan struct{})
+	readImpl := adaptor.i2cReadImpl
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		<-release
+		return readImpl(b)
+	}
+	start := time.Now()
+	_, err = bmp180.Temperature()
+	gobottest.Assert(t, err, ErrBMP180OperationTimeout)
+	gobottest.Assert(t, time.Since(start) >= 10*time.Millisecond, true)
+
+	// the bus is not used again until the abandoned read completes.
+	written := len(adaptor.written)
+	_, err = bmp180.Temperature()
+	gobottest.Assert(t, err, ErrBMP180OperationTimeout)
+	gobottest.Assert(t, len(adaptor.written), written)
+
+	close(release)
+	bmp180.mutex.Lock()
+	<-bmp180.abandoned
+	bmp180.mutex.Unlock()
+	temp, err = bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+}
+
+func TestBMP180DriverSoftReset(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	bmp180.calibrationCoefficients.AC1 = 0
+
+	adaptor.written = []byte{}
+	gobottest.Assert(t, bmp180.SoftReset(), nil)
+	gobottest.Assert(t, adaptor.written[:2], []byte{bmp180RegisterSoftReset, bmp180CmdSoftReset})
+	gobottest.Assert(t, bmp180.calibrationCoefficients.AC1, int16(408))
+}
+
+func TestBMP180DriverSoftResetError(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, bmp180.SoftReset(), errors.New("write error"))
+}
+
 func TestBMP180DriverSetName(t *testing.T) {
 	b := initTestBMP180Driver()
 	b.SetName("TESTME")
@@ -186,6 +1399,28 @@ func TestBMP180DriverOptions(t *testing.T) {
 	gobottest.Assert(t, b.GetBusOrDefault(1), 2)
 }
 
+func TestBMP180DriverAddress(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180.Start()
+	gobottest.Assert(t, adaptor.address, bmp180Address)
+
+	adaptor = newI2cTestAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180 = NewBMP180Driver(adaptor, WithAddress(0x76))
+	gobottest.Assert(t, bmp180.Start(), nil)
+	gobottest.Assert(t, adaptor.address, 0x76)
+}
+
+func TestBMP180TempToB5(t *testing.T) {
+	c := &BMP180CalibrationCoefficients{AC5: 24875, AC6: 23153, MC: -8711, MD: 2868}
+	for rawTemp := uint16(20000); rawTemp < 40000; rawTemp += 97 {
+		temp := bmp180CalculateTemp(c, rawTemp)
+		b5 := bmp180TempToB5(temp)
+		gobottest.Assert(t, (b5+8)>>4, (bmp180CalculateB5(c, rawTemp)+8)>>4)
+	}
+}
+
 func TestBMP180PauseForReading(t *testing.T) {
 	gobottest.Assert(t, pauseForReading(BMP180UltraLowPower), time.Duration(5*time.Millisecond))
 	gobottest.Assert(t, pauseForReading(BMP180Standard), time.Duration(8*time.Millisecond))
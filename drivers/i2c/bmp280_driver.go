@@ -138,6 +138,12 @@ func (d *BMP280Driver) Altitude() (alt float32, err error) {
 	return
 }
 
+// SupportsHumidity returns false, the BMP280 has no humidity sensor, see the
+// BME280Driver.
+func (d *BMP280Driver) SupportsHumidity() bool {
+	return false
+}
+
 // initialization reads the calibration coefficients.
 func (d *BMP280Driver) initialization() (err error) {
 	var coefficients []byte
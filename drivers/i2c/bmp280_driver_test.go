@@ -71,6 +71,12 @@ func TestBMP280DriverHalt(t *testing.T) {
 	gobottest.Assert(t, bmp280.Halt(), nil)
 }
 
+func TestBMP280DriverSupportsHumidity(t *testing.T) {
+	bmp280 := initTestBMP280Driver()
+
+	gobottest.Assert(t, bmp280.SupportsHumidity(), false)
+}
+
 func TestBMP280DriverMeasurements(t *testing.T) {
 	bmp280, adaptor := initTestBMP280DriverWithStubbedAdaptor()
 	adaptor.i2cReadImpl = func(b []byte) (int, error) {
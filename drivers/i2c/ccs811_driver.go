@@ -0,0 +1,277 @@
+package i2c
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// CCS811AddressA is the default address of the CCS811, with ADDR low.
+const CCS811AddressA = 0x5A
+
+// CCS811AddressB is the address of the CCS811 with ADDR high.
+const CCS811AddressB = 0x5B
+
+const ccs811RegisterStatus = 0x00
+const ccs811RegisterMeasMode = 0x01
+const ccs811RegisterAlgResultData = 0x02
+const ccs811RegisterEnvData = 0x05
+const ccs811RegisterHWID = 0x20
+const ccs811RegisterErrorID = 0xE0
+const ccs811RegisterAppStart = 0xF4
+
+const ccs811HWID = 0x81
+
+const ccs811StatusError = 0x01
+const ccs811StatusDataReady = 0x08
+const ccs811StatusAppValid = 0x10
+const ccs811StatusFWMode = 0x80
+
+const (
+	// CCS811DriveModeIdle stops the measurements.
+	CCS811DriveModeIdle CCS811DriveMode = iota
+	// CCS811DriveMode1Sec measures every second.
+	CCS811DriveMode1Sec
+	// CCS811DriveMode10Sec measures every 10 seconds.
+	CCS811DriveMode10Sec
+	// CCS811DriveMode60Sec measures every 60 seconds.
+	CCS811DriveMode60Sec
+	// CCS811DriveMode250MS measures every 250 milliseconds, with raw data only.
+	CCS811DriveMode250MS
+)
+
+// ErrCCS811InvalidApp is returned when the CCS811 has no valid application
+// firmware to start.
+var ErrCCS811InvalidApp = errors.New("CCS811 has no valid application")
+
+// CCS811DriveMode is the measurement period of the CCS811.
+type CCS811DriveMode uint8
+
+// CCS811Driver is the gobot driver for the AMS air quality sensor CCS811,
+// which measures the equivalent CO2 (eCO2) and the total volatile organic
+// compounds (TVOC).
+// Device datasheet: https://ams.com/documents/20143/36005/CCS811_DS000459_7-00.pdf
+type CCS811Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	driveMode CCS811DriveMode
+	mutex     *sync.Mutex
+}
+
+// NewCCS811Driver creates a new driver with the i2c interface for the CCS811 device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithCCS811DriveMode(CCS811DriveMode):	measurement period, defaults to CCS811DriveMode1Sec
+//
+func NewCCS811Driver(c Connector, options ...func(Config)) *CCS811Driver {
+	d := &CCS811Driver{
+		name:      gobot.DefaultName("CCS811"),
+		connector: c,
+		Config:    NewConfig(),
+		driveMode: CCS811DriveMode1Sec,
+		mutex:     &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	// TODO: expose commands to API
+	return d
+}
+
+// WithCCS811DriveMode option sets the measurement period of the CCS811.
+func WithCCS811DriveMode(val CCS811DriveMode) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*CCS811Driver)
+		if ok && val <= CCS811DriveMode250MS {
+			d.driveMode = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *CCS811Driver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *CCS811Driver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *CCS811Driver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start initializes the CCS811, starts its application and its measurements.
+func (d *CCS811Driver) Start() (err error) {
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(CCS811AddressA)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *CCS811Driver) initialization() (err error) {
+	var id []byte
+	if id, err = d.read(ccs811RegisterHWID, 1); err != nil {
+		return err
+	}
+	if id[0] != ccs811HWID {
+		return fmt.Errorf("CCS811 device not found (HW_ID 0x%02X)", id[0])
+	}
+
+	var status byte
+	if status, err = d.status(); err != nil {
+		return err
+	}
+	if status&ccs811StatusAppValid == 0 {
+		return ErrCCS811InvalidApp
+	}
+	// the sensor boots in firmware mode, measurements need the application.
+	if status&ccs811StatusFWMode == 0 {
+		if _, err = d.connection.Write([]byte{ccs811RegisterAppStart}); err != nil {
+			return err
+		}
+		time.Sleep(1 * time.Millisecond)
+		if status, err = d.status(); err != nil {
+			return err
+		}
+		if status&ccs811StatusFWMode == 0 {
+			return ErrCCS811InvalidApp
+		}
+	}
+
+	return d.SetDriveMode(d.driveMode)
+}
+
+// Halt halts the device.
+func (d *CCS811Driver) Halt() (err error) {
+	return nil
+}
+
+// SetDriveMode sets the measurement period of the CCS811.
+func (d *CCS811Driver) SetDriveMode(mode CCS811DriveMode) (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, err = d.connection.Write([]byte{ccs811RegisterMeasMode, byte(mode) << 4}); err != nil {
+		return err
+	}
+	d.driveMode = mode
+	return nil
+}
+
+// SetEnvironmentalData sets the temperature, in celsius degrees, and the
+// relative humidity, in percent, the CCS811 uses to compensate its
+// measurements, e.g. from a BMP180 and a SHT3x. Without it, the sensor
+// assumes 25 celsius degrees and 50 percent.
+func (d *CCS811Driver) SetEnvironmentalData(temp, humidity float32) (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if humidity < 0 {
+		humidity = 0
+	}
+	if temp < -25 {
+		temp = -25
+	}
+	// both in 1/512th, the temperature with an offset of 25 degrees.
+	h := uint16(humidity * 512)
+	t := uint16((temp + 25) * 512)
+	_, err = d.connection.Write([]byte{ccs811RegisterEnvData, byte(h >> 8), byte(h), byte(t >> 8), byte(t)})
+	return err
+}
+
+// HasData returns true when a new measurement is available.
+func (d *CCS811Driver) HasData() (ready bool, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var status byte
+	if status, err = d.status(); err != nil {
+		return false, err
+	}
+	return status&ccs811StatusDataReady != 0, nil
+}
+
+// GasData returns the last measured equivalent CO2, in ppm, and total
+// volatile organic compounds, in ppb.
+func (d *CCS811Driver) GasData() (eco2 uint16, tvoc uint16, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	if data, err = d.read(ccs811RegisterAlgResultData, 5); err != nil {
+		return 0, 0, err
+	}
+	if data[4]&ccs811StatusError != 0 {
+		return 0, 0, d.deviceError()
+	}
+	eco2 = uint16(data[0])<<8 | uint16(data[1])
+	tvoc = uint16(data[2])<<8 | uint16(data[3])
+	return eco2, tvoc, nil
+}
+
+// ECO2 returns the last measured equivalent CO2, in ppm.
+func (d *CCS811Driver) ECO2() (eco2 uint16, err error) {
+	eco2, _, err = d.GasData()
+	return
+}
+
+// TVOC returns the last measured total volatile organic compounds, in ppb.
+func (d *CCS811Driver) TVOC() (tvoc uint16, err error) {
+	_, tvoc, err = d.GasData()
+	return
+}
+
+func (d *CCS811Driver) status() (byte, error) {
+	status, err := d.read(ccs811RegisterStatus, 1)
+	if err != nil {
+		return 0, err
+	}
+	if status[0]&ccs811StatusError != 0 {
+		return 0, d.deviceError()
+	}
+	return status[0], nil
+}
+
+// deviceError returns the error flagged by the CCS811 in its status.
+func (d *CCS811Driver) deviceError() error {
+	id, err := d.read(ccs811RegisterErrorID, 1)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("CCS811 error (ERROR_ID 0x%02X)", id[0])
+}
+
+func (d *CCS811Driver) read(address byte, n int) ([]byte, error) {
+	if _, err := d.connection.Write([]byte{address}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if bytesRead != n {
+		return nil, ErrNotEnoughBytes
+	}
+	return buf, nil
+}
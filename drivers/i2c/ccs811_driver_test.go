@@ -0,0 +1,219 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+// the CCS811Driver is a Driver
+var _ gobot.Driver = (*CCS811Driver)(nil)
+
+// --------- HELPERS
+func initTestCCS811Driver() (driver *CCS811Driver) {
+	driver, _ = initTestCCS811DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestCCS811DriverWithStubbedAdaptor() (*CCS811Driver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewCCS811Driver(adaptor), adaptor
+}
+
+// ccs811TestReadImpl answers the register read last requested, with the
+// status returned by status() and the given algorithm results.
+func ccs811TestReadImpl(adaptor *i2cTestAdaptor, status func() byte, data []byte) func([]byte) (int, error) {
+	return func(b []byte) (int, error) {
+		switch adaptor.written[len(adaptor.written)-1] {
+		case ccs811RegisterHWID:
+			b[0] = ccs811HWID
+		case ccs811RegisterStatus:
+			b[0] = status()
+		case ccs811RegisterAlgResultData:
+			copy(b, data)
+		case ccs811RegisterErrorID:
+			b[0] = 0x02
+		}
+		return len(b), nil
+	}
+}
+
+// ccs811AppStatus is the status of a CCS811 running its application.
+func ccs811AppStatus() byte {
+	return ccs811StatusAppValid | ccs811StatusFWMode
+}
+
+// --------- TESTS
+
+func TestNewCCS811Driver(t *testing.T) {
+	// Does it return a pointer to an instance of CCS811Driver?
+	var ccs811 interface{} = NewCCS811Driver(newI2cTestAdaptor())
+	_, ok := ccs811.(*CCS811Driver)
+	if !ok {
+		t.Errorf("NewCCS811Driver() should have returned a *CCS811Driver")
+	}
+}
+
+func TestCCS811DriverOptions(t *testing.T) {
+	d := NewCCS811Driver(newI2cTestAdaptor(), WithAddress(CCS811AddressB), WithCCS811DriveMode(CCS811DriveMode60Sec))
+	gobottest.Assert(t, d.GetAddressOrDefault(CCS811AddressA), CCS811AddressB)
+	gobottest.Assert(t, d.driveMode, CCS811DriveMode60Sec)
+
+	d = NewCCS811Driver(newI2cTestAdaptor(), WithCCS811DriveMode(5))
+	gobottest.Assert(t, d.driveMode, CCS811DriveMode1Sec)
+}
+
+func TestCCS811DriverStart(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	started := false
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, func() byte {
+		if started {
+			return ccs811AppStatus()
+		}
+		return ccs811StatusAppValid
+	}, nil)
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		if len(b) == 1 && b[0] == ccs811RegisterAppStart {
+			started = true
+		}
+		return len(b), nil
+	}
+	gobottest.Assert(t, ccs811.Start(), nil)
+	gobottest.Assert(t, adaptor.address, CCS811AddressA)
+	// HW_ID, status, APP_START, status, then the drive mode.
+	gobottest.Assert(t, adaptor.written, []byte{0x20, 0x00, 0xF4, 0x00, 0x01, 0x10})
+}
+
+func TestCCS811DriverStartAppRunning(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, ccs811AppStatus, nil)
+	gobottest.Assert(t, ccs811.Start(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x20, 0x00, 0x01, 0x10})
+}
+
+func TestCCS811DriverStartErrors(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, ccs811.Start(), errors.New("Invalid i2c connection"))
+
+	ccs811, adaptor = initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		b[0] = 0x55
+		return 1, nil
+	}
+	gobottest.Assert(t, ccs811.Start(), errors.New("CCS811 device not found (HW_ID 0x55)"))
+
+	ccs811, adaptor = initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, func() byte { return 0x00 }, nil)
+	gobottest.Assert(t, ccs811.Start(), ErrCCS811InvalidApp)
+
+	// the application does not start.
+	ccs811, adaptor = initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, func() byte { return ccs811StatusAppValid }, nil)
+	gobottest.Assert(t, ccs811.Start(), ErrCCS811InvalidApp)
+
+	ccs811, adaptor = initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, func() byte { return ccs811StatusError }, nil)
+	gobottest.Assert(t, ccs811.Start(), errors.New("CCS811 error (ERROR_ID 0x02)"))
+
+	ccs811, _ = initTestCCS811DriverWithStubbedAdaptor()
+	gobottest.Assert(t, ccs811.Start(), ErrNotEnoughBytes)
+}
+
+func TestCCS811DriverHalt(t *testing.T) {
+	ccs811 := initTestCCS811Driver()
+	gobottest.Assert(t, ccs811.Halt(), nil)
+}
+
+func TestCCS811DriverSetName(t *testing.T) {
+	ccs811 := initTestCCS811Driver()
+	ccs811.SetName("TESTME")
+	gobottest.Assert(t, ccs811.Name(), "TESTME")
+}
+
+func TestCCS811DriverSetDriveMode(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, ccs811AppStatus, nil)
+	ccs811.Start()
+	adaptor.written = []byte{}
+	gobottest.Assert(t, ccs811.SetDriveMode(CCS811DriveMode250MS), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x01, 0x40})
+	gobottest.Assert(t, ccs811.driveMode, CCS811DriveMode250MS)
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, ccs811.SetDriveMode(CCS811DriveModeIdle), errors.New("write error"))
+	gobottest.Assert(t, ccs811.driveMode, CCS811DriveMode250MS)
+}
+
+func TestCCS811DriverSetEnvironmentalData(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, ccs811AppStatus, nil)
+	ccs811.Start()
+	adaptor.written = []byte{}
+	// datasheet examples: 48.5% and 25 C.
+	gobottest.Assert(t, ccs811.SetEnvironmentalData(25, 48.5), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x05, 0x61, 0x00, 0x64, 0x00})
+
+	adaptor.written = []byte{}
+	gobottest.Assert(t, ccs811.SetEnvironmentalData(-40, -1), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x05, 0x00, 0x00, 0x00, 0x00})
+}
+
+func TestCCS811DriverHasData(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	status := ccs811AppStatus()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, func() byte { return status }, nil)
+	ccs811.Start()
+
+	ready, err := ccs811.HasData()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, ready, false)
+
+	status |= ccs811StatusDataReady
+	ready, err = ccs811.HasData()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, ready, true)
+
+	status |= ccs811StatusError
+	_, err = ccs811.HasData()
+	gobottest.Assert(t, err, errors.New("CCS811 error (ERROR_ID 0x02)"))
+}
+
+func TestCCS811DriverGasData(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	// 1000 ppm eCO2 and 150 ppb TVOC.
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, ccs811AppStatus, []byte{0x03, 0xE8, 0x00, 0x96, 0x98})
+	ccs811.Start()
+
+	eco2, tvoc, err := ccs811.GasData()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, eco2, uint16(1000))
+	gobottest.Assert(t, tvoc, uint16(150))
+
+	eco2, err = ccs811.ECO2()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, eco2, uint16(1000))
+	tvoc, err = ccs811.TVOC()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, tvoc, uint16(150))
+}
+
+func TestCCS811DriverGasDataError(t *testing.T) {
+	ccs811, adaptor := initTestCCS811DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = ccs811TestReadImpl(adaptor, ccs811AppStatus, []byte{0x03, 0xE8, 0x00, 0x96, 0x99})
+	ccs811.Start()
+	_, _, err := ccs811.GasData()
+	gobottest.Assert(t, err, errors.New("CCS811 error (ERROR_ID 0x02)"))
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = ccs811.ECO2()
+	gobottest.Assert(t, err, errors.New("read error"))
+	_, err = ccs811.TVOC()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
@@ -0,0 +1,25 @@
+package i2c
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInvalidHumidity is returned by DewPoint for a relative humidity that is
+// not positive, the dew point being only defined when there is water vapor.
+var ErrInvalidHumidity = errors.New("Invalid relative humidity")
+
+// DewPoint returns the dew point, in celsius degrees, for the given
+// temperature in celsius degrees and relative humidity in percent, using the
+// Magnus-Tetens approximation. The temperature can come from any source,
+// e.g. a BMP180 paired with a separate humidity sensor. It returns
+// ErrInvalidHumidity for a relative humidity that is not positive.
+func DewPoint(tempC, relativeHumidity float32) (float32, error) {
+	// also false for NaN
+	if !(relativeHumidity > 0) {
+		return 0, ErrInvalidHumidity
+	}
+	const a, b = 17.62, 243.12
+	gamma := math.Log(float64(relativeHumidity)/100) + a*float64(tempC)/(b+float64(tempC))
+	return float32(b * gamma / (a - gamma)), nil
+}
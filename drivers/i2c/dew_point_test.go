@@ -0,0 +1,33 @@
+package i2c
+
+import (
+	"math"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestDewPoint(t *testing.T) {
+	tests := []struct {
+		temp, humidity, dewPoint float32
+	}{
+		{temp: 20, humidity: 100, dewPoint: 20},
+		{temp: 25, humidity: 50, dewPoint: 13.9},
+		{temp: 30, humidity: 70, dewPoint: 23.9},
+		{temp: 10, humidity: 40, dewPoint: -3.0},
+		{temp: 0, humidity: 80, dewPoint: -3.0},
+	}
+	for _, tt := range tests {
+		d, err := DewPoint(tt.temp, tt.humidity)
+		if err != nil || math.Abs(float64(d-tt.dewPoint)) > 0.1 {
+			t.Errorf("DewPoint(%v, %v) = %v, %v, want %v", tt.temp, tt.humidity, d, err, tt.dewPoint)
+		}
+	}
+}
+
+func TestDewPointInvalidHumidity(t *testing.T) {
+	for _, humidity := range []float32{0, -10, float32(math.NaN())} {
+		_, err := DewPoint(20, humidity)
+		gobottest.Assert(t, err, ErrInvalidHumidity)
+	}
+}
@@ -0,0 +1,19 @@
+package i2c
+
+// DriverQuantity is a quantity measured by a driver, and its unit.
+type DriverQuantity struct {
+	Name string
+	Unit string
+}
+
+// DriverInfo describes a driver and the device it is configured for, so that
+// generic tooling, e.g. a management UI listing the devices of a robot, can
+// present its capabilities without knowing each driver.
+type DriverInfo struct {
+	// Type is the device driven, e.g. "BMP180".
+	Type string
+	// Quantities are the quantities the driver measures, with their units.
+	Quantities []DriverQuantity
+	Bus        int
+	Address    int
+}
@@ -0,0 +1,277 @@
+package i2c
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+const hdc1080Address = 0x40
+
+const hdc1080RegisterTemperature = 0x00
+const hdc1080RegisterConfig = 0x02
+const hdc1080RegisterManufacturerID = 0xFE
+const hdc1080RegisterDeviceID = 0xFF
+
+const hdc1080ManufacturerID = 0x5449
+const hdc1080DeviceID = 0x1050
+
+// the bits of the high byte of the configuration register, its low byte
+// being reserved.
+const hdc1080ConfigReset = 0x80
+const hdc1080ConfigHeater = 0x20
+const hdc1080ConfigModeSequence = 0x10
+const hdc1080ConfigResolutionMask = 0x07
+
+const hdc1080ResetDelay = 15 * time.Millisecond
+
+// HDC1080Resolution is the resolution of the humidity and temperature
+// measurements, as the bits of the configuration register.
+type HDC1080Resolution uint8
+
+const (
+	// HDC1080ResolutionRH14T14 is a 14 bit humidity and 14 bit temperature
+	// resolution, the default of the HDC1080.
+	HDC1080ResolutionRH14T14 HDC1080Resolution = 0x00
+	// HDC1080ResolutionRH11T14 is a 11 bit humidity and 14 bit temperature
+	// resolution.
+	HDC1080ResolutionRH11T14 HDC1080Resolution = 0x01
+	// HDC1080ResolutionRH8T14 is a 8 bit humidity and 14 bit temperature
+	// resolution.
+	HDC1080ResolutionRH8T14 HDC1080Resolution = 0x02
+	// HDC1080ResolutionRH14T11 is a 14 bit humidity and 11 bit temperature
+	// resolution.
+	HDC1080ResolutionRH14T11 HDC1080Resolution = 0x04
+	// HDC1080ResolutionRH11T11 is a 11 bit humidity and 11 bit temperature
+	// resolution.
+	HDC1080ResolutionRH11T11 HDC1080Resolution = 0x05
+	// HDC1080ResolutionRH8T11 is a 8 bit humidity and 11 bit temperature
+	// resolution, the fastest.
+	HDC1080ResolutionRH8T11 HDC1080Resolution = 0x06
+)
+
+// delay returns the time a combined acquisition takes at the resolution:
+// the conversion of the temperature then of the humidity, which the HDC1080
+// does in sequence, from the conversion times of the datasheet rounded up.
+func (r HDC1080Resolution) delay() time.Duration {
+	temp := 7 * time.Millisecond
+	if r&0x04 != 0 {
+		temp = 4 * time.Millisecond
+	}
+	humidity := 7 * time.Millisecond
+	switch r & 0x03 {
+	case 0x01:
+		humidity = 4 * time.Millisecond
+	case 0x02:
+		humidity = 3 * time.Millisecond
+	}
+	return temp + humidity
+}
+
+func (r HDC1080Resolution) valid() bool {
+	return r&^hdc1080ConfigResolutionMask == 0 && r&0x03 != 0x03
+}
+
+// HDC1080Driver is the gobot driver for the Texas Instruments HDC1080
+// temperature and humidity sensor.
+// Device datasheet: https://www.ti.com/lit/ds/symlink/hdc1080.pdf
+//
+// The sensor is set in its combined acquisition mode: a single trigger
+// converts the temperature then the humidity, which are read together,
+// after both conversions, from the temperature register on. The sensor
+// does not acknowledge a read before the end of the conversions, which the
+// driver thus waits for.
+type HDC1080Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	resolution HDC1080Resolution
+	heater     bool
+	mutex      *sync.Mutex
+}
+
+// NewHDC1080Driver creates a new driver with the i2c interface for the HDC1080 device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithHDC1080Resolution(HDC1080Resolution):	resolution, defaults to HDC1080ResolutionRH14T14
+//
+func NewHDC1080Driver(c Connector, options ...func(Config)) *HDC1080Driver {
+	h := &HDC1080Driver{
+		name:       gobot.DefaultName("HDC1080"),
+		connector:  c,
+		Config:     NewConfig(),
+		resolution: HDC1080ResolutionRH14T14,
+		mutex:      &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// WithHDC1080Resolution option sets the resolution of the measurements, set
+// on Start. Unknown resolutions are ignored.
+func WithHDC1080Resolution(val HDC1080Resolution) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*HDC1080Driver)
+		if ok && val.valid() {
+			d.resolution = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *HDC1080Driver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *HDC1080Driver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *HDC1080Driver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start checks the identity of the device, then resets it and configures
+// its combined acquisition mode and resolution.
+func (d *HDC1080Driver) Start() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(hdc1080Address)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	return d.initialization()
+}
+
+func (d *HDC1080Driver) initialization() (err error) {
+	var manufacturer, device uint16
+	if manufacturer, err = ReadWord(d.connection, hdc1080RegisterManufacturerID, binary.BigEndian); err != nil {
+		return err
+	}
+	if device, err = ReadWord(d.connection, hdc1080RegisterDeviceID, binary.BigEndian); err != nil {
+		return err
+	}
+	if manufacturer != hdc1080ManufacturerID || device != hdc1080DeviceID {
+		return fmt.Errorf("HDC1080 device not found (manufacturer 0x%04X, device 0x%04X)", manufacturer, device)
+	}
+	if _, err = d.connection.Write([]byte{hdc1080RegisterConfig, hdc1080ConfigReset, 0x00}); err != nil {
+		return err
+	}
+	time.Sleep(hdc1080ResetDelay)
+	return d.writeConfig(d.resolution, d.heater)
+}
+
+// Halt is a noop for the HDC1080.
+func (d *HDC1080Driver) Halt() (err error) {
+	return nil
+}
+
+// SupportsHumidity returns true, the HDC1080 measures the relative humidity.
+func (d *HDC1080Driver) SupportsHumidity() bool {
+	return true
+}
+
+// SetResolution sets the resolution of the measurements, or returns
+// ErrInvalidResolution for an unknown resolution.
+func (d *HDC1080Driver) SetResolution(res HDC1080Resolution) error {
+	if !res.valid() {
+		return ErrInvalidResolution
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.writeConfig(res, d.heater); err != nil {
+		return err
+	}
+	d.resolution = res
+	return nil
+}
+
+// SetHeater turns the heater of the HDC1080 on or off. The heater drives
+// off the condensation of a sensor exposed to a high humidity; it only
+// heats during the measurements, and biases the temperature while on.
+func (d *HDC1080Driver) SetHeater(on bool) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.writeConfig(d.resolution, on); err != nil {
+		return err
+	}
+	d.heater = on
+	return nil
+}
+
+// Temperature returns the current temperature, in celsius degrees.
+func (d *HDC1080Driver) Temperature() (temp float32, err error) {
+	temp, _, err = d.Sample()
+	return temp, err
+}
+
+// Humidity returns the current relative humidity, in percent.
+func (d *HDC1080Driver) Humidity() (humidity float32, err error) {
+	_, humidity, err = d.Sample()
+	return humidity, err
+}
+
+// Sample returns the current temperature, in celsius degrees, and relative
+// humidity, in percent, of a single combined acquisition.
+func (d *HDC1080Driver) Sample() (temp float32, humidity float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	// writing the register pointer triggers the acquisition.
+	if _, err = d.connection.Write([]byte{hdc1080RegisterTemperature}); err != nil {
+		return 0, 0, err
+	}
+	time.Sleep(d.resolution.delay())
+
+	buf := make([]byte, 4)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return 0, 0, err
+	}
+	if bytesRead != 4 {
+		return 0, 0, ErrNotEnoughBytes
+	}
+	temp = hdc1080DecodeTemp(binary.BigEndian.Uint16(buf[0:]))
+	humidity = hdc1080DecodeHumidity(binary.BigEndian.Uint16(buf[2:]))
+	return temp, humidity, nil
+}
+
+func (d *HDC1080Driver) writeConfig(res HDC1080Resolution, heater bool) error {
+	config := hdc1080ConfigModeSequence | byte(res)
+	if heater {
+		config |= hdc1080ConfigHeater
+	}
+	_, err := d.connection.Write([]byte{hdc1080RegisterConfig, config, 0x00})
+	return err
+}
+
+// hdc1080DecodeTemp returns the temperature, in celsius degrees, of the value
+// of the temperature register.
+func hdc1080DecodeTemp(raw uint16) float32 {
+	return float32(raw)*165/65536 - 40
+}
+
+// hdc1080DecodeHumidity returns the relative humidity, in percent, of the
+// value of the humidity register.
+func hdc1080DecodeHumidity(raw uint16) float32 {
+	return float32(raw) * 100 / 65536
+}
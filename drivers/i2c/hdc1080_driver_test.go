@@ -0,0 +1,230 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*HDC1080Driver)(nil)
+
+// --------- HELPERS
+func initTestHDC1080Driver() (driver *HDC1080Driver) {
+	driver, _ = initTestHDC1080DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestHDC1080DriverWithStubbedAdaptor(options ...func(Config)) (*HDC1080Driver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewHDC1080Driver(adaptor, options...), adaptor
+}
+
+// hdc1080TestDevice simulates a HDC1080, answering the identity reads, and
+// the read of a combined acquisition with its temperature and humidity, by
+// default 42.5 degrees and 50%.
+type hdc1080TestDevice struct {
+	manufacturer uint16
+	device       uint16
+	temp         uint16
+	humidity     uint16
+	config       byte
+	pointer      byte
+	triggered    time.Time
+	read         time.Time
+}
+
+func newHDC1080TestDevice(adaptor *i2cTestAdaptor) *hdc1080TestDevice {
+	dev := &hdc1080TestDevice{
+		manufacturer: hdc1080ManufacturerID,
+		device:       hdc1080DeviceID,
+		temp:         0x8000,
+		humidity:     0x8000,
+	}
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		dev.pointer = b[0]
+		if b[0] == hdc1080RegisterConfig && len(b) == 3 {
+			dev.config = b[1]
+		}
+		if b[0] == hdc1080RegisterTemperature {
+			dev.triggered = time.Now()
+		}
+		return len(b), nil
+	}
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		var words []uint16
+		switch dev.pointer {
+		case hdc1080RegisterManufacturerID:
+			words = []uint16{dev.manufacturer}
+		case hdc1080RegisterDeviceID:
+			words = []uint16{dev.device}
+		case hdc1080RegisterTemperature:
+			dev.read = time.Now()
+			words = []uint16{dev.temp, dev.humidity}
+		}
+		for i, w := range words {
+			if 2*i+1 < len(b) {
+				b[2*i], b[2*i+1] = byte(w>>8), byte(w)
+			}
+		}
+		return len(b), nil
+	}
+	return dev
+}
+
+// --------- TESTS
+
+func TestNewHDC1080Driver(t *testing.T) {
+	// Does it return a pointer to an instance of HDC1080Driver?
+	var hdc1080 interface{} = NewHDC1080Driver(newI2cTestAdaptor())
+	_, ok := hdc1080.(*HDC1080Driver)
+	if !ok {
+		t.Errorf("NewHDC1080Driver() should have returned a *HDC1080Driver")
+	}
+
+	d := NewHDC1080Driver(newI2cTestAdaptor())
+	gobottest.Refute(t, d.Connection(), nil)
+	gobottest.Assert(t, d.resolution, HDC1080ResolutionRH14T14)
+	gobottest.Assert(t, d.SupportsHumidity(), true)
+}
+
+func TestHDC1080DriverOptions(t *testing.T) {
+	d := NewHDC1080Driver(newI2cTestAdaptor(), WithBus(2), WithHDC1080Resolution(HDC1080ResolutionRH8T11))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.resolution, HDC1080ResolutionRH8T11)
+
+	d = NewHDC1080Driver(newI2cTestAdaptor(), WithHDC1080Resolution(HDC1080Resolution(0x03)))
+	gobottest.Assert(t, d.resolution, HDC1080ResolutionRH14T14)
+}
+
+func TestHDC1080DriverSetName(t *testing.T) {
+	d := initTestHDC1080Driver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "TESTME"), true)
+}
+
+func TestHDC1080DriverStart(t *testing.T) {
+	d, adaptor := initTestHDC1080DriverWithStubbedAdaptor(WithHDC1080Resolution(HDC1080ResolutionRH11T11))
+	dev := newHDC1080TestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, adaptor.address, 0x40)
+	// the identity, the reset, then the combined acquisition mode.
+	gobottest.Assert(t, adaptor.written, []byte{0xFE, 0xFF, 0x02, 0x80, 0x00, 0x02, 0x15, 0x00})
+	gobottest.Assert(t, dev.config, uint8(0x15))
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestHDC1080DriverStartNotFound(t *testing.T) {
+	d, adaptor := initTestHDC1080DriverWithStubbedAdaptor()
+	dev := newHDC1080TestDevice(adaptor)
+	// a HDC1000.
+	dev.device = 0x1000
+	gobottest.Assert(t, d.Start(), errors.New("HDC1080 device not found (manufacturer 0x5449, device 0x1000)"))
+	// nothing is configured.
+	gobottest.Assert(t, adaptor.written, []byte{0xFE, 0xFF})
+}
+
+func TestHDC1080DriverStartError(t *testing.T) {
+	d, adaptor := initTestHDC1080DriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+
+	d, adaptor = initTestHDC1080DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	gobottest.Assert(t, d.Start(), errors.New("read error"))
+}
+
+func TestHDC1080Decode(t *testing.T) {
+	gobottest.Assert(t, hdc1080DecodeTemp(0x0000), float32(-40))
+	gobottest.Assert(t, hdc1080DecodeTemp(0x4000), float32(1.25))
+	gobottest.Assert(t, hdc1080DecodeTemp(0x8000), float32(42.5))
+	gobottest.Assert(t, hdc1080DecodeTemp(0xFFFF) < 125, true)
+	gobottest.Assert(t, hdc1080DecodeHumidity(0x0000), float32(0))
+	gobottest.Assert(t, hdc1080DecodeHumidity(0x4000), float32(25))
+	gobottest.Assert(t, hdc1080DecodeHumidity(0x8000), float32(50))
+	gobottest.Assert(t, hdc1080DecodeHumidity(0xFFFF) < 100, true)
+}
+
+func TestHDC1080ResolutionDelay(t *testing.T) {
+	gobottest.Assert(t, HDC1080ResolutionRH14T14.delay(), 14*time.Millisecond)
+	gobottest.Assert(t, HDC1080ResolutionRH11T14.delay(), 11*time.Millisecond)
+	gobottest.Assert(t, HDC1080ResolutionRH8T14.delay(), 10*time.Millisecond)
+	gobottest.Assert(t, HDC1080ResolutionRH14T11.delay(), 11*time.Millisecond)
+	gobottest.Assert(t, HDC1080ResolutionRH8T11.delay(), 7*time.Millisecond)
+}
+
+func TestHDC1080DriverSample(t *testing.T) {
+	d, adaptor := initTestHDC1080DriverWithStubbedAdaptor()
+	dev := newHDC1080TestDevice(adaptor)
+	d.Start()
+
+	adaptor.written = []byte{}
+	temp, humidity, err := d.Sample()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(42.5))
+	gobottest.Assert(t, humidity, float32(50))
+	// a single trigger for both measurements.
+	gobottest.Assert(t, adaptor.written, []byte{0x00})
+	// read after both conversions.
+	gobottest.Assert(t, dev.read.Sub(dev.triggered) >= 14*time.Millisecond, true)
+
+	dev.temp, dev.humidity = 0x4000, 0x4000
+	temp, err = d.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(1.25))
+	humidity, err = d.Humidity()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, humidity, float32(25))
+}
+
+func TestHDC1080DriverSampleError(t *testing.T) {
+	d, adaptor := initTestHDC1080DriverWithStubbedAdaptor()
+	newHDC1080TestDevice(adaptor)
+	d.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 2, nil
+	}
+	_, _, err := d.Sample()
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	_, _, err = d.Sample()
+	gobottest.Assert(t, err, errors.New("write error"))
+}
+
+func TestHDC1080DriverSetResolution(t *testing.T) {
+	d, adaptor := initTestHDC1080DriverWithStubbedAdaptor()
+	dev := newHDC1080TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetResolution(HDC1080ResolutionRH8T14), nil)
+	gobottest.Assert(t, dev.config, uint8(0x12))
+	gobottest.Assert(t, d.SetResolution(HDC1080Resolution(0x07)), ErrInvalidResolution)
+	gobottest.Assert(t, d.SetResolution(HDC1080Resolution(0x08)), ErrInvalidResolution)
+	gobottest.Assert(t, d.resolution, HDC1080ResolutionRH8T14)
+}
+
+func TestHDC1080DriverSetHeater(t *testing.T) {
+	d, adaptor := initTestHDC1080DriverWithStubbedAdaptor(WithHDC1080Resolution(HDC1080ResolutionRH11T14))
+	dev := newHDC1080TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetHeater(true), nil)
+	gobottest.Assert(t, dev.config, uint8(0x31))
+	// the heater is kept with the resolution.
+	gobottest.Assert(t, d.SetResolution(HDC1080ResolutionRH14T14), nil)
+	gobottest.Assert(t, dev.config, uint8(0x30))
+	gobottest.Assert(t, d.SetHeater(false), nil)
+	gobottest.Assert(t, dev.config, uint8(0x10))
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, d.SetHeater(true), errors.New("write error"))
+	gobottest.Assert(t, d.heater, false)
+}
@@ -22,6 +22,7 @@ var blue = castColor("blue")
 
 type i2cTestAdaptor struct {
 	name          string
+	address       int
 	written       []byte
 	mtx           sync.Mutex
 	i2cConnectErr bool
@@ -150,10 +151,11 @@ func (t *i2cTestAdaptor) WriteBlockData(reg uint8, b []byte) (err error) {
 	return
 }
 
-func (t *i2cTestAdaptor) GetConnection( /* address */ int /* bus */, int) (connection Connection, err error) {
+func (t *i2cTestAdaptor) GetConnection(address int, bus int) (connection Connection, err error) {
 	if t.i2cConnectErr {
 		return nil, errors.New("Invalid i2c connection")
 	}
+	t.address = address
 	return t, nil
 }
 
@@ -177,3 +179,57 @@ func newI2cTestAdaptor() *i2cTestAdaptor {
 		},
 	}
 }
+
+// i2cTestWordDevice is a device with big-endian 16 bit registers, like the
+// MCP9808 or the TMP102: a write of 2 bytes sets the byte of a register,
+// a write of 3 bytes its word, and a read returns the word of the register
+// written last.
+type i2cTestWordDevice struct {
+	mtx  sync.Mutex
+	reg  byte
+	regs map[byte]uint16
+}
+
+func newI2cTestWordDevice(adaptor *i2cTestAdaptor, regs map[byte]uint16) *i2cTestWordDevice {
+	dev := &i2cTestWordDevice{regs: regs}
+	adaptor.i2cWriteImpl = dev.write
+	adaptor.i2cReadImpl = dev.read
+	return dev
+}
+
+func (dev *i2cTestWordDevice) write(b []byte) (int, error) {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	dev.reg = b[0]
+	switch len(b) {
+	case 2:
+		dev.regs[b[0]] = uint16(b[1])
+	case 3:
+		dev.regs[b[0]] = uint16(b[1])<<8 | uint16(b[2])
+	}
+	return len(b), nil
+}
+
+func (dev *i2cTestWordDevice) read(b []byte) (int, error) {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	val := dev.regs[dev.reg]
+	copy(b, []byte{byte(val >> 8), byte(val)})
+	return len(b), nil
+}
+
+func (dev *i2cTestWordDevice) get(reg byte) uint16 {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	return dev.regs[reg]
+}
+
+func (dev *i2cTestWordDevice) set(reg byte, val uint16) {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	dev.regs[reg] = val
+}
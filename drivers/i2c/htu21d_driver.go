@@ -0,0 +1,320 @@
+package i2c
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sigurn/crc8"
+	"gobot.io/x/gobot"
+)
+
+const htu21dAddress = 0x40
+
+const htu21dCmdTempHold = 0xE3
+const htu21dCmdHumidityHold = 0xE5
+const htu21dCmdTempNoHold = 0xF3
+const htu21dCmdHumidityNoHold = 0xF5
+const htu21dCmdWriteUser = 0xE6
+const htu21dCmdReadUser = 0xE7
+const htu21dCmdSoftReset = 0xFE
+
+// the resolution bits of the user register, bit 7 and bit 0.
+const htu21dUserResolutionMask = 0x81
+
+// the two low bits of a measurement are status bits.
+const htu21dStatusMask = 0x0003
+
+const htu21dSoftResetDelay = 15 * time.Millisecond
+
+var htu21dCrc8Params = crc8.Params{
+	Poly:   0x31,
+	Init:   0x00,
+	RefIn:  false,
+	RefOut: false,
+	XorOut: 0x00,
+	Check:  0xA2,
+	Name:   "CRC-8/HTU21D",
+}
+
+// ErrInvalidResolution is returned when the HTU21D is set to an unknown
+// resolution.
+var ErrInvalidResolution = errors.New("Invalid resolution")
+
+// HTU21DMeasurementMode is the way the HTU21D holds the bus during a
+// measurement.
+type HTU21DMeasurementMode uint8
+
+const (
+	// HTU21DHoldMaster makes the HTU21D stretch the clock until the
+	// measurement is complete: the read of the result waits for it. The
+	// adaptor must support clock stretching, which e.g. the Raspberry Pi does
+	// not do reliably.
+	HTU21DHoldMaster HTU21DMeasurementMode = iota
+	// HTU21DNoHoldMaster frees the bus during the measurement: the driver
+	// sleeps for the maximum conversion time of the resolution before
+	// reading the result.
+	HTU21DNoHoldMaster
+)
+
+// HTU21DResolution is the resolution of the humidity and temperature
+// measurements, as the bits of the user register.
+type HTU21DResolution uint8
+
+const (
+	// HTU21DResolutionRH12T14 is a 12 bit humidity and 14 bit temperature
+	// resolution, the default of the HTU21D.
+	HTU21DResolutionRH12T14 HTU21DResolution = 0x00
+	// HTU21DResolutionRH8T12 is a 8 bit humidity and 12 bit temperature
+	// resolution.
+	HTU21DResolutionRH8T12 HTU21DResolution = 0x01
+	// HTU21DResolutionRH10T13 is a 10 bit humidity and 13 bit temperature
+	// resolution.
+	HTU21DResolutionRH10T13 HTU21DResolution = 0x80
+	// HTU21DResolutionRH11T11 is a 11 bit humidity and 11 bit temperature
+	// resolution.
+	HTU21DResolutionRH11T11 HTU21DResolution = 0x81
+)
+
+// delays returns the maximum conversion times of the humidity and of the
+// temperature for the resolution, from the HTU21D datasheet, which are longer
+// than the Si7021 ones.
+func (r HTU21DResolution) delays() (humidity, temp time.Duration) {
+	switch r {
+	case HTU21DResolutionRH8T12:
+		return 3 * time.Millisecond, 13 * time.Millisecond
+	case HTU21DResolutionRH10T13:
+		return 5 * time.Millisecond, 25 * time.Millisecond
+	case HTU21DResolutionRH11T11:
+		return 8 * time.Millisecond, 7 * time.Millisecond
+	default:
+		return 16 * time.Millisecond, 50 * time.Millisecond
+	}
+}
+
+// HTU21DDriver is the gobot driver for the HTU21D humidity and temperature
+// sensor, which also drives the compatible Si7021.
+// Device datasheet: https://cdn-shop.adafruit.com/datasheets/1899_HTU21D.pdf
+//
+// Each measurement is returned with a CRC-8, which the driver verifies.
+type HTU21DDriver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	mode       HTU21DMeasurementMode
+	resolution HTU21DResolution
+	crcTable   *crc8.Table
+	mutex      *sync.Mutex
+}
+
+// NewHTU21DDriver creates a new driver with the i2c interface for the HTU21D device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithHTU21DMeasurementMode(HTU21DMeasurementMode):	measurement mode, defaults to HTU21DHoldMaster
+//		i2c.WithHTU21DResolution(HTU21DResolution):	resolution, defaults to HTU21DResolutionRH12T14
+//
+func NewHTU21DDriver(c Connector, options ...func(Config)) *HTU21DDriver {
+	h := &HTU21DDriver{
+		name:       gobot.DefaultName("HTU21D"),
+		connector:  c,
+		Config:     NewConfig(),
+		mode:       HTU21DHoldMaster,
+		resolution: HTU21DResolutionRH12T14,
+		crcTable:   crc8.MakeTable(htu21dCrc8Params),
+		mutex:      &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// WithHTU21DMeasurementMode option sets whether the HTU21D holds the bus
+// during the measurements. Unknown modes are ignored.
+func WithHTU21DMeasurementMode(val HTU21DMeasurementMode) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*HTU21DDriver)
+		if ok && val <= HTU21DNoHoldMaster {
+			d.mode = val
+		}
+	}
+}
+
+// WithHTU21DResolution option sets the resolution of the measurements, set
+// on Start. Unknown resolutions are ignored.
+func WithHTU21DResolution(val HTU21DResolution) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*HTU21DDriver)
+		if ok && val&^htu21dUserResolutionMask == 0 {
+			d.resolution = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *HTU21DDriver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *HTU21DDriver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *HTU21DDriver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start resets the HTU21D and sets its resolution.
+func (d *HTU21DDriver) Start() (err error) {
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(htu21dAddress)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *HTU21DDriver) initialization() (err error) {
+	if _, err = d.connection.Write([]byte{htu21dCmdSoftReset}); err != nil {
+		return err
+	}
+	time.Sleep(htu21dSoftResetDelay)
+	return d.writeResolution(d.resolution)
+}
+
+// Halt is a noop for the HTU21D.
+func (d *HTU21DDriver) Halt() (err error) {
+	return nil
+}
+
+// SupportsHumidity returns true, the HTU21D measures the relative humidity.
+func (d *HTU21DDriver) SupportsHumidity() bool {
+	return true
+}
+
+// Temperature returns the current temperature, in celsius degrees.
+func (d *HTU21DDriver) Temperature() (temp float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	cmd := byte(htu21dCmdTempHold)
+	if d.mode == HTU21DNoHoldMaster {
+		cmd = htu21dCmdTempNoHold
+	}
+	_, delay := d.resolution.delays()
+	var raw uint16
+	if raw, err = d.measure(cmd, delay); err != nil {
+		return 0, err
+	}
+	return -46.85 + 175.72*float32(raw)/65536, nil
+}
+
+// Humidity returns the current relative humidity, in percent. It is not
+// compensated for the temperature.
+func (d *HTU21DDriver) Humidity() (humidity float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	cmd := byte(htu21dCmdHumidityHold)
+	if d.mode == HTU21DNoHoldMaster {
+		cmd = htu21dCmdHumidityNoHold
+	}
+	delay, _ := d.resolution.delays()
+	var raw uint16
+	if raw, err = d.measure(cmd, delay); err != nil {
+		return 0, err
+	}
+	return -6 + 125*float32(raw)/65536, nil
+}
+
+// Resolution reads the resolution of the measurements from the user
+// register of the HTU21D.
+func (d *HTU21DDriver) Resolution() (HTU21DResolution, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	user, err := d.readUser()
+	if err != nil {
+		return 0, err
+	}
+	return HTU21DResolution(user & htu21dUserResolutionMask), nil
+}
+
+// SetResolution sets the resolution of the measurements in the user
+// register of the HTU21D, or returns ErrInvalidResolution for an unknown
+// resolution. The other bits of the register are kept.
+func (d *HTU21DDriver) SetResolution(res HTU21DResolution) error {
+	if res&^htu21dUserResolutionMask != 0 {
+		return ErrInvalidResolution
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.writeResolution(res); err != nil {
+		return err
+	}
+	d.resolution = res
+	return nil
+}
+
+func (d *HTU21DDriver) writeResolution(res HTU21DResolution) error {
+	user, err := d.readUser()
+	if err != nil {
+		return err
+	}
+	user = user&^htu21dUserResolutionMask | byte(res)
+	_, err = d.connection.Write([]byte{htu21dCmdWriteUser, user})
+	return err
+}
+
+func (d *HTU21DDriver) readUser() (byte, error) {
+	if _, err := d.connection.Write([]byte{htu21dCmdReadUser}); err != nil {
+		return 0, err
+	}
+	buf := []byte{0}
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if bytesRead != 1 {
+		return 0, ErrNotEnoughBytes
+	}
+	return buf[0], nil
+}
+
+// measure triggers a measurement, and returns its value without the status
+// bits. In the no hold master mode, it waits for the delay before reading
+// the result.
+func (d *HTU21DDriver) measure(cmd byte, delay time.Duration) (uint16, error) {
+	if _, err := d.connection.Write([]byte{cmd}); err != nil {
+		return 0, err
+	}
+	if d.mode == HTU21DNoHoldMaster {
+		time.Sleep(delay)
+	}
+	buf := make([]byte, 3)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if bytesRead != 3 {
+		return 0, ErrNotEnoughBytes
+	}
+	if crc8.Checksum(buf[:2], d.crcTable) != buf[2] {
+		return 0, ErrInvalidCrc
+	}
+	return (uint16(buf[0])<<8 | uint16(buf[1])) &^ htu21dStatusMask, nil
+}
@@ -0,0 +1,217 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*HTU21DDriver)(nil)
+
+// --------- HELPERS
+func initTestHTU21DDriver() (driver *HTU21DDriver) {
+	driver, _ = initTestHTU21DDriverWithStubbedAdaptor()
+	return
+}
+
+func initTestHTU21DDriverWithStubbedAdaptor() (*HTU21DDriver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewHTU21DDriver(adaptor), adaptor
+}
+
+// htu21dTestReadImpl answers reads with the values from the datasheet
+// examples, and with the default user register.
+func htu21dTestReadImpl(adaptor *i2cTestAdaptor) func([]byte) (int, error) {
+	return func(b []byte) (int, error) {
+		switch adaptor.written[len(adaptor.written)-1] {
+		case htu21dCmdReadUser:
+			copy(b, []byte{0x02})
+		case htu21dCmdTempHold, htu21dCmdTempNoHold:
+			copy(b, []byte{0x68, 0x3A, 0x7C})
+		case htu21dCmdHumidityHold, htu21dCmdHumidityNoHold:
+			copy(b, []byte{0x4E, 0x85, 0x6B})
+		}
+		return len(b), nil
+	}
+}
+
+// --------- TESTS
+
+func TestNewHTU21DDriver(t *testing.T) {
+	// Does it return a pointer to an instance of HTU21DDriver?
+	var htu21d interface{} = NewHTU21DDriver(newI2cTestAdaptor())
+	_, ok := htu21d.(*HTU21DDriver)
+	if !ok {
+		t.Errorf("NewHTU21DDriver() should have returned a *HTU21DDriver")
+	}
+
+	h := NewHTU21DDriver(newI2cTestAdaptor())
+	gobottest.Refute(t, h.Connection(), nil)
+}
+
+func TestHTU21DDriverStart(t *testing.T) {
+	htu21d, adaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = htu21dTestReadImpl(adaptor)
+
+	gobottest.Assert(t, htu21d.Start(), nil)
+	// soft reset, then the resolution kept with the other user bits.
+	gobottest.Assert(t, adaptor.written, []byte{0xFE, 0xE7, 0xE6, 0x02})
+}
+
+func TestHTU21DDriverStartConnectError(t *testing.T) {
+	htu21d, adaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, htu21d.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestHTU21DDriverStartWriteError(t *testing.T) {
+	htu21d, adaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, htu21d.Start(), errors.New("write error"))
+}
+
+func TestHTU21DDriverHalt(t *testing.T) {
+	htu21d := initTestHTU21DDriver()
+
+	gobottest.Assert(t, htu21d.Halt(), nil)
+}
+
+func TestHTU21DDriverSupportsHumidity(t *testing.T) {
+	htu21d := initTestHTU21DDriver()
+
+	gobottest.Assert(t, htu21d.SupportsHumidity(), true)
+}
+
+func TestHTU21DDriverHoldMaster(t *testing.T) {
+	htu21d, adaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = htu21dTestReadImpl(adaptor)
+	htu21d.Start()
+
+	adaptor.written = []byte{}
+	temp, err := htu21d.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(24.686401))
+	humidity, err := htu21d.Humidity()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, humidity, float32(32.337708))
+	// the results are read right after the commands, the device stretching
+	// the clock until they are ready.
+	gobottest.Assert(t, adaptor.written, []byte{0xE3, 0xE5})
+}
+
+func TestHTU21DDriverNoHoldMaster(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	htu21d := NewHTU21DDriver(adaptor, WithHTU21DMeasurementMode(HTU21DNoHoldMaster))
+	adaptor.i2cReadImpl = htu21dTestReadImpl(adaptor)
+	htu21d.Start()
+
+	adaptor.written = []byte{}
+	start := time.Now()
+	temp, err := htu21d.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(24.686401))
+	gobottest.Assert(t, time.Since(start) >= 50*time.Millisecond, true)
+	start = time.Now()
+	humidity, err := htu21d.Humidity()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, humidity, float32(32.337708))
+	gobottest.Assert(t, time.Since(start) >= 16*time.Millisecond, true)
+	gobottest.Assert(t, adaptor.written, []byte{0xF3, 0xF5})
+}
+
+func TestHTU21DDriverInvalidCrc(t *testing.T) {
+	htu21d, adaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	htu21d.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x68, 0x3A, 0x7D})
+		return len(b), nil
+	}
+	_, err := htu21d.Temperature()
+	gobottest.Assert(t, err, ErrInvalidCrc)
+}
+
+func TestHTU21DDriverMeasurementErrors(t *testing.T) {
+	htu21d, adaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	htu21d.Start()
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 2, nil
+	}
+	_, err := htu21d.Humidity()
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = htu21d.Humidity()
+	gobottest.Assert(t, err, errors.New("read error"))
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	_, err = htu21d.Temperature()
+	gobottest.Assert(t, err, errors.New("write error"))
+}
+
+func TestHTU21DDriverResolution(t *testing.T) {
+	htu21d, adaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	user := byte(0x02)
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		b[0] = user
+		return 1, nil
+	}
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		if b[0] == htu21dCmdWriteUser {
+			user = b[1]
+		}
+		return len(b), nil
+	}
+	htu21d.Start()
+
+	res, err := htu21d.Resolution()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, res, HTU21DResolutionRH12T14)
+
+	gobottest.Assert(t, htu21d.SetResolution(HTU21DResolutionRH11T11), nil)
+	gobottest.Assert(t, user, byte(0x83))
+	res, _ = htu21d.Resolution()
+	gobottest.Assert(t, res, HTU21DResolutionRH11T11)
+
+	gobottest.Assert(t, htu21d.SetResolution(0x02), ErrInvalidResolution)
+	gobottest.Assert(t, user, byte(0x83))
+}
+
+func TestHTU21DDriverName(t *testing.T) {
+	htu21d := initTestHTU21DDriver()
+
+	gobottest.Assert(t, strings.HasPrefix(htu21d.Name(), "HTU21D"), true)
+	htu21d.SetName("Sensor")
+	gobottest.Assert(t, htu21d.Name(), "Sensor")
+}
+
+func TestHTU21DDriverOptions(t *testing.T) {
+	htu21d := NewHTU21DDriver(newI2cTestAdaptor(), WithBus(2),
+		WithHTU21DMeasurementMode(HTU21DNoHoldMaster), WithHTU21DResolution(HTU21DResolutionRH8T12))
+	gobottest.Assert(t, htu21d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, htu21d.mode, HTU21DNoHoldMaster)
+	gobottest.Assert(t, htu21d.resolution, HTU21DResolutionRH8T12)
+
+	// invalid values are ignored.
+	htu21d = NewHTU21DDriver(newI2cTestAdaptor(),
+		WithHTU21DMeasurementMode(2), WithHTU21DResolution(0x02))
+	gobottest.Assert(t, htu21d.mode, HTU21DHoldMaster)
+	gobottest.Assert(t, htu21d.resolution, HTU21DResolutionRH12T14)
+}
+
+func TestHTU21DDriverAddress(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	htu21d := NewHTU21DDriver(adaptor)
+	htu21d.Start()
+	gobottest.Assert(t, adaptor.address, 0x40)
+}
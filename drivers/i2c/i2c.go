@@ -1,6 +1,7 @@
 package i2c
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 	"sync"
@@ -9,6 +10,14 @@ import (
 const (
 	// Error event
 	Error = "error"
+
+	// Temperature event with the temperature, in celsius degrees, measured
+	// at each poll interval
+	Temperature = "temperature"
+
+	// Humidity event with the relative humidity, in percent, measured at
+	// each poll interval
+	Humidity = "humidity"
 )
 
 const (
@@ -26,6 +35,10 @@ var (
 	ErrInvalidPosition = errors.New("Invalid position value")
 )
 
+// ErrAlreadyStarted is returned by Start when the driver is already started,
+// and not halted since.
+var ErrAlreadyStarted = errors.New("Driver already started")
+
 type I2cOperations interface {
 	io.ReadWriteCloser
 	ReadByte() (val byte, err error)
@@ -182,3 +195,53 @@ func (c *i2cConnection) WriteBlockData(reg uint8, b []byte) (err error) {
 	}
 	return c.bus.WriteBlockData(reg, b)
 }
+
+// ScanBus probes every valid 7-bit address (0x03 to 0x77) of the given bus,
+// like the i2cdetect tool, and returns the addresses of the devices that
+// answered a single byte read. A failing read only means that no device is
+// present at that address; an error is returned only when the Connector
+// itself fails. Note that reading may change the state of some devices.
+func ScanBus(c Connector, bus int) (addresses []int, err error) {
+	addresses = []int{}
+	for address := 0x03; address <= 0x77; address++ {
+		var conn Connection
+		if conn, err = c.GetConnection(address, bus); err != nil {
+			return nil, err
+		}
+		if _, err := conn.ReadByte(); err == nil {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// ReadWord reads a 16 bit word from a register of the device, by writing
+// the register address and then reading back two bytes in the given byte
+// order. Unlike the ReadWordData of the connection it does not need SMBus
+// support from the adaptor. Most sensors, like the BMP180 and the INA219,
+// send the high byte first (binary.BigEndian), while SMBus words are
+// little-endian.
+func ReadWord(c Connection, reg uint8, order binary.ByteOrder) (uint16, error) {
+	if _, err := c.Write([]byte{reg}); err != nil {
+		return 0, err
+	}
+	buf := []byte{0, 0}
+	bytesRead, err := c.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if bytesRead != 2 {
+		return 0, ErrNotEnoughBytes
+	}
+	return order.Uint16(buf), nil
+}
+
+// WriteWord writes a 16 bit word to a register of the device, as the
+// register address followed by the two bytes in the given byte order, like
+// ReadWord reads it.
+func WriteWord(c Connection, reg uint8, val uint16, order binary.ByteOrder) error {
+	buf := []byte{reg, 0, 0}
+	order.PutUint16(buf[1:], val)
+	_, err := c.Write(buf)
+	return err
+}
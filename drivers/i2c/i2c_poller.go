@@ -0,0 +1,57 @@
+package i2c
+
+import "time"
+
+// poller runs the poll of a driver in a goroutine, at an interval, between
+// the Start and the Halt of the driver. It is used with the mutex of the
+// driver locked, which the poll may lock too.
+type poller struct {
+	started bool
+	halt    chan bool
+	done    chan bool
+}
+
+// start marks the driver started, and calls poll at once then after each
+// interval until stop, when the interval is set. It returns
+// ErrAlreadyStarted if the driver is already started, rather than polling
+// twice.
+func (p *poller) start(interval time.Duration, poll func()) error {
+	if p.started {
+		return ErrAlreadyStarted
+	}
+	p.started = true
+	if interval <= 0 {
+		return nil
+	}
+
+	p.halt = make(chan bool)
+	p.done = make(chan bool)
+	go func(halt chan bool, done chan bool) {
+		defer close(done)
+		for {
+			poll()
+			select {
+			case <-halt:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}(p.halt, p.done)
+	return nil
+}
+
+// stop marks the driver halted and stops the poll, if any. It returns a
+// function waiting for the end of the poll, to call once the mutex of the
+// driver is unlocked, as the poll may be waiting for it.
+func (p *poller) stop() (wait func()) {
+	halt, done := p.halt, p.done
+	p.started = false
+	p.halt, p.done = nil, nil
+
+	return func() {
+		if halt != nil {
+			close(halt)
+			<-done
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package i2c
+
+import (
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestPollerWithoutInterval(t *testing.T) {
+	var p poller
+	gobottest.Assert(t, p.start(0, func() { t.Errorf("poller should not have polled") }), nil)
+	gobottest.Assert(t, p.started, true)
+	gobottest.Assert(t, p.halt == nil, true)
+	gobottest.Assert(t, p.start(0, nil), ErrAlreadyStarted)
+
+	p.stop()()
+	gobottest.Assert(t, p.started, false)
+}
+
+func TestPollerStartStop(t *testing.T) {
+	var p poller
+	polls := make(chan bool, 10)
+	poll := func() {
+		select {
+		case polls <- true:
+		default:
+		}
+	}
+	gobottest.Assert(t, p.start(time.Millisecond, poll), nil)
+	// the first poll is at once.
+	select {
+	case <-polls:
+	case <-time.After(1 * time.Second):
+		t.Errorf("poller did not poll")
+	}
+	gobottest.Assert(t, p.start(time.Millisecond, poll), ErrAlreadyStarted)
+
+	done := p.done
+	p.stop()()
+	gobottest.Assert(t, p.started, false)
+	gobottest.Assert(t, p.halt == nil, true)
+	// the poll is over once waited for.
+	select {
+	case <-done:
+	default:
+		t.Errorf("poller did not stop")
+	}
+
+	// it can be started again.
+	gobottest.Assert(t, p.start(time.Millisecond, poll), nil)
+	p.stop()()
+}
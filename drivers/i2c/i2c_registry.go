@@ -0,0 +1,165 @@
+package i2c
+
+import (
+	"fmt"
+	"sync"
+
+	"gobot.io/x/gobot"
+)
+
+// DriverFactory creates a Driver for an i2c device, as the NewXXXDriver
+// constructors do.
+type DriverFactory func(c Connector, options ...func(Config)) gobot.Driver
+
+// i2cDriverRegistry holds the factories of all the drivers of this package,
+// by the name of their constructor without New and Driver, e.g. "BMP180"
+// for NewBMP180Driver, "GroveLcd" for NewGroveLcdDriver.
+var (
+	i2cDriverRegistryMutex sync.RWMutex
+	i2cDriverRegistry      = map[string]DriverFactory{
+		"ADS1015": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewADS1015Driver(c, options...)
+		},
+		"ADS1115": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewADS1115Driver(c, options...)
+		},
+		"ADXL345": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewADXL345Driver(c, options...)
+		},
+		"AM2320": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewAM2320Driver(c, options...)
+		},
+		"AdafruitMotorHat": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewAdafruitMotorHatDriver(c, options...)
+		},
+		"BH1750": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewBH1750Driver(c, options...)
+		},
+		"BME280": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewBME280Driver(c, options...)
+		},
+		"BMP180": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewBMP180Driver(c, options...)
+		},
+		"BMP280": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewBMP280Driver(c, options...)
+		},
+		"BlinkM": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewBlinkMDriver(c, options...)
+		},
+		"CCS811": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewCCS811Driver(c, options...)
+		},
+		"DRV2605L": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewDRV2605LDriver(c, options...)
+		},
+		"GroveAccelerometer": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewGroveAccelerometerDriver(c, options...)
+		},
+		"GroveLcd": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewGroveLcdDriver(c, options...)
+		},
+		"GrovePi": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewGrovePiDriver(c, options...)
+		},
+		"HDC1080": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewHDC1080Driver(c, options...)
+		},
+		"HMC6352": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewHMC6352Driver(c, options...)
+		},
+		"HTU21D": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewHTU21DDriver(c, options...)
+		},
+		"INA219": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewINA219Driver(c, options...)
+		},
+		"INA3221": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewINA3221Driver(c, options...)
+		},
+		"JHD1313M1": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewJHD1313M1Driver(c, options...)
+		},
+		"L3GD20H": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewL3GD20HDriver(c, options...)
+		},
+		"LIDARLite": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewLIDARLiteDriver(c, options...)
+		},
+		"LIS3DH": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewLIS3DHDriver(c, options...)
+		},
+		"LPS25H": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewLPS25HDriver(c, options...)
+		},
+		"MCP23017": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewMCP23017Driver(c, options...)
+		},
+		"MCP9808": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewMCP9808Driver(c, options...)
+		},
+		"MMA7660": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewMMA7660Driver(c, options...)
+		},
+		"MPL115A2": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewMPL115A2Driver(c, options...)
+		},
+		"MPL3115A2": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewMPL3115A2Driver(c, options...)
+		},
+		"MPU6050": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewMPU6050Driver(c, options...)
+		},
+		"MS5611": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewMS5611Driver(c, options...)
+		},
+		"PCA9685": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewPCA9685Driver(c, options...)
+		},
+		"SHT3x": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewSHT3xDriver(c, options...)
+		},
+		"SSD1306": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewSSD1306Driver(c, options...)
+		},
+		"TCA9548A": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewTCA9548ADriver(c, options...)
+		},
+		"TMP102": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewTMP102Driver(c, options...)
+		},
+		"TSL2561": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewTSL2561Driver(c, options...)
+		},
+		"VL53L0X": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewVL53L0XDriver(c, options...)
+		},
+		"Wiichuck": func(c Connector, options ...func(Config)) gobot.Driver {
+			return NewWiichuckDriver(c, options...)
+		},
+	}
+)
+
+// RegisterI2CDriver makes a driver available by name to NewI2CDriverByName,
+// for robots whose devices are only known from their configuration. All the
+// drivers of this package are registered already; this is for drivers of
+// other packages. Registering a name again replaces its factory.
+func RegisterI2CDriver(name string, factory DriverFactory) {
+	i2cDriverRegistryMutex.Lock()
+	defer i2cDriverRegistryMutex.Unlock()
+
+	i2cDriverRegistry[name] = factory
+}
+
+// NewI2CDriverByName creates the driver registered with the given name, e.g.
+// "BMP180", for the given connector and options.
+func NewI2CDriverByName(name string, c Connector, options ...func(Config)) (gobot.Driver, error) {
+	i2cDriverRegistryMutex.RLock()
+	factory, ok := i2cDriverRegistry[name]
+	i2cDriverRegistryMutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("Unknown i2c driver %q", name)
+	}
+	return factory(c, options...), nil
+}
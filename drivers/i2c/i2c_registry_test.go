@@ -0,0 +1,100 @@
+package i2c
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestNewI2CDriverByName(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	d, err := NewI2CDriverByName("BMP180", adaptor, WithAddress(0x76))
+	gobottest.Assert(t, err, nil)
+	bmp180, ok := d.(*BMP180Driver)
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, bmp180.GetAddressOrDefault(bmp180Address), 0x76)
+
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	temp, _ := bmp180.Temperature()
+	gobottest.Assert(t, temp, float32(15.0))
+}
+
+func TestNewI2CDriverByNameUnknown(t *testing.T) {
+	_, err := NewI2CDriverByName("XYZ123", newI2cTestAdaptor())
+	gobottest.Assert(t, err, errors.New("Unknown i2c driver \"XYZ123\""))
+}
+
+func TestRegisterI2CDriver(t *testing.T) {
+	RegisterI2CDriver("TestINA219", func(c Connector, options ...func(Config)) gobot.Driver {
+		return NewINA219Driver(c, options...)
+	})
+	defer func() {
+		i2cDriverRegistryMutex.Lock()
+		delete(i2cDriverRegistry, "TestINA219")
+		i2cDriverRegistryMutex.Unlock()
+	}()
+
+	d, err := NewI2CDriverByName("TestINA219", newI2cTestAdaptor())
+	gobottest.Assert(t, err, nil)
+	_, ok := d.(*INA219Driver)
+	gobottest.Assert(t, ok, true)
+}
+
+func TestI2CDriverRegistryAllDrivers(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver gobot.Driver
+	}{
+		{"ADS1015", (*ADS1x15Driver)(nil)},
+		{"ADS1115", (*ADS1x15Driver)(nil)},
+		{"ADXL345", (*ADXL345Driver)(nil)},
+		{"AM2320", (*AM2320Driver)(nil)},
+		{"AdafruitMotorHat", (*AdafruitMotorHatDriver)(nil)},
+		{"BH1750", (*BH1750Driver)(nil)},
+		{"BME280", (*BME280Driver)(nil)},
+		{"BMP180", (*BMP180Driver)(nil)},
+		{"BMP280", (*BMP280Driver)(nil)},
+		{"BlinkM", (*BlinkMDriver)(nil)},
+		{"CCS811", (*CCS811Driver)(nil)},
+		{"DRV2605L", (*DRV2605LDriver)(nil)},
+		{"GroveAccelerometer", (*GroveAccelerometerDriver)(nil)},
+		{"GroveLcd", (*GroveLcdDriver)(nil)},
+		{"GrovePi", (*GrovePiDriver)(nil)},
+		{"HDC1080", (*HDC1080Driver)(nil)},
+		{"HMC6352", (*HMC6352Driver)(nil)},
+		{"HTU21D", (*HTU21DDriver)(nil)},
+		{"INA219", (*INA219Driver)(nil)},
+		{"INA3221", (*INA3221Driver)(nil)},
+		{"JHD1313M1", (*JHD1313M1Driver)(nil)},
+		{"L3GD20H", (*L3GD20HDriver)(nil)},
+		{"LIDARLite", (*LIDARLiteDriver)(nil)},
+		{"LIS3DH", (*LIS3DHDriver)(nil)},
+		{"LPS25H", (*LPS25HDriver)(nil)},
+		{"MCP23017", (*MCP23017Driver)(nil)},
+		{"MCP9808", (*MCP9808Driver)(nil)},
+		{"MMA7660", (*MMA7660Driver)(nil)},
+		{"MPL115A2", (*MPL115A2Driver)(nil)},
+		{"MPL3115A2", (*MPL3115A2Driver)(nil)},
+		{"MPU6050", (*MPU6050Driver)(nil)},
+		{"MS5611", (*MS5611Driver)(nil)},
+		{"PCA9685", (*PCA9685Driver)(nil)},
+		{"SHT3x", (*SHT3xDriver)(nil)},
+		{"SSD1306", (*SSD1306Driver)(nil)},
+		{"TCA9548A", (*TCA9548ADriver)(nil)},
+		{"TMP102", (*TMP102Driver)(nil)},
+		{"TSL2561", (*TSL2561Driver)(nil)},
+		{"VL53L0X", (*VL53L0XDriver)(nil)},
+		{"Wiichuck", (*WiichuckDriver)(nil)},
+	}
+	for _, tt := range tests {
+		d, err := NewI2CDriverByName(tt.name, newI2cTestAdaptor())
+		gobottest.Assert(t, err, nil)
+		if reflect.TypeOf(d) != reflect.TypeOf(tt.driver) {
+			t.Errorf("NewI2CDriverByName(%q) = %T, want %T", tt.name, d, tt.driver)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package i2c
 
 import (
+	"encoding/binary"
 	"errors"
 	"testing"
 
@@ -173,3 +174,69 @@ func TestI2CWriteBlockDataAddressError(t *testing.T) {
 	err := c.WriteBlockData(0x01, []byte{0x01, 0x02})
 	gobottest.Assert(t, err, errors.New("Setting address failed with syscall.Errno operation not permitted"))
 }
+
+func TestI2CReadWord(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x12, 0x34})
+		return 2, nil
+	}
+	v, err := ReadWord(adaptor, 0x01, binary.BigEndian)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, v, uint16(0x1234))
+	gobottest.Assert(t, adaptor.written, []byte{0x01})
+
+	v, err = ReadWord(adaptor, 0x01, binary.LittleEndian)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, v, uint16(0x3412))
+}
+
+func TestI2CReadWordError(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	_, err := ReadWord(adaptor, 0x01, binary.BigEndian)
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = ReadWord(adaptor, 0x01, binary.BigEndian)
+	gobottest.Assert(t, err, errors.New("read error"))
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	_, err = ReadWord(adaptor, 0x01, binary.BigEndian)
+	gobottest.Assert(t, err, errors.New("write error"))
+}
+
+func TestI2CWriteWord(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	err := WriteWord(adaptor, 0x01, 0x1234, binary.BigEndian)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x01, 0x12, 0x34})
+
+	adaptor.written = []byte{}
+	err = WriteWord(adaptor, 0x01, 0x1234, binary.LittleEndian)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x01, 0x34, 0x12})
+}
+
+func TestI2CScanBus(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		if adaptor.address != 0x77 {
+			return 0, errors.New("no device")
+		}
+		return len(b), nil
+	}
+	addresses, err := ScanBus(adaptor, 1)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, addresses, []int{0x77})
+}
+
+func TestI2CScanBusConnectError(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	_, err := ScanBus(adaptor, 1)
+	gobottest.Assert(t, err, errors.New("Invalid i2c connection"))
+}
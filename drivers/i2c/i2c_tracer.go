@@ -0,0 +1,137 @@
+package i2c
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// NewTracingConnector returns a Connector which writes every transaction of
+// its connections to w, with the bus, the address and the bytes in hex, and
+// then delegates it to the inner Connector. It helps debugging a board or a
+// driver, without changing either: the drivers only need to be created with
+// the returned Connector instead of the adaptor. A transaction is written as:
+//		i2c 1/0x77 write F4 2E
+//		i2c 1/0x77 read 6C FA
+//
+// Such a trace of a session can be replayed in a test with the
+// i2ctest.ReplayAdaptor.
+func NewTracingConnector(inner Connector, w io.Writer) Connector {
+	return &tracingConnector{inner: inner, w: w, mutex: &sync.Mutex{}}
+}
+
+type tracingConnector struct {
+	inner Connector
+	w     io.Writer
+	mutex *sync.Mutex
+}
+
+func (c *tracingConnector) GetConnection(address int, bus int) (Connection, error) {
+	conn, err := c.inner.GetConnection(address, bus)
+	if err != nil {
+		return nil, err
+	}
+	return &tracingConnection{conn: conn, tracer: c, address: address, bus: bus}, nil
+}
+
+func (c *tracingConnector) GetDefaultBus() int {
+	return c.inner.GetDefaultBus()
+}
+
+// tracingConnection is a Connection which traces its transactions.
+type tracingConnection struct {
+	conn    Connection
+	tracer  *tracingConnector
+	address int
+	bus     int
+}
+
+// trace writes a transaction to the writer; the data of a failed read is
+// not written.
+func (c *tracingConnection) trace(op string, write bool, data []byte, err error) {
+	c.tracer.mutex.Lock()
+	defer c.tracer.mutex.Unlock()
+
+	line := fmt.Sprintf("i2c %d/0x%02X %s", c.bus, c.address, op)
+	if len(data) > 0 && (err == nil || write) {
+		line += fmt.Sprintf(" % X", data)
+	}
+	if err != nil {
+		line += fmt.Sprintf(" error: %v", err)
+	}
+	fmt.Fprintln(c.tracer.w, line)
+}
+
+// Read data from the device.
+func (c *tracingConnection) Read(data []byte) (read int, err error) {
+	read, err = c.conn.Read(data)
+	if read >= 0 && read <= len(data) {
+		c.trace("read", false, data[:read], err)
+	} else {
+		c.trace("read", false, nil, err)
+	}
+	return
+}
+
+// Write data to the device.
+func (c *tracingConnection) Write(data []byte) (written int, err error) {
+	written, err = c.conn.Write(data)
+	c.trace("write", true, data, err)
+	return
+}
+
+// Close the connection to the device.
+func (c *tracingConnection) Close() error {
+	err := c.conn.Close()
+	c.trace("close", false, nil, err)
+	return err
+}
+
+// ReadByte reads a single byte from the device.
+func (c *tracingConnection) ReadByte() (val byte, err error) {
+	val, err = c.conn.ReadByte()
+	c.trace("read byte", false, []byte{val}, err)
+	return
+}
+
+// ReadByteData reads a byte value for a register on the device.
+func (c *tracingConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	val, err = c.conn.ReadByteData(reg)
+	c.trace(fmt.Sprintf("read byte data 0x%02X", reg), false, []byte{val}, err)
+	return
+}
+
+// ReadWordData reads a word value for a register on the device.
+func (c *tracingConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	val, err = c.conn.ReadWordData(reg)
+	c.trace(fmt.Sprintf("read word data 0x%02X 0x%04X", reg, val), false, nil, err)
+	return
+}
+
+// WriteByte writes a single byte to the device.
+func (c *tracingConnection) WriteByte(val byte) error {
+	err := c.conn.WriteByte(val)
+	c.trace("write byte", true, []byte{val}, err)
+	return err
+}
+
+// WriteByteData writes a byte value to a register on the device.
+func (c *tracingConnection) WriteByteData(reg uint8, val uint8) error {
+	err := c.conn.WriteByteData(reg, val)
+	c.trace(fmt.Sprintf("write byte data 0x%02X", reg), true, []byte{val}, err)
+	return err
+}
+
+// WriteWordData writes a word value to a register on the device.
+func (c *tracingConnection) WriteWordData(reg uint8, val uint16) error {
+	err := c.conn.WriteWordData(reg, val)
+	c.trace(fmt.Sprintf("write word data 0x%02X 0x%04X", reg, val), true, nil, err)
+	return err
+}
+
+// WriteBlockData writes a block of bytes to a register on the device.
+func (c *tracingConnection) WriteBlockData(reg uint8, b []byte) error {
+	err := c.conn.WriteBlockData(reg, b)
+	c.trace(fmt.Sprintf("write block data 0x%02X", reg), true, b, err)
+	return err
+}
@@ -0,0 +1,87 @@
+package i2c_test
+
+import (
+	"bytes"
+	"testing"
+
+	"gobot.io/x/gobot/drivers/i2c"
+	"gobot.io/x/gobot/drivers/i2c/i2ctest"
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestTracingConnectorBMP180Temperature(t *testing.T) {
+	bus := newBMP180TestBus()
+	trace := &bytes.Buffer{}
+	bmp180 := i2c.NewBMP180Driver(i2c.NewTracingConnector(bus, trace))
+	gobottest.Assert(t, bmp180.Start(), nil)
+
+	trace.Reset()
+	temp, err := bmp180.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15))
+	gobottest.Assert(t, trace.String(),
+		"i2c 0/0x77 write F4 2E\n"+
+			"i2c 0/0x77 write F6\n"+
+			"i2c 0/0x77 read 6C FA\n")
+}
+
+func TestTracingConnectorErrors(t *testing.T) {
+	bus := i2ctest.NewAdaptor()
+	trace := &bytes.Buffer{}
+	conn, err := i2c.NewTracingConnector(bus, trace).GetConnection(0x10, 1)
+	gobottest.Assert(t, err, nil)
+
+	bus.FailNextRead(1)
+	_, err = conn.ReadByteData(0x01)
+	gobottest.Assert(t, err, i2ctest.ErrInjected)
+	gobottest.Assert(t, conn.WriteWordData(0x02, 0x1234), nil)
+	gobottest.Assert(t, conn.Close(), nil)
+	gobottest.Assert(t, trace.String(),
+		"i2c 1/0x10 read byte data 0x01 error: Injected i2c fault\n"+
+			"i2c 1/0x10 write word data 0x02 0x1234\n"+
+			"i2c 1/0x10 close\n")
+}
+
+func TestTracingConnectorReplay(t *testing.T) {
+	// record a session where the pressure jumps on the second reading.
+	bus := newBMP180TestBus()
+	pressures := 0
+	bus.OnWrite(func(address int, data []byte) {
+		if len(data) != 2 || data[0] != 0xF4 {
+			return
+		}
+		switch data[1] {
+		case 0x2E:
+			bus.SetRegisters(address, 0xF6, 0x6C, 0xFA)
+		case 0x34:
+			pressures++
+			bus.SetRegisters(address, 0xF6, 0x5D, 0x23+byte(pressures), 0x00)
+		}
+	})
+	recording := &bytes.Buffer{}
+	bmp180 := i2c.NewBMP180Driver(i2c.NewTracingConnector(bus, recording))
+	gobottest.Assert(t, bmp180.Start(), nil)
+	recorded := []float32{}
+	for i := 0; i < 3; i++ {
+		pressure, err := bmp180.Pressure()
+		gobottest.Assert(t, err, nil)
+		recorded = append(recorded, pressure)
+	}
+	bus.FailNextRead(1)
+	_, err := bmp180.Temperature()
+	gobottest.Assert(t, err, i2ctest.ErrInjected)
+
+	// replay the session with another driver.
+	replay, err := i2ctest.NewReplayAdaptor(recording)
+	gobottest.Assert(t, err, nil)
+	bmp180 = i2c.NewBMP180Driver(replay)
+	gobottest.Assert(t, bmp180.Start(), nil)
+	for i := 0; i < 3; i++ {
+		pressure, err := bmp180.Pressure()
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, pressure, recorded[i])
+	}
+	_, err = bmp180.Temperature()
+	gobottest.Assert(t, err.Error(), i2ctest.ErrInjected.Error())
+	gobottest.Assert(t, replay.Remaining(), 0)
+}
@@ -0,0 +1,295 @@
+// Package i2ctest provides a simulated i2c bus, for testing the i2c drivers
+// against bus faults.
+package i2ctest
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+var _ gobot.Adaptor = (*Adaptor)(nil)
+var _ i2c.Connector = (*Adaptor)(nil)
+var _ i2c.Connection = (*connection)(nil)
+
+// ErrInjected is returned by the transactions failed by FailNextRead and
+// FailNextWrite.
+var ErrInjected = errors.New("Injected i2c fault")
+
+// Transaction is an i2c transaction on the simulated bus.
+type Transaction struct {
+	Address int
+	Write   bool
+	Data    []byte
+	Err     error
+}
+
+// Adaptor is an i2c Connector to a simulated bus. Each device on the bus has
+// 256 registers: a write sets the register pointer to its first byte, and
+// stores the following bytes from that register on; a read returns the bytes
+// from the register pointer on. The pointer increments with each byte.
+//
+// Transactions can be scripted to fail, to return corrupt data or to be
+// delayed, and are all recorded.
+type Adaptor struct {
+	name         string
+	mtx          sync.Mutex
+	registers    map[int]*[256]byte
+	pointers     map[int]byte
+	onWrite      func(address int, data []byte)
+	failReads    int
+	failWrites   int
+	corruptReads int
+	delay        time.Duration
+	transactions []Transaction
+}
+
+// NewAdaptor returns a new Adaptor, with no device on its bus.
+func NewAdaptor() *Adaptor {
+	return &Adaptor{
+		name:      "i2ctest",
+		registers: make(map[int]*[256]byte),
+		pointers:  make(map[int]byte),
+	}
+}
+
+// Name returns the name of the adaptor.
+func (a *Adaptor) Name() string { return a.name }
+
+// SetName sets the name of the adaptor.
+func (a *Adaptor) SetName(n string) { a.name = n }
+
+// Connect does nothing.
+func (a *Adaptor) Connect() (err error) { return }
+
+// Finalize does nothing.
+func (a *Adaptor) Finalize() (err error) { return }
+
+// GetConnection returns a connection to the device at the address. The bus
+// is ignored.
+func (a *Adaptor) GetConnection(address int, bus int) (i2c.Connection, error) {
+	return &connection{adaptor: a, address: address}, nil
+}
+
+// GetDefaultBus returns the default bus, 0.
+func (a *Adaptor) GetDefaultBus() int {
+	return 0
+}
+
+// SetRegisters sets the registers of the device at the address, from reg on.
+func (a *Adaptor) SetRegisters(address int, reg byte, data ...byte) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	registers := a.device(address)
+	for _, b := range data {
+		registers[reg] = b
+		reg++
+	}
+}
+
+// Registers returns n registers of the device at the address, from reg on.
+func (a *Adaptor) Registers(address int, reg byte, n int) []byte {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	registers := a.device(address)
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = registers[reg]
+		reg++
+	}
+	return data
+}
+
+// OnWrite sets a function called after each successful write, e.g. to
+// simulate a conversion started by a command with SetRegisters.
+func (a *Adaptor) OnWrite(f func(address int, data []byte)) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.onWrite = f
+}
+
+// FailNextRead makes the next n reads fail with ErrInjected.
+func (a *Adaptor) FailNextRead(n int) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.failReads = n
+}
+
+// FailNextWrite makes the next n writes fail with ErrInjected.
+func (a *Adaptor) FailNextWrite(n int) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.failWrites = n
+}
+
+// CorruptNextRead makes the next read return its data with all the bits
+// inverted.
+func (a *Adaptor) CorruptNextRead() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.corruptReads++
+}
+
+// SetDelay sets how long each transaction takes.
+func (a *Adaptor) SetDelay(delay time.Duration) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.delay = delay
+}
+
+// Transactions returns the transactions since the adaptor was created or
+// since the last ResetTransactions.
+func (a *Adaptor) Transactions() []Transaction {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return append([]Transaction{}, a.transactions...)
+}
+
+// ResetTransactions clears the recorded transactions.
+func (a *Adaptor) ResetTransactions() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.transactions = nil
+}
+
+func (a *Adaptor) device(address int) *[256]byte {
+	registers, ok := a.registers[address]
+	if !ok {
+		registers = &[256]byte{}
+		a.registers[address] = registers
+	}
+	return registers
+}
+
+func (a *Adaptor) read(address int, b []byte) (int, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	time.Sleep(a.delay)
+	if a.failReads > 0 {
+		a.failReads--
+		a.transactions = append(a.transactions, Transaction{Address: address, Err: ErrInjected})
+		return 0, ErrInjected
+	}
+	registers := a.device(address)
+	reg := a.pointers[address]
+	for i := range b {
+		b[i] = registers[reg]
+		reg++
+	}
+	a.pointers[address] = reg
+	if a.corruptReads > 0 {
+		a.corruptReads--
+		for i := range b {
+			b[i] = ^b[i]
+		}
+	}
+	a.transactions = append(a.transactions, Transaction{Address: address, Data: append([]byte{}, b...)})
+	return len(b), nil
+}
+
+func (a *Adaptor) write(address int, data []byte) (int, error) {
+	a.mtx.Lock()
+	time.Sleep(a.delay)
+	if a.failWrites > 0 {
+		a.failWrites--
+		a.transactions = append(a.transactions, Transaction{Address: address, Write: true, Data: append([]byte{}, data...), Err: ErrInjected})
+		a.mtx.Unlock()
+		return 0, ErrInjected
+	}
+	if len(data) > 0 {
+		registers := a.device(address)
+		reg := data[0]
+		a.pointers[address] = reg
+		for _, b := range data[1:] {
+			registers[reg] = b
+			reg++
+		}
+	}
+	a.transactions = append(a.transactions, Transaction{Address: address, Write: true, Data: append([]byte{}, data...)})
+	onWrite := a.onWrite
+	a.mtx.Unlock()
+
+	if onWrite != nil {
+		onWrite(address, data)
+	}
+	return len(data), nil
+}
+
+// connection is a connection to a device on the simulated bus.
+type connection struct {
+	adaptor *Adaptor
+	address int
+}
+
+func (c *connection) Read(b []byte) (int, error) {
+	return c.adaptor.read(c.address, b)
+}
+
+func (c *connection) Write(data []byte) (int, error) {
+	return c.adaptor.write(c.address, data)
+}
+
+func (c *connection) Close() error {
+	return nil
+}
+
+func (c *connection) ReadByte() (byte, error) {
+	b := []byte{0}
+	if _, err := c.Read(b); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (c *connection) ReadByteData(reg uint8) (uint8, error) {
+	if _, err := c.Write([]byte{reg}); err != nil {
+		return 0, err
+	}
+	return c.ReadByte()
+}
+
+// ReadWordData reads a little-endian SMBus word.
+func (c *connection) ReadWordData(reg uint8) (uint16, error) {
+	if _, err := c.Write([]byte{reg}); err != nil {
+		return 0, err
+	}
+	b := []byte{0, 0}
+	if _, err := c.Read(b); err != nil {
+		return 0, err
+	}
+	return uint16(b[1])<<8 | uint16(b[0]), nil
+}
+
+func (c *connection) WriteByte(val byte) error {
+	_, err := c.Write([]byte{val})
+	return err
+}
+
+func (c *connection) WriteByteData(reg uint8, val uint8) error {
+	_, err := c.Write([]byte{reg, val})
+	return err
+}
+
+// WriteWordData writes a little-endian SMBus word.
+func (c *connection) WriteWordData(reg uint8, val uint16) error {
+	_, err := c.Write([]byte{reg, byte(val), byte(val >> 8)})
+	return err
+}
+
+func (c *connection) WriteBlockData(reg uint8, b []byte) error {
+	_, err := c.Write(append([]byte{reg}, b...))
+	return err
+}
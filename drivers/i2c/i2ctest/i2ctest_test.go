@@ -0,0 +1,94 @@
+package i2ctest
+
+import (
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestAdaptorRegisters(t *testing.T) {
+	a := NewAdaptor()
+	a.SetRegisters(0x10, 0x20, 0x01, 0x02, 0x03)
+	conn, err := a.GetConnection(0x10, a.GetDefaultBus())
+	gobottest.Assert(t, err, nil)
+
+	b := make([]byte, 3)
+	conn.Write([]byte{0x20})
+	n, err := conn.Read(b)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, 3)
+	gobottest.Assert(t, b, []byte{0x01, 0x02, 0x03})
+
+	conn.Write([]byte{0x21, 0xAA, 0xBB})
+	gobottest.Assert(t, a.Registers(0x10, 0x20, 3), []byte{0x01, 0xAA, 0xBB})
+	// the devices do not share their registers.
+	gobottest.Assert(t, a.Registers(0x11, 0x20, 3), []byte{0x00, 0x00, 0x00})
+
+	val, err := conn.ReadWordData(0x21)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, uint16(0xBBAA))
+	gobottest.Assert(t, conn.WriteWordData(0x21, 0x1234), nil)
+	gobottest.Assert(t, a.Registers(0x10, 0x21, 2), []byte{0x34, 0x12})
+	gobottest.Assert(t, conn.WriteByteData(0x22, 0x56), nil)
+	v, err := conn.ReadByteData(0x22)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, v, uint8(0x56))
+}
+
+func TestAdaptorFaults(t *testing.T) {
+	a := NewAdaptor()
+	a.SetRegisters(0x10, 0x00, 0x0F)
+	conn, _ := a.GetConnection(0x10, 0)
+
+	a.FailNextWrite(1)
+	_, err := conn.Write([]byte{0x00})
+	gobottest.Assert(t, err, ErrInjected)
+	_, err = conn.Write([]byte{0x00})
+	gobottest.Assert(t, err, nil)
+
+	a.FailNextRead(2)
+	_, err = conn.ReadByte()
+	gobottest.Assert(t, err, ErrInjected)
+	_, err = conn.ReadByte()
+	gobottest.Assert(t, err, ErrInjected)
+
+	a.CorruptNextRead()
+	conn.Write([]byte{0x00})
+	val, err := conn.ReadByte()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, byte(0xF0))
+	conn.Write([]byte{0x00})
+	val, _ = conn.ReadByte()
+	gobottest.Assert(t, val, byte(0x0F))
+
+	a.SetDelay(10 * time.Millisecond)
+	start := time.Now()
+	conn.ReadByte()
+	gobottest.Assert(t, time.Since(start) >= 10*time.Millisecond, true)
+}
+
+func TestAdaptorTransactions(t *testing.T) {
+	a := NewAdaptor()
+	conn, _ := a.GetConnection(0x10, 0)
+	written := []byte{}
+	a.OnWrite(func(address int, data []byte) {
+		written = append(written, data...)
+		// simulate a measurement
+		a.SetRegisters(address, 0x01, 0x42)
+	})
+
+	a.FailNextRead(1)
+	conn.ReadByteData(0x01)
+	conn.ReadByteData(0x01)
+	gobottest.Assert(t, written, []byte{0x01, 0x01})
+	gobottest.Assert(t, a.Transactions(), []Transaction{
+		{Address: 0x10, Write: true, Data: []byte{0x01}},
+		{Address: 0x10, Err: ErrInjected},
+		{Address: 0x10, Write: true, Data: []byte{0x01}},
+		{Address: 0x10, Data: []byte{0x42}},
+	})
+
+	a.ResetTransactions()
+	gobottest.Assert(t, len(a.Transactions()), 0)
+}
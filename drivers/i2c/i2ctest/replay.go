@@ -0,0 +1,221 @@
+package i2ctest
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+var _ gobot.Adaptor = (*ReplayAdaptor)(nil)
+var _ i2c.Connector = (*ReplayAdaptor)(nil)
+var _ i2c.Connection = (*replayConnection)(nil)
+
+// ErrReplayMismatch is returned by the transactions which differ from the
+// next transaction of the recording.
+var ErrReplayMismatch = errors.New("Transaction does not match the recording")
+
+// ErrReplayEnd is returned by the transactions after the end of the
+// recording.
+var ErrReplayEnd = errors.New("End of the recording")
+
+const replayErrorSep = " error: "
+
+// ReplayAdaptor is an i2c Connector replaying a recording of the
+// transactions of a real session, as written by i2c.NewTracingConnector:
+// the reads return the recorded data and errors, in the recorded order, so
+// that the readings of a session in the field can be reproduced in a test.
+// The transactions must be the recorded ones, or ErrReplayMismatch is
+// returned.
+type ReplayAdaptor struct {
+	name         string
+	mtx          sync.Mutex
+	transactions []string
+	next         int
+}
+
+// NewReplayAdaptor returns a new ReplayAdaptor replaying the recording read
+// from r.
+func NewReplayAdaptor(r io.Reader) (*ReplayAdaptor, error) {
+	a := &ReplayAdaptor{name: "i2ctest replay"}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			a.transactions = append(a.transactions, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Name returns the name of the adaptor.
+func (a *ReplayAdaptor) Name() string { return a.name }
+
+// SetName sets the name of the adaptor.
+func (a *ReplayAdaptor) SetName(n string) { a.name = n }
+
+// Connect does nothing.
+func (a *ReplayAdaptor) Connect() (err error) { return }
+
+// Finalize does nothing.
+func (a *ReplayAdaptor) Finalize() (err error) { return }
+
+// GetConnection returns a connection to the device at the address on the
+// bus.
+func (a *ReplayAdaptor) GetConnection(address int, bus int) (i2c.Connection, error) {
+	return &replayConnection{adaptor: a, address: address, bus: bus}, nil
+}
+
+// GetDefaultBus returns the default bus, 0.
+func (a *ReplayAdaptor) GetDefaultBus() int {
+	return 0
+}
+
+// Remaining returns the number of transactions of the recording not
+// replayed yet.
+func (a *ReplayAdaptor) Remaining() int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return len(a.transactions) - a.next
+}
+
+// replay checks that the next transaction of the recording is the
+// transaction, or only starts with it for a read, and returns the rest of it
+// with its recorded error.
+func (a *ReplayAdaptor) replay(transaction string, read bool) (rest string, err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.next >= len(a.transactions) {
+		return "", ErrReplayEnd
+	}
+	recorded := a.transactions[a.next]
+	if i := strings.Index(recorded, replayErrorSep); i >= 0 {
+		err = errors.New(recorded[i+len(replayErrorSep):])
+		recorded = recorded[:i]
+	}
+	if recorded != transaction && !(read && strings.HasPrefix(recorded, transaction+" ")) {
+		return "", ErrReplayMismatch
+	}
+	rest = strings.TrimSpace(recorded[len(transaction):])
+	// the rest of a read is its data, not the rest of another operation.
+	for _, field := range strings.Fields(rest) {
+		if _, perr := strconv.ParseUint(strings.TrimPrefix(field, "0x"), 16, 16); perr != nil {
+			return "", ErrReplayMismatch
+		}
+	}
+	a.next++
+	return rest, err
+}
+
+// replayConnection is a connection to a device of the recording.
+type replayConnection struct {
+	adaptor *ReplayAdaptor
+	address int
+	bus     int
+}
+
+func (c *replayConnection) replay(op string, read bool) (string, error) {
+	return c.adaptor.replay(fmt.Sprintf("i2c %d/0x%02X %s", c.bus, c.address, op), read)
+}
+
+// replayRead replays a read, and returns its recorded data.
+func (c *replayConnection) replayRead(op string) ([]byte, error) {
+	rest, err := c.replay(op, true)
+	if err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(strings.Replace(rest, " ", "", -1))
+	if err != nil {
+		return nil, ErrReplayMismatch
+	}
+	return data, nil
+}
+
+// replayWrite replays a write of the data.
+func (c *replayConnection) replayWrite(op string, data []byte) error {
+	if len(data) > 0 {
+		op += fmt.Sprintf(" % X", data)
+	}
+	_, err := c.replay(op, false)
+	return err
+}
+
+func (c *replayConnection) Read(b []byte) (int, error) {
+	data, err := c.replayRead("read")
+	if err != nil {
+		return 0, err
+	}
+	return copy(b, data), nil
+}
+
+func (c *replayConnection) Write(data []byte) (int, error) {
+	if err := c.replayWrite("write", data); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (c *replayConnection) Close() error {
+	return c.replayWrite("close", nil)
+}
+
+func (c *replayConnection) ReadByte() (byte, error) {
+	data, err := c.replayRead("read byte")
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 {
+		return 0, ErrReplayMismatch
+	}
+	return data[0], nil
+}
+
+func (c *replayConnection) ReadByteData(reg uint8) (uint8, error) {
+	data, err := c.replayRead(fmt.Sprintf("read byte data 0x%02X", reg))
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 1 {
+		return 0, ErrReplayMismatch
+	}
+	return data[0], nil
+}
+
+func (c *replayConnection) ReadWordData(reg uint8) (uint16, error) {
+	rest, err := c.replay(fmt.Sprintf("read word data 0x%02X", reg), true)
+	if err != nil {
+		return 0, err
+	}
+	val, err := strconv.ParseUint(rest, 0, 16)
+	if err != nil {
+		return 0, ErrReplayMismatch
+	}
+	return uint16(val), nil
+}
+
+func (c *replayConnection) WriteByte(val byte) error {
+	return c.replayWrite("write byte", []byte{val})
+}
+
+func (c *replayConnection) WriteByteData(reg uint8, val uint8) error {
+	return c.replayWrite(fmt.Sprintf("write byte data 0x%02X", reg), []byte{val})
+}
+
+func (c *replayConnection) WriteWordData(reg uint8, val uint16) error {
+	return c.replayWrite(fmt.Sprintf("write word data 0x%02X 0x%04X", reg, val), nil)
+}
+
+func (c *replayConnection) WriteBlockData(reg uint8, b []byte) error {
+	return c.replayWrite(fmt.Sprintf("write block data 0x%02X", reg), b)
+}
@@ -0,0 +1,70 @@
+package i2ctest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+func TestReplayAdaptor(t *testing.T) {
+	a, err := NewReplayAdaptor(strings.NewReader(`
+i2c 0/0x10 write 01
+i2c 0/0x10 read 42 43
+i2c 0/0x10 read error: Remote I/O error
+i2c 0/0x10 read byte data 0x02 7F
+i2c 0/0x10 read word data 0x03 0x1234
+i2c 0/0x10 write byte data 0x04 05
+i2c 0/0x10 write word data 0x05 0xABCD
+i2c 0/0x10 close
+`))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, a.Remaining(), 8)
+	conn, _ := a.GetConnection(0x10, a.GetDefaultBus())
+
+	n, err := conn.Write([]byte{0x01})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, 1)
+	b := make([]byte, 2)
+	n, err = conn.Read(b)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, 2)
+	gobottest.Assert(t, b, []byte{0x42, 0x43})
+	_, err = conn.Read(b)
+	gobottest.Assert(t, err, errors.New("Remote I/O error"))
+	val, err := conn.ReadByteData(0x02)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, uint8(0x7F))
+	word, err := conn.ReadWordData(0x03)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, word, uint16(0x1234))
+	gobottest.Assert(t, conn.WriteByteData(0x04, 0x05), nil)
+	gobottest.Assert(t, conn.WriteWordData(0x05, 0xABCD), nil)
+	gobottest.Assert(t, conn.Close(), nil)
+
+	gobottest.Assert(t, a.Remaining(), 0)
+	_, err = conn.Read(b)
+	gobottest.Assert(t, err, ErrReplayEnd)
+}
+
+func TestReplayAdaptorMismatch(t *testing.T) {
+	a, _ := NewReplayAdaptor(strings.NewReader("i2c 0/0x10 write F4 2E\ni2c 0/0x10 read byte 01\n"))
+	conn, _ := a.GetConnection(0x10, 0)
+	other, _ := a.GetConnection(0x11, 0)
+
+	_, err := conn.Write([]byte{0xF4})
+	gobottest.Assert(t, err, ErrReplayMismatch)
+	_, err = other.Write([]byte{0xF4, 0x2E})
+	gobottest.Assert(t, err, ErrReplayMismatch)
+	gobottest.Assert(t, conn.WriteByte(0xF4), ErrReplayMismatch)
+	// a mismatch does not consume the recording.
+	_, err = conn.Write([]byte{0xF4, 0x2E})
+	gobottest.Assert(t, err, nil)
+
+	_, err = conn.Read(make([]byte, 1))
+	gobottest.Assert(t, err, ErrReplayMismatch)
+	val, err := conn.ReadByte()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, byte(0x01))
+}
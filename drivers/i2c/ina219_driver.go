@@ -0,0 +1,203 @@
+package i2c
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"gobot.io/x/gobot"
+)
+
+const (
+	ina219Address             uint8   = 0x40   // 1000000 (A0+A1=GND)
+	ina219RegConfig           uint8   = 0x00   // CONFIGURATION REGISTER (R/W)
+	ina219RegShuntVoltage     uint8   = 0x01   // SHUNT VOLTAGE REGISTER (R)
+	ina219RegBusVoltage       uint8   = 0x02   // BUS VOLTAGE REGISTER (R)
+	ina219RegPower            uint8   = 0x03   // POWER REGISTER (R)
+	ina219RegCurrent          uint8   = 0x04   // CURRENT REGISTER (R)
+	ina219RegCalibration      uint8   = 0x05   // CALIBRATION REGISTER (R/W)
+	ina219ConfigBusRange32V   uint16  = 0x2000 // Bus voltage range of 32V
+	ina219ConfigGain8         uint16  = 0x1800 // Shunt voltage range of +-320mV
+	ina219ConfigBusADC12Bit   uint16  = 0x0180 // Bus ADC resolution of 12 bits
+	ina219ConfigShuntADC12Bit uint16  = 0x0018 // Shunt ADC resolution of 12 bits
+	ina219ConfigModeShuntBus  uint16  = 0x0007 // Shunt and bus voltage, continuous
+	ina219ShuntResistorValue  float64 = 0.1    // default shunt resistor value of 0.1 Ohm
+	ina219MaxCurrentValue     float64 = 3.2    // default maximum current of 3.2A, the full range over 0.1 Ohm
+)
+
+// ErrINA219Calibration is returned by Start when the shunt resistance and
+// the maximum current do not fit in the 16 bits calibration register.
+var ErrINA219Calibration = errors.New("INA219 calibration out of range")
+
+// INA219Driver is a driver for the Texas Instruments INA219 device. The INA219 is a high-side
+// current and bus voltage monitor with an I2C and SMBUS compatible interface.
+//
+// INA219 data sheet and specifications can be found at http://www.ti.com/product/INA219
+type INA219Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	shuntResistance float64
+	maxCurrent      float64
+	currentLSB      float64
+}
+
+// NewINA219Driver creates a new driver with the specified i2c interface.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):				bus to use with this driver
+//		i2c.WithAddress(int):				address to use with this driver
+//		i2c.WithINA219ShuntResistance(float64):	shunt resistance in Ohm, defaults to 0.1
+//		i2c.WithINA219MaxCurrent(float64):		maximum expected current in A, defaults to 3.2
+func NewINA219Driver(c Connector, options ...func(Config)) *INA219Driver {
+	i := &INA219Driver{
+		name:            gobot.DefaultName("INA219"),
+		connector:       c,
+		Config:          NewConfig(),
+		shuntResistance: ina219ShuntResistorValue,
+		maxCurrent:      ina219MaxCurrentValue,
+	}
+
+	for _, option := range options {
+		option(i)
+	}
+
+	return i
+}
+
+// WithINA219ShuntResistance option sets the value of the shunt resistor, in Ohm.
+func WithINA219ShuntResistance(val float64) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*INA219Driver)
+		if ok {
+			d.shuntResistance = val
+		}
+	}
+}
+
+// WithINA219MaxCurrent option sets the maximum expected current, in A, which
+// is used to compute the calibration register.
+func WithINA219MaxCurrent(val float64) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*INA219Driver)
+		if ok {
+			d.maxCurrent = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (i *INA219Driver) Name() string {
+	return i.name
+}
+
+// SetName sets the name of the device.
+func (i *INA219Driver) SetName(name string) {
+	i.name = name
+}
+
+// Connection returns the connection of the device.
+func (i *INA219Driver) Connection() gobot.Connection {
+	return i.connector.(gobot.Connection)
+}
+
+// Start initializes the INA219
+func (i *INA219Driver) Start() error {
+	var err error
+	bus := i.GetBusOrDefault(i.connector.GetDefaultBus())
+	address := i.GetAddressOrDefault(int(ina219Address))
+
+	if i.connection, err = i.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+
+	if err := i.initialize(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Halt halts the device.
+func (i *INA219Driver) Halt() error {
+	return nil
+}
+
+// BusVoltage gets the bus voltage in Volts
+func (i *INA219Driver) BusVoltage() (float64, error) {
+	val, err := ReadWord(i.connection, ina219RegBusVoltage, binary.BigEndian)
+	if err != nil {
+		return 0, err
+	}
+
+	// the lowest 3 bits are status flags, LSB is 4mV
+	return float64(val>>3) * .004, nil
+}
+
+// ShuntVoltage gets the shunt voltage in mV
+func (i *INA219Driver) ShuntVoltage() (float64, error) {
+	val, err := ReadWord(i.connection, ina219RegShuntVoltage, binary.BigEndian)
+	if err != nil {
+		return 0, err
+	}
+
+	// LSB is 10uV
+	return float64(int16(val)) * .01, nil
+}
+
+// Current gets the current in mA
+func (i *INA219Driver) Current() (float64, error) {
+	val, err := ReadWord(i.connection, ina219RegCurrent, binary.BigEndian)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(int16(val)) * i.currentLSB * 1000, nil
+}
+
+// Power gets the power in mW
+func (i *INA219Driver) Power() (float64, error) {
+	val, err := ReadWord(i.connection, ina219RegPower, binary.BigEndian)
+	if err != nil {
+		return 0, err
+	}
+
+	// power LSB is 20 times the current LSB
+	return float64(val) * 20 * i.currentLSB * 1000, nil
+}
+
+// calibration returns the calibration register value for the given current
+// LSB and the configured shunt resistance, see datasheet, or
+// ErrINA219Calibration when it does not fit in the register.
+func (i *INA219Driver) calibration(currentLSB float64) (uint16, error) {
+	cal := 0.04096 / (currentLSB * i.shuntResistance)
+	// also false for NaN
+	if !(cal >= 2 && cal <= 0xFFFF) {
+		return 0, ErrINA219Calibration
+	}
+	return uint16(cal) &^ 1, nil
+}
+
+// initialize configures the INA219 and writes the calibration register
+func (i *INA219Driver) initialize() error {
+	config := ina219ConfigBusRange32V |
+		ina219ConfigGain8 |
+		ina219ConfigBusADC12Bit |
+		ina219ConfigShuntADC12Bit |
+		ina219ConfigModeShuntBus
+
+	if err := WriteWord(i.connection, ina219RegConfig, config, binary.BigEndian); err != nil {
+		return err
+	}
+
+	// best resolution for the maximum expected current
+	currentLSB := i.maxCurrent / 32768
+	cal, err := i.calibration(currentLSB)
+	if err != nil {
+		return err
+	}
+	i.currentLSB = currentLSB
+	return WriteWord(i.connection, ina219RegCalibration, cal, binary.BigEndian)
+}
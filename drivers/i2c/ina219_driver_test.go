@@ -0,0 +1,200 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*INA219Driver)(nil)
+
+func initTestINA219Driver() *INA219Driver {
+	d, _ := initTestINA219DriverWithStubbedAdaptor()
+	return d
+}
+
+func initTestINA219DriverWithStubbedAdaptor() (*INA219Driver, *i2cTestAdaptor) {
+	a := newI2cTestAdaptor()
+	return NewINA219Driver(a), a
+}
+
+func TestNewINA219Driver(t *testing.T) {
+	var d interface{} = NewINA219Driver(newI2cTestAdaptor())
+	if _, ok := d.(*INA219Driver); !ok {
+		t.Error("NewINA219Driver() should return a *INA219Driver")
+	}
+}
+
+func TestINA219Driver_Connection(t *testing.T) {
+	d := initTestINA219Driver()
+	gobottest.Refute(t, d.Connection(), nil)
+}
+
+func TestINA219Driver_Start(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+	// config, then calibration for 3.2A over 0.1 Ohm
+	gobottest.Assert(t, a.written, []byte{0x00, 0x39, 0x9F, 0x05, 0x10, 0x62})
+}
+
+func TestINA219Driver_StartCalibration(t *testing.T) {
+	// datasheet example: 100uA current LSB over 0.1 Ohm
+	a := newI2cTestAdaptor()
+	d := NewINA219Driver(a, WithINA219ShuntResistance(0.1), WithINA219MaxCurrent(3.2768))
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, a.written[3:], []byte{0x05, 0x10, 0x00})
+
+	a = newI2cTestAdaptor()
+	d = NewINA219Driver(a, WithINA219ShuntResistance(0.01), WithINA219MaxCurrent(10))
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, a.written[3:], []byte{0x05, 0x34, 0x6C})
+}
+
+func TestINA219Driver_StartCalibrationOutOfRange(t *testing.T) {
+	// 0.04096/(1e-9*0.001) does not fit in 16 bits
+	d := NewINA219Driver(newI2cTestAdaptor(), WithINA219ShuntResistance(0.001), WithINA219MaxCurrent(0.0000328))
+	gobottest.Assert(t, d.Start(), ErrINA219Calibration)
+
+	d = NewINA219Driver(newI2cTestAdaptor(), WithINA219ShuntResistance(100), WithINA219MaxCurrent(100))
+	gobottest.Assert(t, d.Start(), ErrINA219Calibration)
+
+	d = NewINA219Driver(newI2cTestAdaptor(), WithINA219ShuntResistance(0))
+	gobottest.Assert(t, d.Start(), ErrINA219Calibration)
+}
+
+func TestINA219Driver_ConnectError(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	a.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestINA219Driver_StartWriteError(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	a.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, d.Start(), errors.New("write error"))
+}
+
+func TestINA219Driver_Halt(t *testing.T) {
+	d := initTestINA219Driver()
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestINA219Driver_SetName(t *testing.T) {
+	d := initTestINA219Driver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, d.Name(), "TESTME")
+}
+
+func TestINA219DriverBusVoltage(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		// 12V battery, conversion ready flag set
+		copy(b, []byte{0x5D, 0xC2})
+		return 2, nil
+	}
+
+	v, err := d.BusVoltage()
+	gobottest.Assert(t, v, float64(12.0))
+	gobottest.Assert(t, err, nil)
+}
+
+func TestINA219DriverBusVoltageReadError(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+
+	_, err := d.BusVoltage()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestINA219DriverShuntVoltage(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		// -3.2mV, reverse current
+		copy(b, []byte{0xFE, 0xC0})
+		return 2, nil
+	}
+
+	v, err := d.ShuntVoltage()
+	gobottest.Assert(t, v, float64(-3.2))
+	gobottest.Assert(t, err, nil)
+}
+
+func TestINA219DriverShuntVoltageReadError(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+
+	_, err := d.ShuntVoltage()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestINA219DriverCurrent(t *testing.T) {
+	a := newI2cTestAdaptor()
+	d := NewINA219Driver(a, WithINA219MaxCurrent(3.2768))
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		// 5000 * 100uA
+		copy(b, []byte{0x13, 0x88})
+		return 2, nil
+	}
+
+	v, err := d.Current()
+	gobottest.Assert(t, v, float64(500))
+	gobottest.Assert(t, err, nil)
+}
+
+func TestINA219DriverCurrentReadError(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+
+	_, err := d.Current()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestINA219DriverPower(t *testing.T) {
+	a := newI2cTestAdaptor()
+	d := NewINA219Driver(a, WithINA219MaxCurrent(3.2768))
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		// 3000 * 2mW
+		copy(b, []byte{0x0B, 0xB8})
+		return 2, nil
+	}
+
+	v, err := d.Power()
+	gobottest.Assert(t, v, float64(6000))
+	gobottest.Assert(t, err, nil)
+}
+
+func TestINA219DriverPowerReadError(t *testing.T) {
+	d, a := initTestINA219DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.Start(), nil)
+
+	a.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+
+	_, err := d.Power()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
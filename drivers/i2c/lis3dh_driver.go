@@ -0,0 +1,410 @@
+package i2c
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// the default address, with SA0 low. It is 0x19 with SA0 high.
+const lis3dhAddress = 0x18
+
+const lis3dhRegisterWhoAmI = 0x0F
+const lis3dhWhoAmI = 0x33
+
+const lis3dhRegisterCtrl1 = 0x20
+const lis3dhRegisterCtrl4 = 0x23
+const lis3dhRegisterCtrl5 = 0x24
+const lis3dhRegisterOutXL = 0x28
+const lis3dhRegisterFifoCtrl = 0x2E
+const lis3dhRegisterFifoSrc = 0x2F
+
+// the MSB of the register address auto-increments it.
+const lis3dhAutoIncrement = 0x80
+
+// the X, Y and Z axes enabled, in the low bits of CTRL_REG1.
+const lis3dhCtrl1Axes = 0x07
+
+// block data update and high resolution, in CTRL_REG4.
+const lis3dhCtrl4BDU = 0x80
+const lis3dhCtrl4HR = 0x08
+
+const lis3dhCtrl5FifoEnable = 0x40
+const lis3dhFifoModeStream = 0x80
+
+// the samples in the FIFO, in FIFO_SRC_REG, and the overrun flag set when
+// all the 32 are unread.
+const lis3dhFifoSamples = 0x1F
+const lis3dhFifoOverrun = 0x40
+const lis3dhFifoSize = 32
+
+const (
+	// Accel event with a LIS3DHAcceleration, for each sample of the FIFO
+	Accel = "accel"
+)
+
+// ErrInvalidRange is returned when the range of the LIS3DH is set to an
+// unknown range.
+var ErrInvalidRange = errors.New("Invalid range")
+
+// ErrInvalidDataRate is returned when the data rate of the LIS3DH is set to
+// an unknown rate.
+var ErrInvalidDataRate = errors.New("Invalid data rate")
+
+// LIS3DHRange is the full scale of the LIS3DH.
+type LIS3DHRange uint8
+
+const (
+	// LIS3DHRange2G is ±2g, the default of the LIS3DH.
+	LIS3DHRange2G LIS3DHRange = iota
+	// LIS3DHRange4G is ±4g.
+	LIS3DHRange4G
+	// LIS3DHRange8G is ±8g.
+	LIS3DHRange8G
+	// LIS3DHRange16G is ±16g.
+	LIS3DHRange16G
+)
+
+// the sensitivity of the ranges in high resolution mode, in mg per digit of
+// the 12 bit values.
+var lis3dhSensitivities = [...]float32{1, 2, 4, 12}
+
+// LIS3DHDataRate is the output data rate of the LIS3DH.
+type LIS3DHDataRate uint8
+
+const (
+	// LIS3DHRate1Hz is 1 sample a second.
+	LIS3DHRate1Hz LIS3DHDataRate = iota + 1
+	// LIS3DHRate10Hz is 10 samples a second.
+	LIS3DHRate10Hz
+	// LIS3DHRate25Hz is 25 samples a second.
+	LIS3DHRate25Hz
+	// LIS3DHRate50Hz is 50 samples a second.
+	LIS3DHRate50Hz
+	// LIS3DHRate100Hz is 100 samples a second.
+	LIS3DHRate100Hz
+	// LIS3DHRate200Hz is 200 samples a second.
+	LIS3DHRate200Hz
+	// LIS3DHRate400Hz is 400 samples a second.
+	LIS3DHRate400Hz
+	// LIS3DHRate1344Hz is 1344 samples a second, the highest of the high
+	// resolution mode.
+	LIS3DHRate1344Hz LIS3DHDataRate = 9
+)
+
+// LIS3DHAcceleration is a sample of the acceleration, in g, on the 3 axes.
+type LIS3DHAcceleration struct {
+	X float32
+	Y float32
+	Z float32
+}
+
+// LIS3DHDriver is the gobot driver for the ST LIS3DH 3-axis accelerometer.
+// Device datasheet: https://www.st.com/resource/en/datasheet/lis3dh.pdf
+//
+// The accelerometer measures in high resolution, 12 bit, mode at the data
+// rate. The last sample is read on demand with XYZ, or when a poll interval
+// is set, all the samples are streamed through the 32 samples FIFO, and
+// published each in an Accel event.
+type LIS3DHDriver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	gobot.Eventer
+	sensorRange LIS3DHRange
+	dataRate    LIS3DHDataRate
+	interval    time.Duration
+	poller      poller
+	mutex       *sync.Mutex
+}
+
+// NewLIS3DHDriver creates a new driver with the i2c interface for the LIS3DH device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver, 0x19 when SA0 is high
+//		i2c.WithLIS3DHRange(LIS3DHRange):	full scale, defaults to LIS3DHRange2G
+//		i2c.WithLIS3DHDataRate(LIS3DHDataRate):	output data rate, defaults to LIS3DHRate100Hz
+//		i2c.WithLIS3DHPollInterval(time.Duration):	interval at which the FIFO is read, defaults to 0 that is no streaming
+//
+func NewLIS3DHDriver(c Connector, options ...func(Config)) *LIS3DHDriver {
+	l := &LIS3DHDriver{
+		name:        gobot.DefaultName("LIS3DH"),
+		connector:   c,
+		Config:      NewConfig(),
+		Eventer:     gobot.NewEventer(),
+		sensorRange: LIS3DHRange2G,
+		dataRate:    LIS3DHRate100Hz,
+		mutex:       &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(l)
+	}
+
+	l.AddEvent(Accel)
+	l.AddEvent(Error)
+	return l
+}
+
+// WithLIS3DHRange option sets the full scale. Unknown ranges are ignored.
+func WithLIS3DHRange(val LIS3DHRange) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*LIS3DHDriver)
+		if ok && lis3dhValidRange(val) {
+			d.sensorRange = val
+		}
+	}
+}
+
+// WithLIS3DHDataRate option sets the output data rate. Unknown rates are
+// ignored.
+func WithLIS3DHDataRate(val LIS3DHDataRate) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*LIS3DHDriver)
+		if ok && lis3dhValidDataRate(val) {
+			d.dataRate = val
+		}
+	}
+}
+
+// WithLIS3DHPollInterval option enables the streaming of the samples through
+// the FIFO, read at the interval after Start. Each sample is published in an
+// Accel event, and the read errors in Error events. The FIFO holds 32
+// samples, the interval must be short enough for the data rate not to lose
+// any.
+func WithLIS3DHPollInterval(val time.Duration) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*LIS3DHDriver)
+		if ok && val > 0 {
+			d.interval = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *LIS3DHDriver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *LIS3DHDriver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *LIS3DHDriver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start checks the identity of the device, starts its measurements, and
+// starts streaming the samples if a poll interval is set. It returns
+// ErrAlreadyStarted until Halt once started.
+func (d *LIS3DHDriver) Start() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.poller.started {
+		return ErrAlreadyStarted
+	}
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(lis3dhAddress)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return d.poller.start(d.interval, d.poll)
+}
+
+func (d *LIS3DHDriver) initialization() (err error) {
+	var id []byte
+	if id, err = d.read(lis3dhRegisterWhoAmI, 1); err != nil {
+		return err
+	}
+	if id[0] != lis3dhWhoAmI {
+		return fmt.Errorf("LIS3DH device not found (WHO_AM_I 0x%02X)", id[0])
+	}
+	if err = d.writeCtrl4(d.sensorRange); err != nil {
+		return err
+	}
+	var ctrl5, fifoCtrl byte
+	if d.interval > 0 {
+		ctrl5, fifoCtrl = lis3dhCtrl5FifoEnable, lis3dhFifoModeStream
+	}
+	if _, err = d.connection.Write([]byte{lis3dhRegisterCtrl5, ctrl5}); err != nil {
+		return err
+	}
+	if _, err = d.connection.Write([]byte{lis3dhRegisterFifoCtrl, fifoCtrl}); err != nil {
+		return err
+	}
+	return d.writeCtrl1(d.dataRate)
+}
+
+// Halt stops streaming the samples, if it was, and powers the device down.
+// The driver can then be started again.
+func (d *LIS3DHDriver) Halt() (err error) {
+	d.mutex.Lock()
+	wait := d.poller.stop()
+	d.mutex.Unlock()
+
+	// the poll locks the mutex to measure, thus is waited for without it.
+	wait()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.connection == nil {
+		return nil
+	}
+	_, err = d.connection.Write([]byte{lis3dhRegisterCtrl1, 0x00})
+	return err
+}
+
+// Range returns the full scale.
+func (d *LIS3DHDriver) Range() LIS3DHRange {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.sensorRange
+}
+
+// SetRange sets the full scale, or returns ErrInvalidRange for an unknown
+// range.
+func (d *LIS3DHDriver) SetRange(sensorRange LIS3DHRange) error {
+	if !lis3dhValidRange(sensorRange) {
+		return ErrInvalidRange
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.writeCtrl4(sensorRange); err != nil {
+		return err
+	}
+	d.sensorRange = sensorRange
+	return nil
+}
+
+// SetDataRate sets the output data rate, or returns ErrInvalidDataRate for
+// an unknown rate.
+func (d *LIS3DHDriver) SetDataRate(rate LIS3DHDataRate) error {
+	if !lis3dhValidDataRate(rate) {
+		return ErrInvalidDataRate
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.writeCtrl1(rate); err != nil {
+		return err
+	}
+	d.dataRate = rate
+	return nil
+}
+
+// XYZ returns the last sample of the acceleration, in g, on the 3 axes.
+func (d *LIS3DHDriver) XYZ() (x, y, z float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var a LIS3DHAcceleration
+	if a, err = d.sample(); err != nil {
+		return 0, 0, 0, err
+	}
+	return a.X, a.Y, a.Z, nil
+}
+
+// ReadFIFO returns the samples of the FIFO not read yet, from the oldest,
+// when streaming. Up to 32 samples are kept, the older ones are lost.
+func (d *LIS3DHDriver) ReadFIFO() (samples []LIS3DHAcceleration, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var src []byte
+	if src, err = d.read(lis3dhRegisterFifoSrc, 1); err != nil {
+		return nil, err
+	}
+	n := int(src[0] & lis3dhFifoSamples)
+	if src[0]&lis3dhFifoOverrun != 0 {
+		n = lis3dhFifoSize
+	}
+	// each read of the output registers pops a sample.
+	for i := 0; i < n; i++ {
+		var a LIS3DHAcceleration
+		if a, err = d.sample(); err != nil {
+			return nil, err
+		}
+		samples = append(samples, a)
+	}
+	return samples, nil
+}
+
+func (d *LIS3DHDriver) sample() (a LIS3DHAcceleration, err error) {
+	var data []byte
+	if data, err = d.read(lis3dhRegisterOutXL, 6); err != nil {
+		return a, err
+	}
+	a.X = lis3dhScale(int16(uint16(data[1])<<8|uint16(data[0])), d.sensorRange)
+	a.Y = lis3dhScale(int16(uint16(data[3])<<8|uint16(data[2])), d.sensorRange)
+	a.Z = lis3dhScale(int16(uint16(data[5])<<8|uint16(data[4])), d.sensorRange)
+	return a, nil
+}
+
+func (d *LIS3DHDriver) poll() {
+	samples, err := d.ReadFIFO()
+	if err != nil {
+		d.Publish(d.Event(Error), err)
+	}
+	for _, a := range samples {
+		d.Publish(d.Event(Accel), a)
+	}
+}
+
+func (d *LIS3DHDriver) writeCtrl1(rate LIS3DHDataRate) error {
+	_, err := d.connection.Write([]byte{lis3dhRegisterCtrl1, byte(rate)<<4 | lis3dhCtrl1Axes})
+	return err
+}
+
+func (d *LIS3DHDriver) writeCtrl4(sensorRange LIS3DHRange) error {
+	_, err := d.connection.Write([]byte{lis3dhRegisterCtrl4, lis3dhCtrl4BDU | byte(sensorRange)<<4 | lis3dhCtrl4HR})
+	return err
+}
+
+func (d *LIS3DHDriver) read(address byte, n int) ([]byte, error) {
+	if n > 1 {
+		address |= lis3dhAutoIncrement
+	}
+	if _, err := d.connection.Write([]byte{address}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if bytesRead != n {
+		return nil, ErrNotEnoughBytes
+	}
+	return buf, nil
+}
+
+// lis3dhScale returns the acceleration, in g, of the left-justified 12 bit
+// value of an output register.
+func lis3dhScale(raw int16, sensorRange LIS3DHRange) float32 {
+	return float32(raw>>4) * lis3dhSensitivities[sensorRange] / 1000
+}
+
+func lis3dhValidRange(sensorRange LIS3DHRange) bool {
+	return sensorRange <= LIS3DHRange16G
+}
+
+func lis3dhValidDataRate(rate LIS3DHDataRate) bool {
+	return (rate >= LIS3DHRate1Hz && rate <= LIS3DHRate400Hz) || rate == LIS3DHRate1344Hz
+}
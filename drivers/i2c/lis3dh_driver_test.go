@@ -0,0 +1,314 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*LIS3DHDriver)(nil)
+
+// --------- HELPERS
+func initTestLIS3DHDriver() (driver *LIS3DHDriver) {
+	driver, _ = initTestLIS3DHDriverWithStubbedAdaptor()
+	return
+}
+
+func initTestLIS3DHDriverWithStubbedAdaptor(options ...func(Config)) (*LIS3DHDriver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewLIS3DHDriver(adaptor, options...), adaptor
+}
+
+// lis3dhTestDevice simulates the registers of a LIS3DH, whose output
+// registers pop the samples of its FIFO, when there are any.
+type lis3dhTestDevice struct {
+	mutex     sync.Mutex
+	registers map[byte]byte
+	pointer   byte
+	output    []byte
+	fifo      [][]byte
+}
+
+func newLIS3DHTestDevice(adaptor *i2cTestAdaptor) *lis3dhTestDevice {
+	dev := &lis3dhTestDevice{
+		registers: map[byte]byte{lis3dhRegisterWhoAmI: lis3dhWhoAmI},
+		output:    make([]byte, 6),
+	}
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		dev.mutex.Lock()
+		defer dev.mutex.Unlock()
+		dev.pointer = b[0] &^ lis3dhAutoIncrement
+		if len(b) == 2 {
+			dev.registers[dev.pointer] = b[1]
+		}
+		return len(b), nil
+	}
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		dev.mutex.Lock()
+		defer dev.mutex.Unlock()
+		switch dev.pointer {
+		case lis3dhRegisterOutXL:
+			if len(dev.fifo) > 0 {
+				copy(b, dev.fifo[0])
+				dev.fifo = dev.fifo[1:]
+			} else {
+				copy(b, dev.output)
+			}
+		case lis3dhRegisterFifoSrc:
+			b[0] = byte(len(dev.fifo))
+			if len(dev.fifo) >= lis3dhFifoSize {
+				b[0] = lis3dhFifoOverrun
+			}
+		default:
+			b[0] = dev.registers[dev.pointer]
+		}
+		return len(b), nil
+	}
+	return dev
+}
+
+func (dev *lis3dhTestDevice) register(reg byte) byte {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	return dev.registers[reg]
+}
+
+func (dev *lis3dhTestDevice) push(sample []byte) {
+	dev.mutex.Lock()
+	defer dev.mutex.Unlock()
+	dev.fifo = append(dev.fifo, sample)
+}
+
+// lis3dhTestSample is the output registers of 0.992g, -0.496g and 0.25g in
+// the ±2g range, 12 bit left-justified, low bytes first.
+var lis3dhTestSample = []byte{0x00, 0x3E, 0x00, 0xE1, 0xA0, 0x0F}
+
+// --------- TESTS
+
+func TestNewLIS3DHDriver(t *testing.T) {
+	// Does it return a pointer to an instance of LIS3DHDriver?
+	var lis3dh interface{} = NewLIS3DHDriver(newI2cTestAdaptor())
+	_, ok := lis3dh.(*LIS3DHDriver)
+	if !ok {
+		t.Errorf("NewLIS3DHDriver() should have returned a *LIS3DHDriver")
+	}
+
+	d := NewLIS3DHDriver(newI2cTestAdaptor())
+	gobottest.Refute(t, d.Connection(), nil)
+	gobottest.Assert(t, d.Range(), LIS3DHRange2G)
+	gobottest.Assert(t, d.dataRate, LIS3DHRate100Hz)
+}
+
+func TestLIS3DHDriverOptions(t *testing.T) {
+	d := NewLIS3DHDriver(newI2cTestAdaptor(), WithBus(2), WithLIS3DHRange(LIS3DHRange8G),
+		WithLIS3DHDataRate(LIS3DHRate1344Hz), WithLIS3DHPollInterval(3*time.Second))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.Range(), LIS3DHRange8G)
+	gobottest.Assert(t, d.dataRate, LIS3DHRate1344Hz)
+	gobottest.Assert(t, d.interval, 3*time.Second)
+
+	// the invalid values are ignored.
+	d = NewLIS3DHDriver(newI2cTestAdaptor(), WithLIS3DHRange(LIS3DHRange(4)), WithLIS3DHDataRate(LIS3DHDataRate(8)))
+	gobottest.Assert(t, d.Range(), LIS3DHRange2G)
+	gobottest.Assert(t, d.dataRate, LIS3DHRate100Hz)
+}
+
+func TestLIS3DHDriverSetName(t *testing.T) {
+	d := initTestLIS3DHDriver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "TESTME"), true)
+}
+
+func TestLIS3DHDriverStart(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor(WithLIS3DHRange(LIS3DHRange4G))
+	dev := newLIS3DHTestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, adaptor.address, 0x18)
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl1), uint8(0x57))
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl4), uint8(0x98))
+	// no streaming without a poll interval.
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl5), uint8(0x00))
+	gobottest.Assert(t, dev.register(lis3dhRegisterFifoCtrl), uint8(0x00))
+	gobottest.Assert(t, d.Start(), ErrAlreadyStarted)
+
+	// powered down.
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl1), uint8(0x00))
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestLIS3DHDriverStartNotFound(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	dev := newLIS3DHTestDevice(adaptor)
+	dev.registers[lis3dhRegisterWhoAmI] = 0xE5
+	gobottest.Assert(t, d.Start(), errors.New("LIS3DH device not found (WHO_AM_I 0xE5)"))
+	// nothing is configured.
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl1), uint8(0x00))
+
+	dev.registers[lis3dhRegisterWhoAmI] = lis3dhWhoAmI
+	gobottest.Assert(t, d.Start(), nil)
+}
+
+func TestLIS3DHDriverStartConnectError(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestLIS3DHDriverStartReadError(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	gobottest.Assert(t, d.Start(), errors.New("read error"))
+}
+
+func TestLIS3DHScale(t *testing.T) {
+	// 1g in each range, left-justified.
+	gobottest.Assert(t, lis3dhScale(1000<<4, LIS3DHRange2G), float32(1))
+	gobottest.Assert(t, lis3dhScale(500<<4, LIS3DHRange4G), float32(1))
+	gobottest.Assert(t, lis3dhScale(250<<4, LIS3DHRange8G), float32(1))
+	gobottest.Assert(t, lis3dhScale(-250<<4, LIS3DHRange16G), float32(-3))
+	// the full scales.
+	gobottest.Assert(t, lis3dhScale(0x7FF0, LIS3DHRange2G), float32(2.047))
+	gobottest.Assert(t, lis3dhScale(-0x8000, LIS3DHRange2G), float32(-2.048))
+	gobottest.Assert(t, lis3dhScale(-0x8000, LIS3DHRange16G), float32(-24.576))
+	// the low 4 bits are unused.
+	gobottest.Assert(t, lis3dhScale(0x000F, LIS3DHRange2G), float32(0))
+}
+
+func TestLIS3DHDriverXYZ(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	dev := newLIS3DHTestDevice(adaptor)
+	copy(dev.output, lis3dhTestSample)
+	d.Start()
+	adaptor.written = []byte{}
+	x, y, z, err := d.XYZ()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, x, float32(0.992))
+	gobottest.Assert(t, y, float32(-0.496))
+	gobottest.Assert(t, z, float32(0.25))
+	// the 6 output registers are read at once.
+	gobottest.Assert(t, adaptor.written, []byte{0xA8})
+
+	gobottest.Assert(t, d.SetRange(LIS3DHRange16G), nil)
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl4), uint8(0xB8))
+	_, _, z, err = d.XYZ()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, z, float32(3))
+}
+
+func TestLIS3DHDriverXYZError(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	newLIS3DHTestDevice(adaptor)
+	d.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 2, nil
+	}
+	_, _, _, err := d.XYZ()
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+}
+
+func TestLIS3DHDriverSetRange(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	newLIS3DHTestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetRange(LIS3DHRange(4)), ErrInvalidRange)
+	gobottest.Assert(t, d.Range(), LIS3DHRange2G)
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, d.SetRange(LIS3DHRange8G), errors.New("write error"))
+	gobottest.Assert(t, d.Range(), LIS3DHRange2G)
+}
+
+func TestLIS3DHDriverSetDataRate(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	dev := newLIS3DHTestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetDataRate(LIS3DHRate1Hz), nil)
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl1), uint8(0x17))
+	gobottest.Assert(t, d.SetDataRate(LIS3DHRate1344Hz), nil)
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl1), uint8(0x97))
+	gobottest.Assert(t, d.SetDataRate(LIS3DHDataRate(0)), ErrInvalidDataRate)
+	gobottest.Assert(t, d.SetDataRate(LIS3DHDataRate(8)), ErrInvalidDataRate)
+	gobottest.Assert(t, d.dataRate, LIS3DHRate1344Hz)
+}
+
+func TestLIS3DHDriverReadFIFO(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor()
+	dev := newLIS3DHTestDevice(adaptor)
+	d.Start()
+	samples, err := d.ReadFIFO()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(samples), 0)
+
+	dev.push(lis3dhTestSample)
+	dev.push([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0xC0})
+	samples, err = d.ReadFIFO()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, samples, []LIS3DHAcceleration{{0.992, -0.496, 0.25}, {0, 0, -1.024}})
+
+	// all the 32 samples on overrun.
+	for i := 0; i < lis3dhFifoSize; i++ {
+		dev.push(lis3dhTestSample)
+	}
+	samples, err = d.ReadFIFO()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(samples), lis3dhFifoSize)
+}
+
+func TestLIS3DHDriverPoll(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor(WithLIS3DHPollInterval(time.Millisecond))
+	dev := newLIS3DHTestDevice(adaptor)
+
+	sem := make(chan LIS3DHAcceleration, 1)
+	d.Once(d.Event(Accel), func(data interface{}) {
+		sem <- data.(LIS3DHAcceleration)
+	})
+	gobottest.Assert(t, d.Start(), nil)
+	// streaming through the FIFO.
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl5), uint8(0x40))
+	gobottest.Assert(t, dev.register(lis3dhRegisterFifoCtrl), uint8(0x80))
+	dev.push(lis3dhTestSample)
+
+	select {
+	case a := <-sem:
+		gobottest.Assert(t, a, LIS3DHAcceleration{0.992, -0.496, 0.25})
+	case <-time.After(1 * time.Second):
+		t.Errorf("LIS3DH Event \"accel\" was not published")
+	}
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, dev.register(lis3dhRegisterCtrl1), uint8(0x00))
+}
+
+func TestLIS3DHDriverPollError(t *testing.T) {
+	d, adaptor := initTestLIS3DHDriverWithStubbedAdaptor(WithLIS3DHPollInterval(time.Millisecond))
+	newLIS3DHTestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	sem := make(chan error, 1)
+	d.Once(d.Event(Error), func(data interface{}) {
+		sem <- data.(error)
+	})
+	d.mutex.Lock()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	d.mutex.Unlock()
+
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New("read error"))
+	case <-time.After(1 * time.Second):
+		t.Errorf("LIS3DH Event \"error\" was not published")
+	}
+}
@@ -0,0 +1,263 @@
+package i2c
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"gobot.io/x/gobot"
+)
+
+// the default address, with SA0 high. It is 0x5C with SA0 low.
+const lps25hAddress = 0x5D
+
+const lps25hRegisterWhoAmI = 0x0F
+const lps25hWhoAmI = 0xBD
+const lps22hbWhoAmI = 0xB1
+
+const lps25hRegisterCtrl1 = 0x20
+const lps25hRegisterCtrl2 = 0x21
+const lps25hRegisterFifoCtrl = 0x2E
+const lps22hbRegisterCtrl1 = 0x10
+const lps25hRegisterPressureXL = 0x28
+const lps25hRegisterTempL = 0x2B
+
+// the MSB of the register address auto-increments it, on the LPS25H.
+const lps25hAutoIncrement = 0x80
+
+// power on, 1Hz output data rate, block data update.
+const lps25hCtrl1Active = 0x94
+
+// 1Hz output data rate, block data update.
+const lps22hbCtrl1Active = 0x12
+
+const lps25hCtrl2FifoEnable = 0x40
+const lps25hFifoModeMean = 0xC0
+
+// ErrInvalidFifoMean is returned when the FIFO mean of the LPS25H is set to
+// an unsupported number of samples.
+var ErrInvalidFifoMean = errors.New("Invalid FIFO mean samples")
+
+// ErrFifoMeanNotSupported is returned when the FIFO mean is set on a
+// LPS22HB, which cannot average in hardware.
+var ErrFifoMeanNotSupported = errors.New("FIFO mean not supported")
+
+// LPS25HDriver is the gobot driver for the ST pressure sensor LPS25H,
+// which also drives the LPS22HB.
+// Device datasheet: https://www.st.com/resource/en/datasheet/lps25h.pdf
+//
+// The device measures continuously at 1Hz, and the LPS25H can average the
+// last 2 to 32 measurements in its FIFO, which reduces the noise without
+// any computation on the host.
+type LPS25HDriver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	whoAmI   byte
+	fifoMean int
+	mutex    *sync.Mutex
+}
+
+// NewLPS25HDriver creates a new driver with the i2c interface for the LPS25H
+// or LPS22HB device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver, 0x5C when SA0 is low
+//		i2c.WithLPS25HFifoMean(int):	samples averaged by the FIFO, defaults to 0 that is no averaging
+//
+func NewLPS25HDriver(c Connector, options ...func(Config)) *LPS25HDriver {
+	l := &LPS25HDriver{
+		name:      gobot.DefaultName("LPS25H"),
+		connector: c,
+		Config:    NewConfig(),
+		mutex:     &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+// WithLPS25HFifoMean option sets the number of measurements averaged by the
+// FIFO of the LPS25H: 2, 4, 8, 16 or 32, or 0 for no averaging. Other values
+// are ignored.
+func WithLPS25HFifoMean(val int) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*LPS25HDriver)
+		if ok && lps25hValidFifoMean(val) {
+			d.fifoMean = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *LPS25HDriver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *LPS25HDriver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *LPS25HDriver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start checks the identity of the device, and starts its measurements.
+func (d *LPS25HDriver) Start() (err error) {
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(lps25hAddress)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *LPS25HDriver) initialization() (err error) {
+	var id []byte
+	if id, err = d.read(lps25hRegisterWhoAmI, 1); err != nil {
+		return err
+	}
+	if id[0] != lps25hWhoAmI && id[0] != lps22hbWhoAmI {
+		return fmt.Errorf("LPS25H device not found (WHO_AM_I 0x%02X)", id[0])
+	}
+	d.whoAmI = id[0]
+
+	if d.whoAmI == lps22hbWhoAmI {
+		if d.fifoMean != 0 {
+			return ErrFifoMeanNotSupported
+		}
+		_, err = d.connection.Write([]byte{lps22hbRegisterCtrl1, lps22hbCtrl1Active})
+		return err
+	}
+	if _, err = d.connection.Write([]byte{lps25hRegisterCtrl1, lps25hCtrl1Active}); err != nil {
+		return err
+	}
+	return d.writeFifoMean(d.fifoMean)
+}
+
+// Halt powers the device down.
+func (d *LPS25HDriver) Halt() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.connection == nil {
+		return nil
+	}
+	reg := byte(lps25hRegisterCtrl1)
+	if d.whoAmI == lps22hbWhoAmI {
+		reg = lps22hbRegisterCtrl1
+	}
+	_, err = d.connection.Write([]byte{reg, 0x00})
+	return err
+}
+
+// IsLPS22HB returns whether the device found by Start is a LPS22HB rather
+// than a LPS25H.
+func (d *LPS25HDriver) IsLPS22HB() bool {
+	return d.whoAmI == lps22hbWhoAmI
+}
+
+// SetFifoMean sets the number of measurements averaged by the FIFO of the
+// LPS25H: 2, 4, 8, 16 or 32, or 0 for no averaging. It returns
+// ErrInvalidFifoMean for other values, and ErrFifoMeanNotSupported on a
+// LPS22HB.
+func (d *LPS25HDriver) SetFifoMean(samples int) error {
+	if !lps25hValidFifoMean(samples) {
+		return ErrInvalidFifoMean
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.whoAmI == lps22hbWhoAmI {
+		return ErrFifoMeanNotSupported
+	}
+	if err := d.writeFifoMean(samples); err != nil {
+		return err
+	}
+	d.fifoMean = samples
+	return nil
+}
+
+// Pressure returns the current pressure, in pascals.
+func (d *LPS25HDriver) Pressure() (pressure float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	if data, err = d.read(lps25hRegisterPressureXL, 3); err != nil {
+		return 0, err
+	}
+	// 24 bit two's complement, in 1/4096 hPa.
+	raw := int32(uint32(data[2])<<24|uint32(data[1])<<16|uint32(data[0])<<8) >> 8
+	return float32(raw) * 100 / 4096, nil
+}
+
+// Temperature returns the current temperature, in celsius degrees.
+func (d *LPS25HDriver) Temperature() (temp float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	if data, err = d.read(lps25hRegisterTempL, 2); err != nil {
+		return 0, err
+	}
+	raw := int16(uint16(data[1])<<8 | uint16(data[0]))
+	if d.whoAmI == lps22hbWhoAmI {
+		return float32(raw) / 100, nil
+	}
+	return 42.5 + float32(raw)/480, nil
+}
+
+func (d *LPS25HDriver) writeFifoMean(samples int) (err error) {
+	if samples == 0 {
+		if _, err = d.connection.Write([]byte{lps25hRegisterCtrl2, 0x00}); err != nil {
+			return err
+		}
+		_, err = d.connection.Write([]byte{lps25hRegisterFifoCtrl, 0x00})
+		return err
+	}
+	if _, err = d.connection.Write([]byte{lps25hRegisterFifoCtrl, lps25hFifoModeMean | byte(samples-1)}); err != nil {
+		return err
+	}
+	_, err = d.connection.Write([]byte{lps25hRegisterCtrl2, lps25hCtrl2FifoEnable})
+	return err
+}
+
+func (d *LPS25HDriver) read(address byte, n int) ([]byte, error) {
+	if n > 1 && d.whoAmI != lps22hbWhoAmI {
+		address |= lps25hAutoIncrement
+	}
+	if _, err := d.connection.Write([]byte{address}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if bytesRead != n {
+		return nil, ErrNotEnoughBytes
+	}
+	return buf, nil
+}
+
+func lps25hValidFifoMean(samples int) bool {
+	switch samples {
+	case 0, 2, 4, 8, 16, 32:
+		return true
+	}
+	return false
+}
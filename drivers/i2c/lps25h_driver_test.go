@@ -0,0 +1,223 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*LPS25HDriver)(nil)
+
+// --------- HELPERS
+func initTestLPS25HDriver() (driver *LPS25HDriver) {
+	driver, _ = initTestLPS25HDriverWithStubbedAdaptor()
+	return
+}
+
+func initTestLPS25HDriverWithStubbedAdaptor() (*LPS25HDriver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewLPS25HDriver(adaptor), adaptor
+}
+
+// lps25hTestReadImpl answers reads as a device with the given WHO_AM_I,
+// measuring 1013.25 hPa and 25 celsius degrees.
+func lps25hTestReadImpl(adaptor *i2cTestAdaptor, whoAmI byte) func([]byte) (int, error) {
+	return func(b []byte) (int, error) {
+		switch adaptor.written[len(adaptor.written)-1] &^ lps25hAutoIncrement {
+		case lps25hRegisterWhoAmI:
+			b[0] = whoAmI
+		case lps25hRegisterPressureXL:
+			copy(b, []byte{0x00, 0x54, 0x3F})
+		case lps25hRegisterTempL:
+			if whoAmI == lps22hbWhoAmI {
+				// 2500
+				copy(b, []byte{0xC4, 0x09})
+			} else {
+				// -8400
+				copy(b, []byte{0x30, 0xDF})
+			}
+		}
+		return len(b), nil
+	}
+}
+
+// --------- TESTS
+
+func TestNewLPS25HDriver(t *testing.T) {
+	// Does it return a pointer to an instance of LPS25HDriver?
+	var lps25h interface{} = NewLPS25HDriver(newI2cTestAdaptor())
+	_, ok := lps25h.(*LPS25HDriver)
+	if !ok {
+		t.Errorf("NewLPS25HDriver() should have returned a *LPS25HDriver")
+	}
+
+	l := NewLPS25HDriver(newI2cTestAdaptor())
+	gobottest.Refute(t, l.Connection(), nil)
+}
+
+func TestLPS25HDriverStart(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps25hWhoAmI)
+
+	gobottest.Assert(t, lps25h.Start(), nil)
+	gobottest.Assert(t, lps25h.IsLPS22HB(), false)
+	gobottest.Assert(t, adaptor.written, []byte{0x0F, 0x20, 0x94, 0x21, 0x00, 0x2E, 0x00})
+	gobottest.Assert(t, adaptor.address, 0x5D)
+}
+
+func TestLPS25HDriverStartLPS22HB(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps22hbWhoAmI)
+
+	gobottest.Assert(t, lps25h.Start(), nil)
+	gobottest.Assert(t, lps25h.IsLPS22HB(), true)
+	gobottest.Assert(t, adaptor.written, []byte{0x0F, 0x10, 0x12})
+}
+
+func TestLPS25HDriverStartWhoAmI(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, 0x33)
+
+	gobottest.Assert(t, lps25h.Start(), errors.New("LPS25H device not found (WHO_AM_I 0x33)"))
+	// nothing is configured on an unknown device.
+	gobottest.Assert(t, adaptor.written, []byte{0x0F})
+}
+
+func TestLPS25HDriverStartConnectError(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, lps25h.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestLPS25HDriverStartReadError(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	gobottest.Assert(t, lps25h.Start(), errors.New("read error"))
+}
+
+func TestLPS25HDriverHalt(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	gobottest.Assert(t, lps25h.Halt(), nil)
+
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps25hWhoAmI)
+	lps25h.Start()
+	adaptor.written = []byte{}
+	gobottest.Assert(t, lps25h.Halt(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x20, 0x00})
+}
+
+func TestLPS25HDriverMeasurements(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps25hWhoAmI)
+	lps25h.Start()
+
+	adaptor.written = []byte{}
+	pressure, err := lps25h.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(101325))
+	temp, err := lps25h.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(25))
+	// the multi-byte reads auto-increment the register address.
+	gobottest.Assert(t, adaptor.written, []byte{0xA8, 0xAB})
+}
+
+func TestLPS25HDriverMeasurementsLPS22HB(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps22hbWhoAmI)
+	lps25h.Start()
+
+	adaptor.written = []byte{}
+	pressure, err := lps25h.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(101325))
+	temp, err := lps25h.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(25))
+	gobottest.Assert(t, adaptor.written, []byte{0x28, 0x2B})
+}
+
+func TestLPS25HDriverNegativePressure(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps25hWhoAmI)
+	lps25h.Start()
+
+	// -4096, a sign extended 24 bit value.
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x00, 0xF0, 0xFF})
+		return len(b), nil
+	}
+	pressure, err := lps25h.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(-100))
+}
+
+func TestLPS25HDriverMeasurementErrors(t *testing.T) {
+	lps25h, adaptor := initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps25hWhoAmI)
+	lps25h.Start()
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 1, nil
+	}
+	_, err := lps25h.Pressure()
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	_, err = lps25h.Temperature()
+	gobottest.Assert(t, err, errors.New("write error"))
+}
+
+func TestLPS25HDriverFifoMean(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	lps25h := NewLPS25HDriver(adaptor, WithLPS25HFifoMean(32))
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps25hWhoAmI)
+
+	gobottest.Assert(t, lps25h.Start(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x0F, 0x20, 0x94, 0x2E, 0xDF, 0x21, 0x40})
+
+	adaptor.written = []byte{}
+	gobottest.Assert(t, lps25h.SetFifoMean(4), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x2E, 0xC3, 0x21, 0x40})
+	adaptor.written = []byte{}
+	gobottest.Assert(t, lps25h.SetFifoMean(0), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x21, 0x00, 0x2E, 0x00})
+	gobottest.Assert(t, lps25h.SetFifoMean(3), ErrInvalidFifoMean)
+}
+
+func TestLPS25HDriverFifoMeanLPS22HB(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	lps25h := NewLPS25HDriver(adaptor, WithLPS25HFifoMean(8))
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps22hbWhoAmI)
+	gobottest.Assert(t, lps25h.Start(), ErrFifoMeanNotSupported)
+
+	lps25h, adaptor = initTestLPS25HDriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = lps25hTestReadImpl(adaptor, lps22hbWhoAmI)
+	lps25h.Start()
+	gobottest.Assert(t, lps25h.SetFifoMean(8), ErrFifoMeanNotSupported)
+}
+
+func TestLPS25HDriverName(t *testing.T) {
+	lps25h := initTestLPS25HDriver()
+
+	gobottest.Assert(t, strings.HasPrefix(lps25h.Name(), "LPS25H"), true)
+	lps25h.SetName("Sensor")
+	gobottest.Assert(t, lps25h.Name(), "Sensor")
+}
+
+func TestLPS25HDriverOptions(t *testing.T) {
+	lps25h := NewLPS25HDriver(newI2cTestAdaptor(), WithBus(2), WithLPS25HFifoMean(16))
+	gobottest.Assert(t, lps25h.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, lps25h.fifoMean, 16)
+
+	// invalid values are ignored.
+	lps25h = NewLPS25HDriver(newI2cTestAdaptor(), WithLPS25HFifoMean(5))
+	gobottest.Assert(t, lps25h.fifoMean, 0)
+}
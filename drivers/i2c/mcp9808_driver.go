@@ -0,0 +1,421 @@
+package i2c
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+const mcp9808Address = 0x18
+
+const mcp9808RegisterConfig = 0x01
+const mcp9808RegisterUpper = 0x02
+const mcp9808RegisterLower = 0x03
+const mcp9808RegisterCritical = 0x04
+const mcp9808RegisterAmbient = 0x05
+const mcp9808RegisterManufacturerID = 0x06
+const mcp9808RegisterDeviceID = 0x07
+const mcp9808RegisterResolution = 0x08
+
+const mcp9808ManufacturerID = 0x0054
+
+// the high byte of the device id register, the low byte is the revision.
+const mcp9808DeviceID = 0x04
+
+// the bits of the configuration register.
+const mcp9808ConfigHysteresisShift = 9
+const mcp9808ConfigShutdown = 0x0100
+const mcp9808ConfigIntClear = 0x0020
+const mcp9808ConfigAlertEnable = 0x0008
+const mcp9808ConfigAlertCritical = 0x0004
+const mcp9808ConfigAlertActiveHigh = 0x0002
+const mcp9808ConfigAlertInterrupt = 0x0001
+
+// the flags of the ambient temperature register.
+const mcp9808AmbientCritical = 0x8000
+const mcp9808AmbientUpper = 0x4000
+const mcp9808AmbientLower = 0x2000
+
+// the limits are in quarters of degree, from -256 degrees exclusive.
+const mcp9808MaxLimit = 255.75
+
+// ErrInvalidTemperatureLimit is returned when an alert limit of the MCP9808
+// is out of its -255.75 to 255.75 degrees range.
+var ErrInvalidTemperatureLimit = errors.New("Invalid temperature limit")
+
+// MCP9808Resolution is the resolution of the temperature measurement.
+type MCP9808Resolution uint8
+
+const (
+	// MCP9808ResolutionHalf is a 0.5 degree resolution, measured in 30ms.
+	MCP9808ResolutionHalf MCP9808Resolution = iota
+	// MCP9808ResolutionQuarter is a 0.25 degree resolution, measured in 65ms.
+	MCP9808ResolutionQuarter
+	// MCP9808ResolutionEighth is a 0.125 degree resolution, measured in
+	// 130ms.
+	MCP9808ResolutionEighth
+	// MCP9808ResolutionSixteenth is a 0.0625 degree resolution, measured in
+	// 250ms, the default of the MCP9808.
+	MCP9808ResolutionSixteenth
+)
+
+// MCP9808Hysteresis is the hysteresis of the alert limits.
+type MCP9808Hysteresis uint8
+
+const (
+	// MCP9808Hysteresis0 is no hysteresis, the default.
+	MCP9808Hysteresis0 MCP9808Hysteresis = iota
+	// MCP9808Hysteresis1_5 is a 1.5 degree hysteresis.
+	MCP9808Hysteresis1_5
+	// MCP9808Hysteresis3 is a 3 degree hysteresis.
+	MCP9808Hysteresis3
+	// MCP9808Hysteresis6 is a 6 degree hysteresis.
+	MCP9808Hysteresis6
+)
+
+// MCP9808AlertConfig is the configuration of the alert output of the
+// MCP9808.
+type MCP9808AlertConfig struct {
+	// Enabled enables the alert output.
+	Enabled bool
+	// CriticalOnly asserts the output only above the critical limit, rather
+	// than outside of the lower and upper limits too.
+	CriticalOnly bool
+	// ActiveHigh makes the output active high, rather than active low for an
+	// open drain with a pull-up.
+	ActiveHigh bool
+	// Interrupt makes the output an interrupt, asserted until cleared with
+	// ClearInterrupt, rather than a comparator following the temperature.
+	Interrupt bool
+	// Hysteresis is the hysteresis of the limits.
+	Hysteresis MCP9808Hysteresis
+}
+
+// MCP9808AlertStatus is the state of the temperature compared to the alert
+// limits.
+type MCP9808AlertStatus struct {
+	Lower    bool
+	Upper    bool
+	Critical bool
+}
+
+// MCP9808Driver is the gobot driver for the Microchip MCP9808 digital
+// temperature sensor, accurate to 0.25 degree.
+// Device datasheet: http://ww1.microchip.com/downloads/en/DeviceDoc/25095A.pdf
+//
+// The temperature is measured on demand, or polled and published as
+// a Temperature event when a poll interval is set.
+type MCP9808Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	gobot.Eventer
+	resolution MCP9808Resolution
+	interval   time.Duration
+	errorEvent bool
+	lastError  error
+	poller     poller
+	mutex      *sync.Mutex
+}
+
+// NewMCP9808Driver creates a new driver with the i2c interface for the MCP9808 device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithMCP9808Resolution(MCP9808Resolution):	resolution, defaults to MCP9808ResolutionSixteenth
+//		i2c.WithMCP9808PollInterval(time.Duration):	interval of the Temperature events, defaults to 0 that is no polling
+//
+func NewMCP9808Driver(c Connector, options ...func(Config)) *MCP9808Driver {
+	m := &MCP9808Driver{
+		name:       gobot.DefaultName("MCP9808"),
+		connector:  c,
+		Config:     NewConfig(),
+		Eventer:    gobot.NewEventer(),
+		resolution: MCP9808ResolutionSixteenth,
+		errorEvent: true,
+		mutex:      &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	m.AddEvent(Temperature)
+	m.AddEvent(Error)
+	return m
+}
+
+// WithMCP9808Resolution option sets the resolution of the temperature
+// measurement. Unknown resolutions are ignored.
+func WithMCP9808Resolution(val MCP9808Resolution) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*MCP9808Driver)
+		if ok && val <= MCP9808ResolutionSixteenth {
+			d.resolution = val
+		}
+	}
+}
+
+// WithMCP9808PollInterval option sets the interval at which the driver
+// measures the temperature after Start, and publishes it in a Temperature
+// event, or an Error event.
+func WithMCP9808PollInterval(val time.Duration) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*MCP9808Driver)
+		if ok && val > 0 {
+			d.interval = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *MCP9808Driver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *MCP9808Driver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *MCP9808Driver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start checks the identity of the device, sets its resolution, and starts
+// polling the temperature if a poll interval is set. It returns
+// ErrAlreadyStarted until Halt once started, rather than polling twice.
+func (d *MCP9808Driver) Start() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.poller.started {
+		return ErrAlreadyStarted
+	}
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(mcp9808Address)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return d.poller.start(d.interval, d.poll)
+}
+
+func (d *MCP9808Driver) initialization() (err error) {
+	var manufacturer, device uint16
+	if manufacturer, err = ReadWord(d.connection, mcp9808RegisterManufacturerID, binary.BigEndian); err != nil {
+		return err
+	}
+	if device, err = ReadWord(d.connection, mcp9808RegisterDeviceID, binary.BigEndian); err != nil {
+		return err
+	}
+	if manufacturer != mcp9808ManufacturerID || device>>8 != mcp9808DeviceID {
+		return fmt.Errorf("MCP9808 device not found (manufacturer 0x%04X, device 0x%04X)", manufacturer, device)
+	}
+	_, err = d.connection.Write([]byte{mcp9808RegisterResolution, byte(d.resolution)})
+	return err
+}
+
+// Halt stops polling the temperature, if it was, after which the driver can
+// be started again.
+func (d *MCP9808Driver) Halt() (err error) {
+	d.mutex.Lock()
+	wait := d.poller.stop()
+	d.mutex.Unlock()
+
+	// the poll locks the mutex to measure, thus is waited for without it.
+	wait()
+	return nil
+}
+
+// Temperature returns the current temperature, in celsius degrees.
+func (d *MCP9808Driver) Temperature() (temp float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var ambient uint16
+	if ambient, err = ReadWord(d.connection, mcp9808RegisterAmbient, binary.BigEndian); err != nil {
+		return 0, err
+	}
+	return mcp9808DecodeTemp(ambient), nil
+}
+
+// AlertStatus returns the state of the current temperature compared to the
+// alert limits. The flags do not depend on the alert configuration.
+func (d *MCP9808Driver) AlertStatus() (status MCP9808AlertStatus, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var ambient uint16
+	if ambient, err = ReadWord(d.connection, mcp9808RegisterAmbient, binary.BigEndian); err != nil {
+		return status, err
+	}
+	status.Lower = ambient&mcp9808AmbientLower != 0
+	status.Upper = ambient&mcp9808AmbientUpper != 0
+	status.Critical = ambient&mcp9808AmbientCritical != 0
+	return status, nil
+}
+
+// SetResolution sets the resolution of the temperature measurement, or
+// returns ErrInvalidResolution for an unknown resolution.
+func (d *MCP9808Driver) SetResolution(res MCP9808Resolution) error {
+	if res > MCP9808ResolutionSixteenth {
+		return ErrInvalidResolution
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, err := d.connection.Write([]byte{mcp9808RegisterResolution, byte(res)}); err != nil {
+		return err
+	}
+	d.resolution = res
+	return nil
+}
+
+// SetAlertLimits sets the limits of the alert, in celsius degrees, rounded
+// to a quarter of degree: the output is asserted below lower, above upper,
+// or above critical. It returns ErrInvalidTemperatureLimit for limits beyond
+// 255.75 degrees either way.
+func (d *MCP9808Driver) SetAlertLimits(lower, upper, critical float32) error {
+	limits := []struct {
+		reg   uint8
+		limit float32
+	}{
+		{mcp9808RegisterLower, lower},
+		{mcp9808RegisterUpper, upper},
+		{mcp9808RegisterCritical, critical},
+	}
+	for _, l := range limits {
+		if !(l.limit >= -mcp9808MaxLimit && l.limit <= mcp9808MaxLimit) {
+			return ErrInvalidTemperatureLimit
+		}
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for _, l := range limits {
+		if err := WriteWord(d.connection, l.reg, mcp9808EncodeLimit(l.limit), binary.BigEndian); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AlertLimits returns the lower, upper and critical limits of the alert,
+// in celsius degrees.
+func (d *MCP9808Driver) AlertLimits() (lower, upper, critical float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var limits [3]float32
+	for i, reg := range []uint8{mcp9808RegisterLower, mcp9808RegisterUpper, mcp9808RegisterCritical} {
+		var val uint16
+		if val, err = ReadWord(d.connection, reg, binary.BigEndian); err != nil {
+			return 0, 0, 0, err
+		}
+		limits[i] = mcp9808DecodeTemp(val)
+	}
+	return limits[0], limits[1], limits[2], nil
+}
+
+// SetAlertConfig sets the configuration of the alert output. The other
+// bits of the configuration register are kept.
+func (d *MCP9808Driver) SetAlertConfig(config MCP9808AlertConfig) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	reg, err := ReadWord(d.connection, mcp9808RegisterConfig, binary.BigEndian)
+	if err != nil {
+		return err
+	}
+	reg &= mcp9808ConfigShutdown
+	reg |= uint16(config.Hysteresis&0x03) << mcp9808ConfigHysteresisShift
+	if config.Enabled {
+		reg |= mcp9808ConfigAlertEnable
+	}
+	if config.CriticalOnly {
+		reg |= mcp9808ConfigAlertCritical
+	}
+	if config.ActiveHigh {
+		reg |= mcp9808ConfigAlertActiveHigh
+	}
+	if config.Interrupt {
+		reg |= mcp9808ConfigAlertInterrupt
+	}
+	return WriteWord(d.connection, mcp9808RegisterConfig, reg, binary.BigEndian)
+}
+
+// SetErrorEventEnabled sets whether the errors of the poll are published in
+// Error events. When disabled, the Error event stays registered but is not
+// published, and the errors are only returned by LastError. Defaults to
+// true.
+func (d *MCP9808Driver) SetErrorEventEnabled(enabled bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.errorEvent = enabled
+}
+
+// LastError returns the error of the last measurement of the poll, or nil
+// if it succeeded.
+func (d *MCP9808Driver) LastError() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.lastError
+}
+
+// ClearInterrupt clears the alert output in interrupt mode.
+func (d *MCP9808Driver) ClearInterrupt() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	reg, err := ReadWord(d.connection, mcp9808RegisterConfig, binary.BigEndian)
+	if err != nil {
+		return err
+	}
+	return WriteWord(d.connection, mcp9808RegisterConfig, reg|mcp9808ConfigIntClear, binary.BigEndian)
+}
+
+func (d *MCP9808Driver) poll() {
+	temp, err := d.Temperature()
+	d.mutex.Lock()
+	d.lastError = err
+	errorEvent := d.errorEvent
+	d.mutex.Unlock()
+	// published unlocked, for the subscribers calling back into the driver.
+	if err != nil && errorEvent {
+		d.Publish(d.Event(Error), err)
+	}
+	if err == nil {
+		d.Publish(d.Event(Temperature), temp)
+	}
+}
+
+// mcp9808DecodeTemp returns the temperature, in celsius degrees, of the
+// 13 bit two's complement value of a temperature register, in sixteenths of
+// degree. The flags in the 3 high bits are ignored.
+func mcp9808DecodeTemp(val uint16) float32 {
+	// shifting the sign bit to the top sign extends the value.
+	return float32(int16(val<<3)>>3) / 16
+}
+
+// mcp9808EncodeLimit returns the value of a limit register for the
+// temperature, in celsius degrees, rounded to a quarter of degree.
+func mcp9808EncodeLimit(temp float32) uint16 {
+	quarters := int16(math.Floor(float64(temp)*4 + 0.5))
+	return uint16(quarters<<2) & 0x1FFC
+}
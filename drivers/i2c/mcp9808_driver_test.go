@@ -0,0 +1,368 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*MCP9808Driver)(nil)
+
+// --------- HELPERS
+func initTestMCP9808Driver() (driver *MCP9808Driver) {
+	driver, _ = initTestMCP9808DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestMCP9808DriverWithStubbedAdaptor(options ...func(Config)) (*MCP9808Driver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewMCP9808Driver(adaptor, options...), adaptor
+}
+
+// newMCP9808TestDevice returns a MCP9808 measuring 25.0625 celsius degrees.
+func newMCP9808TestDevice(adaptor *i2cTestAdaptor) *i2cTestWordDevice {
+	return newI2cTestWordDevice(adaptor, map[byte]uint16{
+		mcp9808RegisterManufacturerID: 0x0054,
+		mcp9808RegisterDeviceID:       0x0400,
+		mcp9808RegisterAmbient:        0x0191,
+		mcp9808RegisterResolution:     0x0003,
+	})
+}
+
+// --------- TESTS
+
+func TestNewMCP9808Driver(t *testing.T) {
+	// Does it return a pointer to an instance of MCP9808Driver?
+	var mcp9808 interface{} = NewMCP9808Driver(newI2cTestAdaptor())
+	_, ok := mcp9808.(*MCP9808Driver)
+	if !ok {
+		t.Errorf("NewMCP9808Driver() should have returned a *MCP9808Driver")
+	}
+
+	m := NewMCP9808Driver(newI2cTestAdaptor())
+	gobottest.Refute(t, m.Connection(), nil)
+	gobottest.Assert(t, m.resolution, MCP9808ResolutionSixteenth)
+}
+
+func TestMCP9808DriverOptions(t *testing.T) {
+	d := NewMCP9808Driver(newI2cTestAdaptor(), WithBus(2),
+		WithMCP9808Resolution(MCP9808ResolutionQuarter), WithMCP9808PollInterval(time.Second))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.resolution, MCP9808ResolutionQuarter)
+	gobottest.Assert(t, d.interval, time.Second)
+
+	d = NewMCP9808Driver(newI2cTestAdaptor(), WithMCP9808Resolution(4), WithMCP9808PollInterval(-1))
+	gobottest.Assert(t, d.resolution, MCP9808ResolutionSixteenth)
+	gobottest.Assert(t, d.interval, time.Duration(0))
+}
+
+func TestMCP9808DriverSetName(t *testing.T) {
+	d := initTestMCP9808Driver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "TESTME"), true)
+}
+
+func TestMCP9808DriverStart(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor(WithMCP9808Resolution(MCP9808ResolutionHalf))
+	dev := newMCP9808TestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, adaptor.address, 0x18)
+	gobottest.Assert(t, dev.get(mcp9808RegisterResolution), uint16(0x00))
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestMCP9808DriverStartConnectError(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestMCP9808DriverStartNotFound(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	dev := newMCP9808TestDevice(adaptor)
+	dev.set(mcp9808RegisterManufacturerID, 0x0001)
+	gobottest.Assert(t, d.Start(), errors.New("MCP9808 device not found (manufacturer 0x0001, device 0x0400)"))
+
+	// any revision of the device is accepted.
+	dev.set(mcp9808RegisterManufacturerID, 0x0054)
+	dev.set(mcp9808RegisterDeviceID, 0x0401)
+	gobottest.Assert(t, d.Start(), nil)
+	d.Halt()
+	dev.set(mcp9808RegisterDeviceID, 0x7501)
+	gobottest.Assert(t, d.Start(), errors.New("MCP9808 device not found (manufacturer 0x0054, device 0x7501)"))
+}
+
+func TestMCP9808DriverStartTwice(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor(WithMCP9808PollInterval(time.Millisecond))
+	newMCP9808TestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	halt := d.poller.halt
+
+	// a single poll is running.
+	gobottest.Assert(t, d.Start(), ErrAlreadyStarted)
+	gobottest.Assert(t, d.poller.halt == halt, true)
+
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, d.poller.started, false)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.poller.halt != halt, true)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestMCP9808DriverStartTwiceConcurrently(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor(WithMCP9808PollInterval(time.Millisecond))
+	newMCP9808TestDevice(adaptor)
+	defer d.Halt()
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { errs <- d.Start() }()
+	}
+	err1, err2 := <-errs, <-errs
+	gobottest.Assert(t, (err1 == nil) != (err2 == nil), true)
+	gobottest.Assert(t, err1 == ErrAlreadyStarted || err2 == ErrAlreadyStarted, true)
+}
+
+func TestMCP9808DriverStartReadError(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	gobottest.Assert(t, d.Start(), errors.New("read error"))
+}
+
+func TestMCP9808DriverTemperature(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	dev := newMCP9808TestDevice(adaptor)
+	d.Start()
+	temp, err := d.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(25.0625))
+
+	// the alert flags are not part of the temperature.
+	dev.set(mcp9808RegisterAmbient, 0xE191)
+	temp, _ = d.Temperature()
+	gobottest.Assert(t, temp, float32(25.0625))
+}
+
+func TestMCP9808DriverTemperatureError(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	newMCP9808TestDevice(adaptor)
+	d.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err := d.Temperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestMCP9808DecodeTemp(t *testing.T) {
+	tests := []struct {
+		val  uint16
+		temp float32
+	}{
+		{0x0000, 0},
+		{0x0001, 0.0625},
+		{0x0190, 25},
+		{0x07D0, 125},
+		{0x0FFF, 255.9375},
+		{0x1FFF, -0.0625},
+		{0x1FF0, -1},
+		{0x1E70, -25},
+		{0x1D80, -40},
+		{0x1000, -256},
+		// the flags are ignored.
+		{0xFFF0, -1},
+		{0xE190, 25},
+	}
+	for _, test := range tests {
+		gobottest.Assert(t, mcp9808DecodeTemp(test.val), test.temp)
+	}
+}
+
+func TestMCP9808EncodeLimit(t *testing.T) {
+	tests := []struct {
+		temp float32
+		val  uint16
+	}{
+		{0, 0x0000},
+		{25, 0x0190},
+		{25.25, 0x0194},
+		// rounded to a quarter of degree.
+		{25.1, 0x0190},
+		{25.2, 0x0194},
+		{-1, 0x1FF0},
+		{-0.25, 0x1FFC},
+		{-40, 0x1D80},
+		{255.75, 0x0FFC},
+		{-255.75, 0x1004},
+	}
+	for _, test := range tests {
+		gobottest.Assert(t, mcp9808EncodeLimit(test.temp), test.val)
+	}
+}
+
+func TestMCP9808DriverSetResolution(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	dev := newMCP9808TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetResolution(MCP9808ResolutionEighth), nil)
+	gobottest.Assert(t, dev.get(mcp9808RegisterResolution), uint16(0x02))
+	gobottest.Assert(t, d.resolution, MCP9808ResolutionEighth)
+
+	gobottest.Assert(t, d.SetResolution(4), ErrInvalidResolution)
+	gobottest.Assert(t, d.resolution, MCP9808ResolutionEighth)
+}
+
+func TestMCP9808DriverAlertLimits(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	dev := newMCP9808TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetAlertLimits(-10, 30.5, 80), nil)
+	gobottest.Assert(t, dev.get(mcp9808RegisterLower), uint16(0x1F60))
+	gobottest.Assert(t, dev.get(mcp9808RegisterUpper), uint16(0x01E8))
+	gobottest.Assert(t, dev.get(mcp9808RegisterCritical), uint16(0x0500))
+
+	lower, upper, critical, err := d.AlertLimits()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, lower, float32(-10))
+	gobottest.Assert(t, upper, float32(30.5))
+	gobottest.Assert(t, critical, float32(80))
+
+	// no limit is written when any is invalid.
+	gobottest.Assert(t, d.SetAlertLimits(0, 256, 300), ErrInvalidTemperatureLimit)
+	gobottest.Assert(t, dev.get(mcp9808RegisterLower), uint16(0x1F60))
+}
+
+func TestMCP9808DriverAlertConfig(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	dev := newMCP9808TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetAlertConfig(MCP9808AlertConfig{
+		Enabled:    true,
+		ActiveHigh: true,
+		Interrupt:  true,
+		Hysteresis: MCP9808Hysteresis3,
+	}), nil)
+	gobottest.Assert(t, dev.get(mcp9808RegisterConfig), uint16(0x040B))
+
+	// the shutdown bit is kept.
+	dev.set(mcp9808RegisterConfig, 0x0100|0x040B)
+	gobottest.Assert(t, d.SetAlertConfig(MCP9808AlertConfig{Enabled: true, CriticalOnly: true}), nil)
+	gobottest.Assert(t, dev.get(mcp9808RegisterConfig), uint16(0x010C))
+
+	gobottest.Assert(t, d.ClearInterrupt(), nil)
+	gobottest.Assert(t, dev.get(mcp9808RegisterConfig), uint16(0x012C))
+}
+
+func TestMCP9808DriverAlertStatus(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor()
+	dev := newMCP9808TestDevice(adaptor)
+	d.Start()
+	status, err := d.AlertStatus()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, status, MCP9808AlertStatus{})
+
+	dev.set(mcp9808RegisterAmbient, 0xC191)
+	status, _ = d.AlertStatus()
+	gobottest.Assert(t, status, MCP9808AlertStatus{Upper: true, Critical: true})
+
+	dev.set(mcp9808RegisterAmbient, 0x3E70)
+	status, _ = d.AlertStatus()
+	gobottest.Assert(t, status, MCP9808AlertStatus{Lower: true})
+}
+
+func TestMCP9808DriverPoll(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor(WithMCP9808PollInterval(time.Millisecond))
+	newMCP9808TestDevice(adaptor)
+
+	sem := make(chan float32, 1)
+	d.Once(d.Event(Temperature), func(data interface{}) {
+		sem <- data.(float32)
+	})
+	gobottest.Assert(t, d.Start(), nil)
+
+	select {
+	case temp := <-sem:
+		gobottest.Assert(t, temp, float32(25.0625))
+	case <-time.After(1 * time.Second):
+		t.Errorf("MCP9808 Event \"temperature\" was not published")
+	}
+
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, d.poller.halt == nil, true)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestMCP9808DriverPollError(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor(WithMCP9808PollInterval(time.Millisecond))
+	newMCP9808TestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	sem := make(chan error, 1)
+	d.Once(d.Event(Error), func(data interface{}) {
+		sem <- data.(error)
+	})
+	d.mutex.Lock()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	d.mutex.Unlock()
+
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New("read error"))
+	case <-time.After(1 * time.Second):
+		t.Errorf("MCP9808 Event \"error\" was not published")
+	}
+}
+
+func TestMCP9808DriverErrorEventDisabled(t *testing.T) {
+	d, adaptor := initTestMCP9808DriverWithStubbedAdaptor(WithMCP9808PollInterval(time.Millisecond))
+	newMCP9808TestDevice(adaptor)
+	d.SetErrorEventEnabled(false)
+	// the event stays registered.
+	_, ok := d.Events()[Error]
+	gobottest.Assert(t, ok, true)
+
+	sem := make(chan error, 1)
+	d.Once(Error, func(data interface{}) {
+		sem <- data.(error)
+	})
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+	gobottest.Assert(t, d.LastError(), nil)
+	d.mutex.Lock()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	d.mutex.Unlock()
+
+	deadline := time.After(1 * time.Second)
+	for d.LastError() == nil {
+		select {
+		case <-deadline:
+			t.Fatalf("MCP9808 LastError was not set")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	gobottest.Assert(t, d.LastError(), errors.New("read error"))
+	select {
+	case <-sem:
+		t.Errorf("MCP9808 Event \"error\" was published")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	d.SetErrorEventEnabled(true)
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, errors.New("read error"))
+	case <-time.After(1 * time.Second):
+		t.Errorf("MCP9808 Event \"error\" was not published")
+	}
+}
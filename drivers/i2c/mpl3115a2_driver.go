@@ -0,0 +1,303 @@
+package i2c
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+const mpl3115a2Address = 0x60
+
+const mpl3115a2RegisterStatus = 0x00
+const mpl3115a2RegisterPressureMSB = 0x01
+const mpl3115a2RegisterWhoAmI = 0x0C
+const mpl3115a2RegisterPTDataCfg = 0x13
+const mpl3115a2RegisterCtrl1 = 0x26
+
+const mpl3115a2WhoAmI = 0xC4
+
+// data ready flags for pressure/altitude and temperature, see datasheet.
+const mpl3115a2PTDataCfgEvents = 0x07
+const mpl3115a2StatusPTDR = 0x08
+
+const mpl3115a2Ctrl1Alt = 0x80
+const mpl3115a2Ctrl1OST = 0x02
+const mpl3115a2Ctrl1SBYB = 0x01
+
+const mpl3115a2PollInterval = 2 * time.Millisecond
+
+const (
+	// MPL3115A2OneShot starts a conversion for each measurement, and keeps the
+	// device in standby in between.
+	MPL3115A2OneShot MPL3115A2Mode = iota
+	// MPL3115A2Continuous keeps the device converting, each measurement
+	// returns the latest sample.
+	MPL3115A2Continuous
+)
+
+const (
+	// MPL3115A2OS1 takes a single sample per measurement.
+	MPL3115A2OS1 MPL3115A2OversamplingRatio = iota
+	// MPL3115A2OS2 averages 2 samples per measurement.
+	MPL3115A2OS2
+	// MPL3115A2OS4 averages 4 samples per measurement.
+	MPL3115A2OS4
+	// MPL3115A2OS8 averages 8 samples per measurement.
+	MPL3115A2OS8
+	// MPL3115A2OS16 averages 16 samples per measurement.
+	MPL3115A2OS16
+	// MPL3115A2OS32 averages 32 samples per measurement.
+	MPL3115A2OS32
+	// MPL3115A2OS64 averages 64 samples per measurement.
+	MPL3115A2OS64
+	// MPL3115A2OS128 averages 128 samples per measurement.
+	MPL3115A2OS128
+)
+
+// ErrMPL3115A2DataNotReady is returned when a conversion of the MPL3115A2
+// did not complete in the expected time.
+var ErrMPL3115A2DataNotReady = errors.New("MPL3115A2 data not ready")
+
+// MPL3115A2Mode is the acquisition mode of the MPL3115A2.
+type MPL3115A2Mode uint8
+
+// MPL3115A2OversamplingRatio is the oversampling ratio of the MPL3115A2
+// measurements. Each step doubles both the precision and the conversion time.
+type MPL3115A2OversamplingRatio uint8
+
+// MPL3115A2Driver is the gobot driver for the NXP altimeter MPL3115A2.
+// Device datasheet: https://www.nxp.com/docs/en/data-sheet/MPL3115A2.pdf
+type MPL3115A2Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	mode         MPL3115A2Mode
+	oversampling MPL3115A2OversamplingRatio
+	ctrl1        byte
+	mutex        *sync.Mutex
+}
+
+// NewMPL3115A2Driver creates a new driver with the i2c interface for the MPL3115A2 device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithMPL3115A2Mode(MPL3115A2Mode):	acquisition mode, defaults to MPL3115A2OneShot
+//		i2c.WithMPL3115A2Oversampling(MPL3115A2OversamplingRatio):	oversampling ratio, defaults to MPL3115A2OS128
+//
+func NewMPL3115A2Driver(c Connector, options ...func(Config)) *MPL3115A2Driver {
+	m := &MPL3115A2Driver{
+		name:         gobot.DefaultName("MPL3115A2"),
+		connector:    c,
+		Config:       NewConfig(),
+		mode:         MPL3115A2OneShot,
+		oversampling: MPL3115A2OS128,
+		mutex:        &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	// TODO: expose commands to API
+	return m
+}
+
+// WithMPL3115A2Mode option sets the acquisition mode of the MPL3115A2.
+func WithMPL3115A2Mode(val MPL3115A2Mode) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*MPL3115A2Driver)
+		if ok {
+			d.mode = val
+		}
+	}
+}
+
+// WithMPL3115A2Oversampling option sets the oversampling ratio of the MPL3115A2.
+// Valid values are MPL3115A2OS1 to MPL3115A2OS128.
+func WithMPL3115A2Oversampling(val MPL3115A2OversamplingRatio) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*MPL3115A2Driver)
+		if ok && val <= MPL3115A2OS128 {
+			d.oversampling = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *MPL3115A2Driver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *MPL3115A2Driver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *MPL3115A2Driver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start initializes the MPL3115A2, and starts the conversions in continuous mode.
+func (d *MPL3115A2Driver) Start() (err error) {
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(mpl3115a2Address)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *MPL3115A2Driver) initialization() (err error) {
+	var id []byte
+	if id, err = d.read(mpl3115a2RegisterWhoAmI, 1); err != nil {
+		return err
+	}
+	if id[0] != mpl3115a2WhoAmI {
+		return fmt.Errorf("MPL3115A2 device not found (WHO_AM_I 0x%02X)", id[0])
+	}
+
+	if _, err = d.connection.Write([]byte{mpl3115a2RegisterPTDataCfg, mpl3115a2PTDataCfgEvents}); err != nil {
+		return err
+	}
+	d.ctrl1 = byte(d.oversampling) << 3
+	if d.mode == MPL3115A2Continuous {
+		d.ctrl1 |= mpl3115a2Ctrl1SBYB
+	}
+	_, err = d.connection.Write([]byte{mpl3115a2RegisterCtrl1, d.ctrl1})
+	return err
+}
+
+// Halt halts the device.
+func (d *MPL3115A2Driver) Halt() (err error) {
+	return nil
+}
+
+// Pressure returns the current pressure, in pascals.
+func (d *MPL3115A2Driver) Pressure() (pressure float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	if data, err = d.measure(false); err != nil {
+		return 0, err
+	}
+	// unsigned 20 bits, in quarters of pascal.
+	raw := uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
+	return float32(raw>>4) / 4, nil
+}
+
+// Altitude returns the current altitude, in meters, as computed by the
+// MPL3115A2 from the pressure.
+func (d *MPL3115A2Driver) Altitude() (alt float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	if data, err = d.measure(true); err != nil {
+		return 0, err
+	}
+	// signed 20 bits, in sixteenths of meter.
+	raw := int32(uint32(data[0])<<24|uint32(data[1])<<16|uint32(data[2])<<8) >> 12
+	return float32(raw) / 16, nil
+}
+
+// Temperature returns the current temperature, in celsius degrees.
+func (d *MPL3115A2Driver) Temperature() (temp float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var data []byte
+	if data, err = d.measure(d.ctrl1&mpl3115a2Ctrl1Alt != 0); err != nil {
+		return 0, err
+	}
+	// signed 12 bits, in sixteenths of degree.
+	raw := int16(uint16(data[3])<<8|uint16(data[4])) >> 4
+	return float32(raw) / 16, nil
+}
+
+// measure returns the 5 output bytes of the MPL3115A2, the pressure or the
+// altitude followed by the temperature, measured in the altimeter mode or not.
+func (d *MPL3115A2Driver) measure(altimeter bool) (data []byte, err error) {
+	ctrl1 := byte(d.oversampling) << 3
+	if altimeter {
+		ctrl1 |= mpl3115a2Ctrl1Alt
+	}
+
+	if d.mode == MPL3115A2Continuous {
+		if d.ctrl1 != ctrl1|mpl3115a2Ctrl1SBYB {
+			// the altimeter mode can only be changed in standby.
+			if _, err = d.connection.Write([]byte{mpl3115a2RegisterCtrl1, ctrl1}); err != nil {
+				return nil, err
+			}
+			d.ctrl1 = ctrl1 | mpl3115a2Ctrl1SBYB
+			if _, err = d.connection.Write([]byte{mpl3115a2RegisterCtrl1, d.ctrl1}); err != nil {
+				return nil, err
+			}
+			if err = d.waitForData(); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		d.ctrl1 = ctrl1
+		if _, err = d.connection.Write([]byte{mpl3115a2RegisterCtrl1, ctrl1 | mpl3115a2Ctrl1OST}); err != nil {
+			return nil, err
+		}
+		if err = d.waitForData(); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.read(mpl3115a2RegisterPressureMSB, 5)
+}
+
+// waitForData polls the status of the MPL3115A2 until a new sample is
+// available, for at most the conversion time of the oversampling ratio.
+func (d *MPL3115A2Driver) waitForData() error {
+	deadline := time.Now().Add(mpl3115a2ConversionTime(d.oversampling))
+	for {
+		status, err := d.read(mpl3115a2RegisterStatus, 1)
+		if err != nil {
+			return err
+		}
+		if status[0]&mpl3115a2StatusPTDR != 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrMPL3115A2DataNotReady
+		}
+		time.Sleep(mpl3115a2PollInterval)
+	}
+}
+
+func (d *MPL3115A2Driver) read(address byte, n int) ([]byte, error) {
+	if _, err := d.connection.Write([]byte{address}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if bytesRead != n {
+		return nil, ErrNotEnoughBytes
+	}
+	return buf, nil
+}
+
+// mpl3115a2ConversionTime returns the maximum conversion time of the
+// MPL3115A2 for the given oversampling ratio, from 6ms to 514ms, see datasheet.
+func mpl3115a2ConversionTime(ratio MPL3115A2OversamplingRatio) time.Duration {
+	return time.Duration(4<<ratio+2) * time.Millisecond
+}
@@ -0,0 +1,219 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+// the MPL3115A2Driver is a Driver
+var _ gobot.Driver = (*MPL3115A2Driver)(nil)
+
+// --------- HELPERS
+func initTestMPL3115A2Driver() (driver *MPL3115A2Driver) {
+	driver, _ = initTestMPL3115A2DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestMPL3115A2DriverWithStubbedAdaptor() (*MPL3115A2Driver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewMPL3115A2Driver(adaptor), adaptor
+}
+
+// mpl3115a2TestReadImpl answers the register read last requested, with the
+// given output bytes and a status that has new data available.
+func mpl3115a2TestReadImpl(adaptor *i2cTestAdaptor, data []byte) func([]byte) (int, error) {
+	return func(b []byte) (int, error) {
+		switch adaptor.written[len(adaptor.written)-1] {
+		case mpl3115a2RegisterWhoAmI:
+			b[0] = mpl3115a2WhoAmI
+		case mpl3115a2RegisterStatus:
+			b[0] = mpl3115a2StatusPTDR
+		case mpl3115a2RegisterPressureMSB:
+			copy(b, data)
+		}
+		return len(b), nil
+	}
+}
+
+// --------- TESTS
+
+func TestNewMPL3115A2Driver(t *testing.T) {
+	// Does it return a pointer to an instance of MPL3115A2Driver?
+	var mpl3115a2 interface{} = NewMPL3115A2Driver(newI2cTestAdaptor())
+	_, ok := mpl3115a2.(*MPL3115A2Driver)
+	if !ok {
+		t.Errorf("NewMPL3115A2Driver() should have returned a *MPL3115A2Driver")
+	}
+}
+
+func TestMPL3115A2DriverOptions(t *testing.T) {
+	d := NewMPL3115A2Driver(newI2cTestAdaptor(), WithBus(2),
+		WithMPL3115A2Mode(MPL3115A2Continuous), WithMPL3115A2Oversampling(MPL3115A2OS4))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.mode, MPL3115A2Continuous)
+	gobottest.Assert(t, d.oversampling, MPL3115A2OS4)
+
+	d = NewMPL3115A2Driver(newI2cTestAdaptor(), WithMPL3115A2Oversampling(8))
+	gobottest.Assert(t, d.oversampling, MPL3115A2OS128)
+}
+
+func TestMPL3115A2DriverStart(t *testing.T) {
+	mpl3115a2, adaptor := initTestMPL3115A2DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, nil)
+	gobottest.Assert(t, mpl3115a2.Start(), nil)
+	gobottest.Assert(t, adaptor.address, mpl3115a2Address)
+	gobottest.Assert(t, adaptor.written, []byte{0x0C, 0x13, 0x07, 0x26, 0x38})
+
+	mpl3115a2 = NewMPL3115A2Driver(adaptor, WithMPL3115A2Mode(MPL3115A2Continuous))
+	adaptor.written = []byte{}
+	gobottest.Assert(t, mpl3115a2.Start(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x0C, 0x13, 0x07, 0x26, 0x39})
+}
+
+func TestMPL3115A2DriverStartConnectError(t *testing.T) {
+	mpl3115a2, adaptor := initTestMPL3115A2DriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, mpl3115a2.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestMPL3115A2DriverStartWhoAmIError(t *testing.T) {
+	mpl3115a2, adaptor := initTestMPL3115A2DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		b[0] = 0xC5
+		return 1, nil
+	}
+	gobottest.Assert(t, mpl3115a2.Start(), errors.New("MPL3115A2 device not found (WHO_AM_I 0xC5)"))
+}
+
+func TestMPL3115A2DriverStartNotEnoughBytes(t *testing.T) {
+	mpl3115a2, _ := initTestMPL3115A2DriverWithStubbedAdaptor()
+	gobottest.Assert(t, mpl3115a2.Start(), ErrNotEnoughBytes)
+}
+
+func TestMPL3115A2DriverStartWriteError(t *testing.T) {
+	mpl3115a2, adaptor := initTestMPL3115A2DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, nil)
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		if len(b) > 1 {
+			return 0, errors.New("write error")
+		}
+		return len(b), nil
+	}
+	gobottest.Assert(t, mpl3115a2.Start(), errors.New("write error"))
+}
+
+func TestMPL3115A2DriverHalt(t *testing.T) {
+	mpl3115a2 := initTestMPL3115A2Driver()
+	gobottest.Assert(t, mpl3115a2.Halt(), nil)
+}
+
+func TestMPL3115A2DriverSetName(t *testing.T) {
+	mpl3115a2 := initTestMPL3115A2Driver()
+	mpl3115a2.SetName("TESTME")
+	gobottest.Assert(t, mpl3115a2.Name(), "TESTME")
+}
+
+func TestMPL3115A2DriverOneShotMeasurements(t *testing.T) {
+	mpl3115a2, adaptor := initTestMPL3115A2DriverWithStubbedAdaptor()
+	// 101325 Pa and 23.5 C.
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, []byte{0x62, 0xF3, 0x40, 0x17, 0x80})
+	mpl3115a2.Start()
+
+	adaptor.written = []byte{}
+	pressure, err := mpl3115a2.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(101325))
+	gobottest.Assert(t, adaptor.written, []byte{0x26, 0x3A, 0x00, 0x01})
+
+	temp, err := mpl3115a2.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(23.5))
+
+	// 1234.5625 m and -5.25 C.
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, []byte{0x04, 0xD2, 0x90, 0xFA, 0xC0})
+	adaptor.written = []byte{}
+	alt, err := mpl3115a2.Altitude()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt, float32(1234.5625))
+	gobottest.Assert(t, adaptor.written, []byte{0x26, 0xBA, 0x00, 0x01})
+
+	temp, err = mpl3115a2.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(-5.25))
+
+	// -10.5 m.
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, []byte{0xFF, 0xF5, 0x80, 0x17, 0x80})
+	alt, err = mpl3115a2.Altitude()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt, float32(-10.5))
+}
+
+func TestMPL3115A2DriverContinuousMeasurements(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	mpl3115a2 := NewMPL3115A2Driver(adaptor, WithMPL3115A2Mode(MPL3115A2Continuous))
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, []byte{0x62, 0xF3, 0x40, 0x17, 0x80})
+	mpl3115a2.Start()
+
+	// the barometer is already converting, so the latest sample is read.
+	adaptor.written = []byte{}
+	pressure, err := mpl3115a2.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(101325))
+	gobottest.Assert(t, adaptor.written, []byte{0x01})
+
+	// switching to the altimeter goes through standby.
+	adaptor.written = []byte{}
+	mpl3115a2.Altitude()
+	gobottest.Assert(t, adaptor.written, []byte{0x26, 0xB8, 0x26, 0xB9, 0x00, 0x01})
+
+	adaptor.written = []byte{}
+	mpl3115a2.Altitude()
+	gobottest.Assert(t, adaptor.written, []byte{0x01})
+}
+
+func TestMPL3115A2DriverDataNotReady(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	mpl3115a2 := NewMPL3115A2Driver(adaptor, WithMPL3115A2Oversampling(MPL3115A2OS1))
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, nil)
+	mpl3115a2.Start()
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		b[0] = 0x00
+		return len(b), nil
+	}
+	start := time.Now()
+	_, err := mpl3115a2.Pressure()
+	gobottest.Assert(t, err, ErrMPL3115A2DataNotReady)
+	gobottest.Assert(t, time.Since(start) >= mpl3115a2ConversionTime(MPL3115A2OS1), true)
+}
+
+func TestMPL3115A2DriverMeasurementsError(t *testing.T) {
+	mpl3115a2, adaptor := initTestMPL3115A2DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = mpl3115a2TestReadImpl(adaptor, nil)
+	mpl3115a2.Start()
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err := mpl3115a2.Pressure()
+	gobottest.Assert(t, err, errors.New("read error"))
+	_, err = mpl3115a2.Altitude()
+	gobottest.Assert(t, err, errors.New("read error"))
+	_, err = mpl3115a2.Temperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	_, err = mpl3115a2.Pressure()
+	gobottest.Assert(t, err, errors.New("write error"))
+}
+
+func TestMPL3115A2ConversionTime(t *testing.T) {
+	gobottest.Assert(t, mpl3115a2ConversionTime(MPL3115A2OS1), 6*time.Millisecond)
+	gobottest.Assert(t, mpl3115a2ConversionTime(MPL3115A2OS128), 514*time.Millisecond)
+}
@@ -0,0 +1,324 @@
+package i2c
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// the default address, with CSB low. It is 0x76 with CSB high.
+const ms5611Address = 0x77
+
+const ms5611CmdReset = 0x1E
+const ms5611CmdConvertD1 = 0x40
+const ms5611CmdConvertD2 = 0x50
+const ms5611CmdADCRead = 0x00
+const ms5611CmdPROMRead = 0xA0
+
+// the PROM holds 8 words: a factory word, the coefficients C1 to C6, and
+// the serial code with the crc in the low 4 bits.
+const ms5611PROMWords = 8
+
+// the reload of the PROM after a reset takes 2.8ms.
+const ms5611ResetDelay = 3 * time.Millisecond
+
+// MS5611Oversampling is the oversampling ratio of the conversions.
+type MS5611Oversampling uint8
+
+const (
+	// MS5611OSR256 is the fastest and noisiest oversampling ratio.
+	MS5611OSR256 MS5611Oversampling = iota
+	// MS5611OSR512 is the oversampling ratio of 512.
+	MS5611OSR512
+	// MS5611OSR1024 is the oversampling ratio of 1024.
+	MS5611OSR1024
+	// MS5611OSR2048 is the oversampling ratio of 2048.
+	MS5611OSR2048
+	// MS5611OSR4096 is the slowest and most precise oversampling ratio, the
+	// default, resolving 0.012 mbar.
+	MS5611OSR4096
+)
+
+// the maximum conversion times of the datasheet, rounded up.
+var ms5611ConversionDelays = [...]time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	3 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+}
+
+// MS5611Driver is the gobot driver for the TE Connectivity MS5611
+// barometric pressure sensor.
+// Device datasheet: https://www.te.com/commerce/DocumentDelivery/DDEController?Action=showdoc&DocId=Data+Sheet%7FMS5611-01BA03%7FB3%7Fpdf%7FEnglish%7FENG_DS_MS5611-01BA03_B3.pdf
+//
+// Start resets the sensor and reads its calibration coefficients from the
+// PROM, checking their crc. Each measurement then converts the temperature,
+// D2, and for the pressure the uncompensated pressure, D1, and compensates
+// them with the second order compensation of the datasheet.
+type MS5611Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	oversampling     MS5611Oversampling
+	prom             [ms5611PROMWords]uint16
+	seaLevelPressure float32
+	mutex            *sync.Mutex
+}
+
+// NewMS5611Driver creates a new driver with the i2c interface for the MS5611 device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver, 0x76 when CSB is high
+//		i2c.WithMS5611Oversampling(MS5611Oversampling):	oversampling ratio, defaults to MS5611OSR4096
+//
+func NewMS5611Driver(c Connector, options ...func(Config)) *MS5611Driver {
+	m := &MS5611Driver{
+		name:             gobot.DefaultName("MS5611"),
+		connector:        c,
+		Config:           NewConfig(),
+		oversampling:     MS5611OSR4096,
+		seaLevelPressure: bmp180SeaLevelPressure,
+		mutex:            &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	return m
+}
+
+// WithMS5611Oversampling option sets the oversampling ratio of the
+// conversions. Ratios above MS5611OSR4096 are ignored.
+func WithMS5611Oversampling(val MS5611Oversampling) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*MS5611Driver)
+		if ok && val <= MS5611OSR4096 {
+			d.oversampling = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *MS5611Driver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *MS5611Driver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *MS5611Driver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start resets the MS5611 and loads its calibration coefficients. It
+// returns ErrInvalidCrc when the crc of the PROM does not match, and
+// ErrInvalidCalibration when a coefficient is blank.
+func (d *MS5611Driver) Start() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(ms5611Address)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	return d.initialization()
+}
+
+func (d *MS5611Driver) initialization() (err error) {
+	if _, err = d.connection.Write([]byte{ms5611CmdReset}); err != nil {
+		return err
+	}
+	time.Sleep(ms5611ResetDelay)
+
+	var prom [ms5611PROMWords]uint16
+	for i := range prom {
+		var data []byte
+		if data, err = d.read(ms5611CmdPROMRead+byte(2*i), 2); err != nil {
+			return err
+		}
+		prom[i] = uint16(data[0])<<8 | uint16(data[1])
+	}
+	if ms5611Crc4(prom) != byte(prom[7]&0x000F) {
+		return ErrInvalidCrc
+	}
+	for _, c := range prom[1:7] {
+		if c == 0x0000 || c == 0xFFFF {
+			return ErrInvalidCalibration
+		}
+	}
+	d.prom = prom
+	return nil
+}
+
+// Halt halts the device.
+func (d *MS5611Driver) Halt() (err error) {
+	return nil
+}
+
+// SupportsHumidity returns false, the MS5611 has no humidity sensor.
+func (d *MS5611Driver) SupportsHumidity() bool {
+	return false
+}
+
+// SetOversampling sets the oversampling ratio of the conversions, or
+// returns ErrInvalidOversamplingMode for a ratio above MS5611OSR4096.
+func (d *MS5611Driver) SetOversampling(osr MS5611Oversampling) error {
+	if osr > MS5611OSR4096 {
+		return ErrInvalidOversamplingMode
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.oversampling = osr
+	return nil
+}
+
+// SetSeaLevelPressure sets the pressure at sea level, in pascals, with which
+// Altitude is calculated. Defaults to the standard 101325 Pa.
+func (d *MS5611Driver) SetSeaLevelPressure(p float32) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.seaLevelPressure = p
+}
+
+// Temperature returns the current temperature, in celsius degrees.
+func (d *MS5611Driver) Temperature() (temp float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var d2 uint32
+	if d2, err = d.convert(ms5611CmdConvertD2); err != nil {
+		return 0, err
+	}
+	t, _ := ms5611Compensate(d.prom, 0, d2)
+	return float32(t) / 100, nil
+}
+
+// Pressure returns the current barometric pressure, in pascals.
+func (d *MS5611Driver) Pressure() (pressure float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.pressure()
+}
+
+// Altitude returns the current altitude, in meters, based on the pressure
+// at sea level.
+func (d *MS5611Driver) Altitude() (alt float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var pressure float32
+	if pressure, err = d.pressure(); err != nil {
+		return 0, err
+	}
+	return float32(44330.0 * (1.0 - math.Pow(float64(pressure/d.seaLevelPressure), 1/5.255))), nil
+}
+
+func (d *MS5611Driver) pressure() (pressure float32, err error) {
+	var d1, d2 uint32
+	if d2, err = d.convert(ms5611CmdConvertD2); err != nil {
+		return 0, err
+	}
+	if d1, err = d.convert(ms5611CmdConvertD1); err != nil {
+		return 0, err
+	}
+	_, p := ms5611Compensate(d.prom, d1, d2)
+	return float32(p), nil
+}
+
+// convert starts a conversion with the command at the oversampling ratio,
+// and returns its 24 bit result.
+func (d *MS5611Driver) convert(cmd byte) (uint32, error) {
+	if _, err := d.connection.Write([]byte{cmd + byte(2*d.oversampling)}); err != nil {
+		return 0, err
+	}
+	time.Sleep(ms5611ConversionDelays[d.oversampling])
+
+	data, err := d.read(ms5611CmdADCRead, 3)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2]), nil
+}
+
+func (d *MS5611Driver) read(cmd byte, n int) ([]byte, error) {
+	if _, err := d.connection.Write([]byte{cmd}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if bytesRead != n {
+		return nil, ErrNotEnoughBytes
+	}
+	return buf, nil
+}
+
+// ms5611Compensate returns the temperature, in hundredths of celsius
+// degree, and the pressure, in pascals, of the digital pressure d1 and
+// temperature d2 values, compensated with the coefficients of the PROM to
+// the second order.
+func ms5611Compensate(prom [ms5611PROMWords]uint16, d1, d2 uint32) (temp int64, pressure int64) {
+	c1, c2, c3 := int64(prom[1]), int64(prom[2]), int64(prom[3])
+	c4, c5, c6 := int64(prom[4]), int64(prom[5]), int64(prom[6])
+
+	dT := int64(d2) - c5<<8
+	temp = 2000 + dT*c6/(1<<23)
+	off := c2<<16 + c4*dT/(1<<7)
+	sens := c1<<15 + c3*dT/(1<<8)
+
+	// the second order compensation, below 20 degrees.
+	if temp < 2000 {
+		t2 := dT * dT / (1 << 31)
+		off2 := 5 * (temp - 2000) * (temp - 2000) / 2
+		sens2 := 5 * (temp - 2000) * (temp - 2000) / 4
+		if temp < -1500 {
+			off2 += 7 * (temp + 1500) * (temp + 1500)
+			sens2 += 11 * (temp + 1500) * (temp + 1500) / 2
+		}
+		temp -= t2
+		off -= off2
+		sens -= sens2
+	}
+	pressure = (int64(d1)*sens/(1<<21) - off) / (1 << 15)
+	return temp, pressure
+}
+
+// ms5611Crc4 returns the crc 4 of the PROM, as given in the application
+// note AN520, computed with the crc bits of the last word cleared.
+func ms5611Crc4(prom [ms5611PROMWords]uint16) byte {
+	prom[7] &= 0xFF00
+	var rem uint16
+	for i := 0; i < 2*ms5611PROMWords; i++ {
+		if i%2 == 1 {
+			rem ^= prom[i>>1] & 0x00FF
+		} else {
+			rem ^= prom[i>>1] >> 8
+		}
+		for bit := 0; bit < 8; bit++ {
+			if rem&0x8000 != 0 {
+				rem = rem<<1 ^ 0x3000
+			} else {
+				rem <<= 1
+			}
+		}
+	}
+	return byte(rem >> 12 & 0x000F)
+}
@@ -0,0 +1,223 @@
+package i2c
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*MS5611Driver)(nil)
+
+// --------- HELPERS
+func initTestMS5611Driver() (driver *MS5611Driver) {
+	driver, _ = initTestMS5611DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestMS5611DriverWithStubbedAdaptor(options ...func(Config)) (*MS5611Driver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewMS5611Driver(adaptor, options...), adaptor
+}
+
+// ms5611TestPROM is the PROM of the example of the datasheet, whose crc
+// is 0.
+var ms5611TestPROM = [ms5611PROMWords]uint16{0, 40127, 36924, 23317, 23282, 33464, 28312, 0}
+
+// ms5611TestDevice simulates a MS5611, answering the PROM reads with its
+// PROM and the ADC reads with d1 or d2, after their conversion.
+type ms5611TestDevice struct {
+	prom      [ms5611PROMWords]uint16
+	d1        uint32
+	d2        uint32
+	cmd       byte
+	converted byte
+}
+
+// newMS5611TestDevice returns a test device with the values of the example
+// of the datasheet, 20.07 degrees and 100009 Pa.
+func newMS5611TestDevice(adaptor *i2cTestAdaptor) *ms5611TestDevice {
+	dev := &ms5611TestDevice{prom: ms5611TestPROM, d1: 9085466, d2: 8569150}
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		dev.cmd = b[0]
+		if b[0]&0xE0 == ms5611CmdConvertD1 {
+			dev.converted = b[0]
+		}
+		return len(b), nil
+	}
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		var val uint32
+		switch {
+		case dev.cmd&0xF0 == ms5611CmdPROMRead:
+			val = uint32(dev.prom[dev.cmd&0x0F>>1])
+		case dev.cmd == ms5611CmdADCRead && dev.converted&0xF0 == ms5611CmdConvertD1:
+			val = dev.d1
+		case dev.cmd == ms5611CmdADCRead && dev.converted&0xF0 == ms5611CmdConvertD2:
+			val = dev.d2
+		}
+		for i := range b {
+			b[i] = byte(val >> uint(8*(len(b)-1-i)))
+		}
+		return len(b), nil
+	}
+	return dev
+}
+
+// --------- TESTS
+
+func TestNewMS5611Driver(t *testing.T) {
+	// Does it return a pointer to an instance of MS5611Driver?
+	var ms5611 interface{} = NewMS5611Driver(newI2cTestAdaptor())
+	_, ok := ms5611.(*MS5611Driver)
+	if !ok {
+		t.Errorf("NewMS5611Driver() should have returned a *MS5611Driver")
+	}
+
+	d := NewMS5611Driver(newI2cTestAdaptor())
+	gobottest.Refute(t, d.Connection(), nil)
+	gobottest.Assert(t, d.oversampling, MS5611OSR4096)
+	gobottest.Assert(t, d.SupportsHumidity(), false)
+}
+
+func TestMS5611DriverOptions(t *testing.T) {
+	d := NewMS5611Driver(newI2cTestAdaptor(), WithBus(2), WithMS5611Oversampling(MS5611OSR256))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.oversampling, MS5611OSR256)
+
+	d = NewMS5611Driver(newI2cTestAdaptor(), WithMS5611Oversampling(MS5611Oversampling(5)))
+	gobottest.Assert(t, d.oversampling, MS5611OSR4096)
+}
+
+func TestMS5611DriverSetName(t *testing.T) {
+	d := initTestMS5611Driver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "TESTME"), true)
+}
+
+func TestMS5611DriverStart(t *testing.T) {
+	d, adaptor := initTestMS5611DriverWithStubbedAdaptor()
+	newMS5611TestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, adaptor.address, 0x77)
+	gobottest.Assert(t, d.prom, ms5611TestPROM)
+	// the reset, then the 8 words of the PROM.
+	gobottest.Assert(t, adaptor.written, []byte{0x1E, 0xA0, 0xA2, 0xA4, 0xA6, 0xA8, 0xAA, 0xAC, 0xAE})
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestMS5611DriverStartInvalidCrc(t *testing.T) {
+	d, adaptor := initTestMS5611DriverWithStubbedAdaptor()
+	dev := newMS5611TestDevice(adaptor)
+	dev.prom[3]++
+	gobottest.Assert(t, d.Start(), ErrInvalidCrc)
+
+	dev.prom = ms5611TestPROM
+	dev.prom[7] = 0x0001
+	gobottest.Assert(t, d.Start(), ErrInvalidCrc)
+}
+
+func TestMS5611DriverStartInvalidCalibration(t *testing.T) {
+	d, adaptor := initTestMS5611DriverWithStubbedAdaptor()
+	dev := newMS5611TestDevice(adaptor)
+	// a blank PROM has a valid crc.
+	dev.prom = [ms5611PROMWords]uint16{}
+	gobottest.Assert(t, d.Start(), ErrInvalidCalibration)
+}
+
+func TestMS5611DriverStartError(t *testing.T) {
+	d, adaptor := initTestMS5611DriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+
+	d, adaptor = initTestMS5611DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 1, nil
+	}
+	gobottest.Assert(t, d.Start(), ErrNotEnoughBytes)
+}
+
+func TestMS5611Crc4(t *testing.T) {
+	// the example of the application note AN520.
+	gobottest.Assert(t, ms5611Crc4([ms5611PROMWords]uint16{0x3132, 0x3334, 0x3536, 0x3738, 0x3940, 0x4142, 0x4344, 0x4500}), byte(0xB))
+	// the crc bits are not part of the crc.
+	gobottest.Assert(t, ms5611Crc4([ms5611PROMWords]uint16{0x3132, 0x3334, 0x3536, 0x3738, 0x3940, 0x4142, 0x4344, 0x450B}), byte(0xB))
+	gobottest.Assert(t, ms5611Crc4(ms5611TestPROM), byte(0x0))
+}
+
+func TestMS5611Compensate(t *testing.T) {
+	// the example of the datasheet.
+	temp, pressure := ms5611Compensate(ms5611TestPROM, 9085466, 8569150)
+	gobottest.Assert(t, temp, int64(2007))
+	gobottest.Assert(t, pressure, int64(100009))
+
+	// below 20 degrees, with the second order.
+	temp, pressure = ms5611Compensate(ms5611TestPROM, 9085466, 8000000)
+	gobottest.Assert(t, temp, int64(-61))
+	gobottest.Assert(t, pressure, int64(95989))
+
+	// below -15 degrees, with the low temperature terms.
+	temp, pressure = ms5611Compensate(ms5611TestPROM, 9085466, 7000000)
+	gobottest.Assert(t, temp, int64(-4430))
+	gobottest.Assert(t, pressure, int64(85696))
+}
+
+func TestMS5611DriverMeasurements(t *testing.T) {
+	d, adaptor := initTestMS5611DriverWithStubbedAdaptor()
+	newMS5611TestDevice(adaptor)
+	d.Start()
+
+	adaptor.written = []byte{}
+	temp, err := d.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(20.07))
+	// the D2 conversion at OSR 4096, then the ADC read.
+	gobottest.Assert(t, adaptor.written, []byte{0x58, 0x00})
+
+	adaptor.written = []byte{}
+	pressure, err := d.Pressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(100009))
+	gobottest.Assert(t, adaptor.written, []byte{0x58, 0x00, 0x48, 0x00})
+
+	alt, err := d.Altitude()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alt > 110 && alt < 111, true)
+	d.SetSeaLevelPressure(100009)
+	alt, _ = d.Altitude()
+	gobottest.Assert(t, alt, float32(0))
+}
+
+func TestMS5611DriverSetOversampling(t *testing.T) {
+	d, adaptor := initTestMS5611DriverWithStubbedAdaptor()
+	newMS5611TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetOversampling(MS5611Oversampling(5)), ErrInvalidOversamplingMode)
+	gobottest.Assert(t, d.SetOversampling(MS5611OSR256), nil)
+
+	adaptor.written = []byte{}
+	d.Pressure()
+	gobottest.Assert(t, adaptor.written, []byte{0x50, 0x00, 0x40, 0x00})
+}
+
+func TestMS5611DriverMeasurementError(t *testing.T) {
+	d, adaptor := initTestMS5611DriverWithStubbedAdaptor()
+	newMS5611TestDevice(adaptor)
+	d.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err := d.Temperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+	_, err = d.Pressure()
+	gobottest.Assert(t, err, errors.New("read error"))
+	_, err = d.Altitude()
+	gobottest.Assert(t, err, errors.New("read error"))
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	_, err = d.Pressure()
+	gobottest.Assert(t, err, errors.New("write error"))
+}
@@ -136,24 +136,16 @@ func (p *PCA9685Driver) SetPWM(channel int, on uint16, off uint16) (err error) {
 
 // SetPWMFreq sets the PWM frequency in Hz
 func (p *PCA9685Driver) SetPWMFreq(freq float32) error {
-	// IC oscillator frequency is 25 MHz
-	var prescalevel float32 = 25000000
-	// Find frequency of PWM waveform
-	prescalevel /= 4096
-	// Ratio between desired frequency and maximum
-	prescalevel /= freq
-	prescalevel -= 1
-	// Round value to nearest whole
-	prescale := byte(prescalevel + 0.5)
+	prescale := pca9685Prescale(freq)
 
 	if _, err := p.connection.Write([]byte{byte(PCA9685_MODE1)}); err != nil {
 		return err
 	}
 	data := make([]byte, 1)
-	oldmode, err := p.connection.Read(data)
-	if err != nil {
+	if _, err := p.connection.Read(data); err != nil {
 		return err
 	}
+	oldmode := data[0]
 
 	// Put oscillator in sleep mode, clear bit 7 here to avoid overwriting
 	// previous setting
@@ -179,6 +171,20 @@ func (p *PCA9685Driver) SetPWMFreq(freq float32) error {
 	return nil
 }
 
+// pca9685Prescale returns the prescale register value for the PWM frequency
+// in Hz, from 24 Hz to 1526 Hz.
+func pca9685Prescale(freq float32) byte {
+	// IC oscillator frequency is 25 MHz
+	var prescalevel float32 = 25000000
+	// Find frequency of PWM waveform
+	prescalevel /= 4096
+	// Ratio between desired frequency and maximum
+	prescalevel /= freq
+	prescalevel -= 1
+	// Round value to nearest whole
+	return byte(prescalevel + 0.5)
+}
+
 // PwmWrite writes a PWM signal to the specified channel aka "pin".
 // Value values are from 0-255, to conform to the PwmWriter interface.
 // If you need finer control, please look at SetPWM().
@@ -201,6 +207,13 @@ func (p *PCA9685Driver) ServoWrite(pin string, val byte) (err error) {
 	if err != nil {
 		return
 	}
-	v := gobot.ToScale(gobot.FromScale(float64(val), 0, 180), 200, 500)
-	return p.SetPWM(i, 0, uint16(v))
+	return p.Servo(i, float32(val))
+}
+
+// Servo moves the servo on the given channel to the angle, from 0 to 180
+// degrees, with the same pulses as ServoWrite. The PWM frequency is expected
+// to be set to 60 Hz.
+func (p *PCA9685Driver) Servo(channel int, angle float32) (err error) {
+	v := gobot.ToScale(gobot.FromScale(float64(angle), 0, 180), 200, 500)
+	return p.SetPWM(channel, 0, uint16(v))
 }
@@ -93,6 +93,16 @@ func TestPCA9685DriverSetPWMError(t *testing.T) {
 	gobottest.Assert(t, pca.SetPWM(0, 0, 256), errors.New("write error"))
 }
 
+func TestPCA9685DriverServo(t *testing.T) {
+	pca, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+	gobottest.Assert(t, pca.Start(), nil)
+
+	adaptor.written = []byte{}
+	gobottest.Assert(t, pca.Servo(1, 90), nil)
+	// 350 ticks, from 200 at 0 to 500 at 180 degrees
+	gobottest.Assert(t, adaptor.written, []byte{PCA9685_LED0_ON_L + 4, 0x00, 0x00, 0x5E, 0x01})
+}
+
 func TestPCA9685DriverSetPWMFreq(t *testing.T) {
 	pca, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
 	gobottest.Assert(t, pca.Start(), nil)
@@ -104,6 +114,34 @@ func TestPCA9685DriverSetPWMFreq(t *testing.T) {
 	gobottest.Assert(t, pca.SetPWMFreq(60), nil)
 }
 
+func TestPCA9685DriverSetPWMFreqMode(t *testing.T) {
+	pca, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+	gobottest.Assert(t, pca.Start(), nil)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x20})
+		return 1, nil
+	}
+	adaptor.written = []byte{}
+	gobottest.Assert(t, pca.SetPWMFreq(50), nil)
+	// the previous mode is the one read, not the count of bytes read.
+	gobottest.Assert(t, adaptor.written, []byte{
+		PCA9685_MODE1,
+		PCA9685_MODE1, 0x30,
+		PCA9685_PRESCALE, 121,
+		PCA9685_MODE1, 0x20,
+		PCA9685_MODE1, 0xA1,
+	})
+}
+
+func TestPCA9685Prescale(t *testing.T) {
+	gobottest.Assert(t, pca9685Prescale(24), byte(253))
+	gobottest.Assert(t, pca9685Prescale(50), byte(121))
+	gobottest.Assert(t, pca9685Prescale(60), byte(101))
+	gobottest.Assert(t, pca9685Prescale(1000), byte(5))
+	gobottest.Assert(t, pca9685Prescale(1526), byte(3))
+}
+
 func TestPCA9685DriverSetPWMFreqReadError(t *testing.T) {
 	pca, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
 	gobottest.Assert(t, pca.Start(), nil)
@@ -0,0 +1,134 @@
+package i2c
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrUnknownRegister is returned when a register is not in the RegisterMap.
+var ErrUnknownRegister = errors.New("Unknown register")
+
+// ErrInvalidRegisterWidth is returned when a register is not 1, 2 or 4 bytes
+// wide.
+var ErrInvalidRegisterWidth = errors.New("Invalid register width")
+
+// ErrRegisterValueTooLarge is returned when a value written to a register
+// does not fit in its width.
+var ErrRegisterValueTooLarge = errors.New("Value too large for the register")
+
+// Register is a register of a device in a RegisterMap.
+type Register struct {
+	Name    string
+	Address uint8
+	// Width is the size of the register, in bytes: 1, 2 or 4.
+	Width int
+	// Order is the byte order of the registers wider than a byte, defaults
+	// to binary.BigEndian.
+	Order binary.ByteOrder
+}
+
+// RegisterMap describes the registers of a device by name, so that a driver
+// can read and write them with ReadReg and WriteReg rather than with byte
+// constants and buffers, and list them, e.g. to dump them when debugging.
+// The registers are read by writing their address then reading back their
+// width, and written as their address followed by the value.
+type RegisterMap struct {
+	registers []Register
+	index     map[string]int
+}
+
+// NewRegisterMap returns a new RegisterMap of the registers, whose names
+// must be unique.
+func NewRegisterMap(registers ...Register) *RegisterMap {
+	m := &RegisterMap{
+		registers: make([]Register, len(registers)),
+		index:     make(map[string]int, len(registers)),
+	}
+	for i, r := range registers {
+		if r.Order == nil {
+			r.Order = binary.BigEndian
+		}
+		m.registers[i] = r
+		m.index[r.Name] = i
+	}
+	return m
+}
+
+// Registers returns the registers of the map, in the order they were given.
+func (m *RegisterMap) Registers() []Register {
+	return append([]Register{}, m.registers...)
+}
+
+// Register returns the register with the name, and whether it is in the map.
+func (m *RegisterMap) Register(name string) (Register, bool) {
+	i, ok := m.index[name]
+	if !ok {
+		return Register{}, false
+	}
+	return m.registers[i], true
+}
+
+// ReadReg reads the value of the register with the name from the device of
+// the connection.
+func (m *RegisterMap) ReadReg(c Connection, name string) (uint32, error) {
+	r, err := m.register(name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.Write([]byte{r.Address}); err != nil {
+		return 0, err
+	}
+	buf := make([]byte, r.Width)
+	bytesRead, err := c.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if bytesRead != r.Width {
+		return 0, ErrNotEnoughBytes
+	}
+	switch r.Width {
+	case 1:
+		return uint32(buf[0]), nil
+	case 2:
+		return uint32(r.Order.Uint16(buf)), nil
+	}
+	return r.Order.Uint32(buf), nil
+}
+
+// WriteReg writes the value to the register with the name of the device of
+// the connection, or returns ErrRegisterValueTooLarge if it does not fit in
+// the register.
+func (m *RegisterMap) WriteReg(c Connection, name string, val uint32) error {
+	r, err := m.register(name)
+	if err != nil {
+		return err
+	}
+	if r.Width < 4 && val >= 1<<uint(8*r.Width) {
+		return ErrRegisterValueTooLarge
+	}
+	buf := make([]byte, 1+r.Width)
+	buf[0] = r.Address
+	switch r.Width {
+	case 1:
+		buf[1] = byte(val)
+	case 2:
+		r.Order.PutUint16(buf[1:], uint16(val))
+	case 4:
+		r.Order.PutUint32(buf[1:], val)
+	}
+	_, err = c.Write(buf)
+	return err
+}
+
+// register returns the register with the name, if it is in the map and has
+// a supported width.
+func (m *RegisterMap) register(name string) (Register, error) {
+	r, ok := m.Register(name)
+	if !ok {
+		return r, ErrUnknownRegister
+	}
+	if r.Width != 1 && r.Width != 2 && r.Width != 4 {
+		return r, ErrInvalidRegisterWidth
+	}
+	return r, nil
+}
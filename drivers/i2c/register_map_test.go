@@ -0,0 +1,126 @@
+package i2c
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+var testRegisterMap = NewRegisterMap(
+	Register{Name: "status", Address: 0x01, Width: 1},
+	Register{Name: "big", Address: 0x02, Width: 2},
+	Register{Name: "little", Address: 0x04, Width: 2, Order: binary.LittleEndian},
+	Register{Name: "long", Address: 0x06, Width: 4},
+	Register{Name: "odd", Address: 0x0A, Width: 3},
+)
+
+func initTestRegisterMapAdaptor() *i2cTestAdaptor {
+	adaptor := newI2cTestAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x12, 0x34, 0x56, 0x78})
+		return len(b), nil
+	}
+	return adaptor
+}
+
+func TestRegisterMapRegisters(t *testing.T) {
+	registers := testRegisterMap.Registers()
+	gobottest.Assert(t, len(registers), 5)
+	gobottest.Assert(t, registers[0].Name, "status")
+	gobottest.Assert(t, registers[4].Name, "odd")
+	// the byte order defaults to big-endian.
+	gobottest.Assert(t, registers[1].Order, binary.ByteOrder(binary.BigEndian))
+
+	r, ok := testRegisterMap.Register("little")
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, r.Address, uint8(0x04))
+	_, ok = testRegisterMap.Register("none")
+	gobottest.Assert(t, ok, false)
+}
+
+func TestRegisterMapReadReg(t *testing.T) {
+	adaptor := initTestRegisterMapAdaptor()
+	tests := map[string]uint32{
+		"status": 0x12,
+		"big":    0x1234,
+		"little": 0x3412,
+		"long":   0x12345678,
+	}
+	for name, want := range tests {
+		adaptor.written = []byte{}
+		r, _ := testRegisterMap.Register(name)
+		val, err := testRegisterMap.ReadReg(adaptor, name)
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, val, want)
+		gobottest.Assert(t, adaptor.written, []byte{r.Address})
+	}
+}
+
+func TestRegisterMapReadRegError(t *testing.T) {
+	adaptor := initTestRegisterMapAdaptor()
+	_, err := testRegisterMap.ReadReg(adaptor, "none")
+	gobottest.Assert(t, err, ErrUnknownRegister)
+	_, err = testRegisterMap.ReadReg(adaptor, "odd")
+	gobottest.Assert(t, err, ErrInvalidRegisterWidth)
+	gobottest.Assert(t, len(adaptor.written), 0)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 1, nil
+	}
+	_, err = testRegisterMap.ReadReg(adaptor, "big")
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = testRegisterMap.ReadReg(adaptor, "big")
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestRegisterMapWriteReg(t *testing.T) {
+	adaptor := initTestRegisterMapAdaptor()
+	tests := []struct {
+		name    string
+		val     uint32
+		written []byte
+	}{
+		{"status", 0xAB, []byte{0x01, 0xAB}},
+		{"big", 0xABCD, []byte{0x02, 0xAB, 0xCD}},
+		{"little", 0xABCD, []byte{0x04, 0xCD, 0xAB}},
+		{"long", 0x01020304, []byte{0x06, 0x01, 0x02, 0x03, 0x04}},
+	}
+	for _, test := range tests {
+		adaptor.written = []byte{}
+		gobottest.Assert(t, testRegisterMap.WriteReg(adaptor, test.name, test.val), nil)
+		gobottest.Assert(t, adaptor.written, test.written)
+	}
+}
+
+func TestRegisterMapWriteRegError(t *testing.T) {
+	adaptor := initTestRegisterMapAdaptor()
+	gobottest.Assert(t, testRegisterMap.WriteReg(adaptor, "none", 0), ErrUnknownRegister)
+	gobottest.Assert(t, testRegisterMap.WriteReg(adaptor, "odd", 0), ErrInvalidRegisterWidth)
+	gobottest.Assert(t, testRegisterMap.WriteReg(adaptor, "status", 0x100), ErrRegisterValueTooLarge)
+	gobottest.Assert(t, testRegisterMap.WriteReg(adaptor, "big", 0x10000), ErrRegisterValueTooLarge)
+	gobottest.Assert(t, len(adaptor.written), 0)
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, testRegisterMap.WriteReg(adaptor, "status", 1), errors.New("write error"))
+}
+
+func TestBMP180Registers(t *testing.T) {
+	// the coefficients follow each other, in the order of the calibration.
+	for i, name := range []string{"AC1", "AC2", "AC3", "AC4", "AC5", "AC6", "B1", "B2", "MB", "MC", "MD"} {
+		r, ok := bmp180Registers.Register(name)
+		gobottest.Assert(t, ok, true)
+		gobottest.Assert(t, r.Address, uint8(0xAA+2*i))
+	}
+	r, _ := bmp180Registers.Register("ctrl_meas")
+	gobottest.Assert(t, r.Address, uint8(0xF4))
+	r, _ = bmp180Registers.Register("out")
+	gobottest.Assert(t, r.Width, 2)
+}
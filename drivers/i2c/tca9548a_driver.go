@@ -0,0 +1,233 @@
+package i2c
+
+import (
+	"fmt"
+	"sync"
+
+	"gobot.io/x/gobot"
+)
+
+const tca9548aAddress = 0x70
+
+const tca9548aChannels = 8
+
+// TCA9548ADriver is the gobot driver for the TI 8-channel i2c multiplexer
+// TCA9548A, which lets several devices with the same address, e.g. several
+// BMP180, share a bus. Each device is created with the Connector returned by
+// Channel, instead of the adaptor.
+// Device datasheet: http://www.ti.com/lit/ds/symlink/tca9548a.pdf
+type TCA9548ADriver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	channel int
+	mutex   *sync.Mutex
+}
+
+// NewTCA9548ADriver creates a new driver with the i2c interface for the TCA9548A device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//
+func NewTCA9548ADriver(c Connector, options ...func(Config)) *TCA9548ADriver {
+	t := &TCA9548ADriver{
+		name:      gobot.DefaultName("TCA9548A"),
+		connector: c,
+		Config:    NewConfig(),
+		channel:   -1,
+		mutex:     &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	// TODO: expose commands to API
+	return t
+}
+
+// Name returns the name of the device.
+func (d *TCA9548ADriver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *TCA9548ADriver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *TCA9548ADriver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start initializes the TCA9548A. It must be started before the drivers of
+// the devices behind it.
+func (d *TCA9548ADriver) Start() (err error) {
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(tca9548aAddress)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.channel = -1
+	d.connection, err = d.connector.GetConnection(address, bus)
+	return err
+}
+
+// Halt halts the device.
+func (d *TCA9548ADriver) Halt() (err error) {
+	return nil
+}
+
+// SelectChannel connects the given channel, from 0 to 7, to the bus and
+// disconnects the others.
+func (d *TCA9548ADriver) SelectChannel(channel int) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.selectChannel(channel)
+}
+
+// Channel returns a Connector for the devices on the given channel, from 0
+// to 7. Every transaction through its connections first selects the channel
+// on the TCA9548A, when it is not already selected.
+func (d *TCA9548ADriver) Channel(channel int) Connector {
+	return &tca9548aChannel{mux: d, channel: channel}
+}
+
+func (d *TCA9548ADriver) selectChannel(channel int) error {
+	if channel < 0 || channel >= tca9548aChannels {
+		return fmt.Errorf("Invalid TCA9548A channel %d", channel)
+	}
+	if d.connection == nil {
+		return ErrNotReady
+	}
+	if channel == d.channel {
+		return nil
+	}
+	if _, err := d.connection.Write([]byte{1 << uint(channel)}); err != nil {
+		d.channel = -1
+		return err
+	}
+	d.channel = channel
+	return nil
+}
+
+// tca9548aChannel is the Connector of a channel of the TCA9548A, its devices
+// are on the same bus as the multiplexer.
+type tca9548aChannel struct {
+	mux     *TCA9548ADriver
+	channel int
+}
+
+func (c *tca9548aChannel) GetConnection(address int, bus int) (Connection, error) {
+	conn, err := c.mux.connector.GetConnection(address, bus)
+	if err != nil {
+		return nil, err
+	}
+	return &tca9548aConnection{conn: conn, channel: c}, nil
+}
+
+func (c *tca9548aChannel) GetDefaultBus() int {
+	return c.mux.GetBusOrDefault(c.mux.connector.GetDefaultBus())
+}
+
+// tca9548aConnection is a Connection to a device behind a TCA9548A, which
+// selects its channel before each transaction.
+type tca9548aConnection struct {
+	conn    Connection
+	channel *tca9548aChannel
+}
+
+func (c *tca9548aConnection) do(f func() error) error {
+	c.channel.mux.mutex.Lock()
+	defer c.channel.mux.mutex.Unlock()
+
+	if err := c.channel.mux.selectChannel(c.channel.channel); err != nil {
+		return err
+	}
+	return f()
+}
+
+// Read data from the device.
+func (c *tca9548aConnection) Read(data []byte) (read int, err error) {
+	err = c.do(func() (err error) {
+		read, err = c.conn.Read(data)
+		return
+	})
+	return
+}
+
+// Write data to the device.
+func (c *tca9548aConnection) Write(data []byte) (written int, err error) {
+	err = c.do(func() (err error) {
+		written, err = c.conn.Write(data)
+		return
+	})
+	return
+}
+
+// Close the connection to the device.
+func (c *tca9548aConnection) Close() error {
+	return c.conn.Close()
+}
+
+// ReadByte reads a single byte from the device.
+func (c *tca9548aConnection) ReadByte() (val byte, err error) {
+	err = c.do(func() (err error) {
+		val, err = c.conn.ReadByte()
+		return
+	})
+	return
+}
+
+// ReadByteData reads a byte value for a register on the device.
+func (c *tca9548aConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	err = c.do(func() (err error) {
+		val, err = c.conn.ReadByteData(reg)
+		return
+	})
+	return
+}
+
+// ReadWordData reads a word value for a register on the device.
+func (c *tca9548aConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	err = c.do(func() (err error) {
+		val, err = c.conn.ReadWordData(reg)
+		return
+	})
+	return
+}
+
+// WriteByte writes a single byte to the device.
+func (c *tca9548aConnection) WriteByte(val byte) error {
+	return c.do(func() error {
+		return c.conn.WriteByte(val)
+	})
+}
+
+// WriteByteData writes a byte value to a register on the device.
+func (c *tca9548aConnection) WriteByteData(reg uint8, val uint8) error {
+	return c.do(func() error {
+		return c.conn.WriteByteData(reg, val)
+	})
+}
+
+// WriteWordData writes a word value to a register on the device.
+func (c *tca9548aConnection) WriteWordData(reg uint8, val uint16) error {
+	return c.do(func() error {
+		return c.conn.WriteWordData(reg, val)
+	})
+}
+
+// WriteBlockData writes a block of bytes to a register on the device.
+func (c *tca9548aConnection) WriteBlockData(reg uint8, b []byte) error {
+	return c.do(func() error {
+		return c.conn.WriteBlockData(reg, b)
+	})
+}
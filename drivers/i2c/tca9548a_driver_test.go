@@ -0,0 +1,157 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+// the TCA9548ADriver is a Driver
+var _ gobot.Driver = (*TCA9548ADriver)(nil)
+
+// --------- HELPERS
+func initTestTCA9548ADriver() (driver *TCA9548ADriver) {
+	driver, _ = initTestTCA9548ADriverWithStubbedAdaptor()
+	return
+}
+
+func initTestTCA9548ADriverWithStubbedAdaptor() (*TCA9548ADriver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewTCA9548ADriver(adaptor), adaptor
+}
+
+// --------- TESTS
+
+func TestNewTCA9548ADriver(t *testing.T) {
+	// Does it return a pointer to an instance of TCA9548ADriver?
+	var tca9548a interface{} = NewTCA9548ADriver(newI2cTestAdaptor())
+	_, ok := tca9548a.(*TCA9548ADriver)
+	if !ok {
+		t.Errorf("NewTCA9548ADriver() should have returned a *TCA9548ADriver")
+	}
+}
+
+func TestTCA9548ADriverStart(t *testing.T) {
+	tca9548a, adaptor := initTestTCA9548ADriverWithStubbedAdaptor()
+	gobottest.Assert(t, tca9548a.Start(), nil)
+	gobottest.Assert(t, adaptor.address, tca9548aAddress)
+}
+
+func TestTCA9548ADriverStartConnectError(t *testing.T) {
+	tca9548a, adaptor := initTestTCA9548ADriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, tca9548a.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestTCA9548ADriverHalt(t *testing.T) {
+	tca9548a := initTestTCA9548ADriver()
+	gobottest.Assert(t, tca9548a.Halt(), nil)
+}
+
+func TestTCA9548ADriverSetName(t *testing.T) {
+	tca9548a := initTestTCA9548ADriver()
+	tca9548a.SetName("TESTME")
+	gobottest.Assert(t, tca9548a.Name(), "TESTME")
+}
+
+func TestTCA9548ADriverSelectChannel(t *testing.T) {
+	tca9548a, adaptor := initTestTCA9548ADriverWithStubbedAdaptor()
+	gobottest.Assert(t, tca9548a.SelectChannel(0), ErrNotReady)
+	tca9548a.Start()
+
+	gobottest.Assert(t, tca9548a.SelectChannel(3), nil)
+	gobottest.Assert(t, tca9548a.SelectChannel(7), nil)
+	// an already selected channel is not selected again.
+	gobottest.Assert(t, tca9548a.SelectChannel(7), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x08, 0x80})
+
+	gobottest.Assert(t, tca9548a.SelectChannel(8), errors.New("Invalid TCA9548A channel 8"))
+	gobottest.Assert(t, tca9548a.SelectChannel(-1), errors.New("Invalid TCA9548A channel -1"))
+}
+
+func TestTCA9548ADriverSelectChannelError(t *testing.T) {
+	tca9548a, adaptor := initTestTCA9548ADriverWithStubbedAdaptor()
+	tca9548a.Start()
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, tca9548a.SelectChannel(1), errors.New("write error"))
+
+	// the channel is selected again once the bus recovers.
+	adaptor.i2cWriteImpl = func(b []byte) (int, error) {
+		return len(b), nil
+	}
+	adaptor.written = []byte{}
+	gobottest.Assert(t, tca9548a.SelectChannel(1), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x02})
+}
+
+func TestTCA9548ADriverChannels(t *testing.T) {
+	tca9548a, adaptor := initTestTCA9548ADriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = bmp180TestReadImpl(adaptor)
+	bmp180A := NewBMP180Driver(tca9548a.Channel(0))
+	bmp180B := NewBMP180Driver(tca9548a.Channel(1))
+	gobottest.Assert(t, tca9548a.Start(), nil)
+	gobottest.Assert(t, bmp180A.Start(), nil)
+	gobottest.Assert(t, bmp180B.Start(), nil)
+	gobottest.Assert(t, adaptor.address, bmp180Address)
+	gobottest.Assert(t, adaptor.written, []byte{
+		0x01, bmp180RegisterChipID, bmp180RegisterAC1MSB,
+		0x02, bmp180RegisterChipID, bmp180RegisterAC1MSB,
+	})
+
+	adaptor.written = []byte{}
+	temp, err := bmp180A.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+	temp, err = bmp180B.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(15.0))
+	gobottest.Assert(t, adaptor.written, []byte{
+		0x01, bmp180RegisterCtl, bmp180CmdTemp, bmp180RegisterTempMSB,
+		0x02, bmp180RegisterCtl, bmp180CmdTemp, bmp180RegisterTempMSB,
+	})
+}
+
+func TestTCA9548ADriverChannelConnection(t *testing.T) {
+	tca9548a, adaptor := initTestTCA9548ADriverWithStubbedAdaptor()
+	tca9548a.Start()
+	channel := tca9548a.Channel(2)
+	gobottest.Assert(t, channel.GetDefaultBus(), adaptor.GetDefaultBus())
+	conn, err := channel.GetConnection(0x40, 0)
+	gobottest.Assert(t, err, nil)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x34, 0x12})
+		return len(b), nil
+	}
+	b, _ := conn.ReadByte()
+	gobottest.Assert(t, b, uint8(0x34))
+	b, _ = conn.ReadByteData(0x01)
+	gobottest.Assert(t, b, uint8(0x34))
+	w, _ := conn.ReadWordData(0x01)
+	gobottest.Assert(t, w, uint16(0x1234))
+	gobottest.Assert(t, conn.WriteByte(0x05), nil)
+	gobottest.Assert(t, conn.WriteByteData(0x06, 0x07), nil)
+	gobottest.Assert(t, conn.WriteWordData(0x08, 0x0A09), nil)
+	gobottest.Assert(t, conn.WriteBlockData(0x0B, []byte{0x0C}), nil)
+	gobottest.Assert(t, conn.Close(), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C})
+}
+
+func TestTCA9548ADriverChannelErrors(t *testing.T) {
+	tca9548a, adaptor := initTestTCA9548ADriverWithStubbedAdaptor()
+	conn, _ := tca9548a.Channel(9).GetConnection(0x40, 0)
+	_, err := conn.Read([]byte{0})
+	gobottest.Assert(t, err, errors.New("Invalid TCA9548A channel 9"))
+
+	conn, _ = tca9548a.Channel(1).GetConnection(0x40, 0)
+	_, err = conn.Write([]byte{0})
+	gobottest.Assert(t, err, ErrNotReady)
+
+	adaptor.Testi2cConnectErr(true)
+	_, err = tca9548a.Channel(1).GetConnection(0x40, 0)
+	gobottest.Assert(t, err, errors.New("Invalid i2c connection"))
+}
@@ -0,0 +1,399 @@
+package i2c
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// the default address, with ADD0 to ground. It is 0x49 to V+, 0x4A to SDA
+// and 0x4B to SCL.
+const tmp102Address = 0x48
+
+const tmp102RegisterTemp = 0x00
+const tmp102RegisterConfig = 0x01
+const tmp102RegisterLow = 0x02
+const tmp102RegisterHigh = 0x03
+
+// the bits of the configuration register. The resolution bits always read
+// 1, and the one-shot bit is only written in shutdown.
+const tmp102ConfigResolution = 0x6000
+const tmp102ConfigFaultQueueShift = 11
+const tmp102ConfigFaultQueue = 0x1800
+const tmp102ConfigActiveHigh = 0x0400
+const tmp102ConfigInterrupt = 0x0200
+const tmp102ConfigRateShift = 6
+const tmp102ConfigRate = 0x00C0
+const tmp102ConfigAlert = 0x0020
+const tmp102ConfigExtended = 0x0010
+
+// the low bit of the temperature register is set in extended mode.
+const tmp102TempExtended = 0x0001
+
+// ErrInvalidConversionRate is returned when the conversion rate of the
+// TMP102 is set to an unknown rate.
+var ErrInvalidConversionRate = errors.New("Invalid conversion rate")
+
+// TMP102ConversionRate is the rate of the continuous conversions of the
+// TMP102.
+type TMP102ConversionRate uint8
+
+const (
+	// TMP102Rate0_25Hz is a conversion every 4 seconds.
+	TMP102Rate0_25Hz TMP102ConversionRate = iota
+	// TMP102Rate1Hz is a conversion every second.
+	TMP102Rate1Hz
+	// TMP102Rate4Hz is 4 conversions a second, the default of the TMP102.
+	TMP102Rate4Hz
+	// TMP102Rate8Hz is 8 conversions a second.
+	TMP102Rate8Hz
+)
+
+// TMP102FaultQueue is the number of consecutive conversions beyond the
+// limits which trigger the alert.
+type TMP102FaultQueue uint8
+
+const (
+	// TMP102Faults1 triggers the alert at the first fault, the default.
+	TMP102Faults1 TMP102FaultQueue = iota
+	// TMP102Faults2 triggers the alert after 2 consecutive faults.
+	TMP102Faults2
+	// TMP102Faults4 triggers the alert after 4 consecutive faults.
+	TMP102Faults4
+	// TMP102Faults6 triggers the alert after 6 consecutive faults.
+	TMP102Faults6
+)
+
+// TMP102AlertConfig is the configuration of the alert output of the
+// TMP102.
+type TMP102AlertConfig struct {
+	// ActiveHigh makes the output active high, rather than active low for an
+	// open drain with a pull-up.
+	ActiveHigh bool
+	// Interrupt makes the output an interrupt, asserted above the high limit
+	// until read and then below the low limit until read, rather than
+	// a thermostat asserted from above the high limit until below the low
+	// limit.
+	Interrupt bool
+	// FaultQueue is the number of consecutive faults which trigger the
+	// alert.
+	FaultQueue TMP102FaultQueue
+}
+
+// TMP102Driver is the gobot driver for the Texas Instruments TMP102 digital
+// temperature sensor, with a 0.0625 degree resolution.
+// Device datasheet: http://www.ti.com/lit/ds/symlink/tmp102.pdf
+//
+// The temperature is 12 bit, from -128 to 127.9375 degrees, or 13 bit in
+// extended mode, for the temperatures above 128 degrees up to the 150 degrees
+// of the sensor. It is measured on demand, or polled and published as
+// a Temperature event when a poll interval is set.
+type TMP102Driver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	gobot.Eventer
+	rate     TMP102ConversionRate
+	extended bool
+	interval time.Duration
+	poller   poller
+	mutex    *sync.Mutex
+}
+
+// NewTMP102Driver creates a new driver with the i2c interface for the TMP102 device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver, 0x48 to 0x4B following ADD0
+//		i2c.WithTMP102ConversionRate(TMP102ConversionRate):	conversion rate, defaults to TMP102Rate4Hz
+//		i2c.WithTMP102ExtendedMode(bool):	13 bit extended mode, defaults to false
+//		i2c.WithTMP102PollInterval(time.Duration):	interval of the Temperature events, defaults to 0 that is no polling
+//
+func NewTMP102Driver(c Connector, options ...func(Config)) *TMP102Driver {
+	t := &TMP102Driver{
+		name:      gobot.DefaultName("TMP102"),
+		connector: c,
+		Config:    NewConfig(),
+		Eventer:   gobot.NewEventer(),
+		rate:      TMP102Rate4Hz,
+		mutex:     &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	t.AddEvent(Temperature)
+	t.AddEvent(Error)
+	return t
+}
+
+// WithTMP102ConversionRate option sets the rate of the conversions. Unknown
+// rates are ignored.
+func WithTMP102ConversionRate(val TMP102ConversionRate) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*TMP102Driver)
+		if ok && val <= TMP102Rate8Hz {
+			d.rate = val
+		}
+	}
+}
+
+// WithTMP102ExtendedMode option sets the 13 bit extended mode, measuring
+// above 128 degrees.
+func WithTMP102ExtendedMode(val bool) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*TMP102Driver)
+		if ok {
+			d.extended = val
+		}
+	}
+}
+
+// WithTMP102PollInterval option sets the interval at which the driver
+// measures the temperature after Start, and publishes it in a Temperature
+// event, or an Error event.
+func WithTMP102PollInterval(val time.Duration) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*TMP102Driver)
+		if ok && val > 0 {
+			d.interval = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *TMP102Driver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *TMP102Driver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *TMP102Driver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start checks the device, sets its conversion rate and mode, and starts
+// polling the temperature if a poll interval is set. It returns
+// ErrAlreadyStarted until Halt once started.
+func (d *TMP102Driver) Start() (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.poller.started {
+		return ErrAlreadyStarted
+	}
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(tmp102Address)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return d.poller.start(d.interval, d.poll)
+}
+
+func (d *TMP102Driver) initialization() (err error) {
+	var config uint16
+	if config, err = ReadWord(d.connection, tmp102RegisterConfig, binary.BigEndian); err != nil {
+		return err
+	}
+	// the TMP102 has no identification register, but its resolution bits
+	// always read 1.
+	if config&tmp102ConfigResolution != tmp102ConfigResolution {
+		return fmt.Errorf("TMP102 device not found (config 0x%04X)", config)
+	}
+	config &^= tmp102ConfigRate | tmp102ConfigExtended
+	config |= uint16(d.rate) << tmp102ConfigRateShift
+	if d.extended {
+		config |= tmp102ConfigExtended
+	}
+	return WriteWord(d.connection, tmp102RegisterConfig, config, binary.BigEndian)
+}
+
+// Halt stops polling the temperature, if it was, after which the driver can
+// be started again.
+func (d *TMP102Driver) Halt() (err error) {
+	d.mutex.Lock()
+	wait := d.poller.stop()
+	d.mutex.Unlock()
+
+	// the poll locks the mutex to measure, thus is waited for without it.
+	wait()
+	return nil
+}
+
+// Temperature returns the last converted temperature, in celsius degrees.
+func (d *TMP102Driver) Temperature() (temp float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var val uint16
+	if val, err = ReadWord(d.connection, tmp102RegisterTemp, binary.BigEndian); err != nil {
+		return 0, err
+	}
+	return tmp102DecodeTemp(val), nil
+}
+
+// SetConversionRate sets the rate of the conversions, or returns
+// ErrInvalidConversionRate for an unknown rate.
+func (d *TMP102Driver) SetConversionRate(rate TMP102ConversionRate) error {
+	if rate > TMP102Rate8Hz {
+		return ErrInvalidConversionRate
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.updateConfig(tmp102ConfigRate, uint16(rate)<<tmp102ConfigRateShift); err != nil {
+		return err
+	}
+	d.rate = rate
+	return nil
+}
+
+// SetExtendedMode sets the 13 bit extended mode. The limits of the alert are
+// in the format of the mode, so are to be set again after a change.
+func (d *TMP102Driver) SetExtendedMode(extended bool) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var bits uint16
+	if extended {
+		bits = tmp102ConfigExtended
+	}
+	if err := d.updateConfig(tmp102ConfigExtended, bits); err != nil {
+		return err
+	}
+	d.extended = extended
+	return nil
+}
+
+// SetAlertLimits sets the low and high limits of the alert, in celsius
+// degrees, rounded to 0.0625 degree. It returns ErrInvalidTemperatureLimit
+// for limits beyond the range of the mode.
+func (d *TMP102Driver) SetAlertLimits(low, high float32) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	lowVal, ok := tmp102EncodeLimit(low, d.extended)
+	if !ok {
+		return ErrInvalidTemperatureLimit
+	}
+	highVal, ok := tmp102EncodeLimit(high, d.extended)
+	if !ok {
+		return ErrInvalidTemperatureLimit
+	}
+	if err := WriteWord(d.connection, tmp102RegisterLow, lowVal, binary.BigEndian); err != nil {
+		return err
+	}
+	return WriteWord(d.connection, tmp102RegisterHigh, highVal, binary.BigEndian)
+}
+
+// AlertLimits returns the low and high limits of the alert, in celsius
+// degrees.
+func (d *TMP102Driver) AlertLimits() (low, high float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var lowVal, highVal uint16
+	if lowVal, err = ReadWord(d.connection, tmp102RegisterLow, binary.BigEndian); err != nil {
+		return 0, 0, err
+	}
+	if highVal, err = ReadWord(d.connection, tmp102RegisterHigh, binary.BigEndian); err != nil {
+		return 0, 0, err
+	}
+	return tmp102DecodeLimit(lowVal, d.extended), tmp102DecodeLimit(highVal, d.extended), nil
+}
+
+// SetAlertConfig sets the configuration of the alert output.
+func (d *TMP102Driver) SetAlertConfig(config TMP102AlertConfig) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	bits := uint16(config.FaultQueue&0x03) << tmp102ConfigFaultQueueShift
+	if config.ActiveHigh {
+		bits |= tmp102ConfigActiveHigh
+	}
+	if config.Interrupt {
+		bits |= tmp102ConfigInterrupt
+	}
+	return d.updateConfig(tmp102ConfigFaultQueue|tmp102ConfigActiveHigh|tmp102ConfigInterrupt, bits)
+}
+
+// Alert returns whether the alert is active, whatever the polarity of the
+// output.
+func (d *TMP102Driver) Alert() (bool, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	config, err := ReadWord(d.connection, tmp102RegisterConfig, binary.BigEndian)
+	if err != nil {
+		return false, err
+	}
+	// the alert bit reads as the polarity bit when active.
+	return (config&tmp102ConfigAlert != 0) == (config&tmp102ConfigActiveHigh != 0), nil
+}
+
+// updateConfig sets the bits of the mask of the configuration register to
+// the bits, keeping the others.
+func (d *TMP102Driver) updateConfig(mask uint16, bits uint16) error {
+	config, err := ReadWord(d.connection, tmp102RegisterConfig, binary.BigEndian)
+	if err != nil {
+		return err
+	}
+	return WriteWord(d.connection, tmp102RegisterConfig, config&^mask|bits, binary.BigEndian)
+}
+
+func (d *TMP102Driver) poll() {
+	temp, err := d.Temperature()
+	if err != nil {
+		d.Publish(d.Event(Error), err)
+	} else {
+		d.Publish(d.Event(Temperature), temp)
+	}
+}
+
+// tmp102DecodeTemp returns the temperature, in celsius degrees, of the value
+// of the temperature register, in 12 bit or, flagged by its low bit, 13 bit
+// two's complement sixteenths of degree.
+func tmp102DecodeTemp(val uint16) float32 {
+	return tmp102DecodeLimit(val, val&tmp102TempExtended != 0)
+}
+
+// tmp102DecodeLimit returns the temperature, in celsius degrees, of the value
+// of a limit register in the format of the mode.
+func tmp102DecodeLimit(val uint16, extended bool) float32 {
+	if extended {
+		return float32(int16(val)>>3) / 16
+	}
+	return float32(int16(val)>>4) / 16
+}
+
+// tmp102EncodeLimit returns the value of a limit register for the
+// temperature, in celsius degrees, rounded to 0.0625 degree, and whether the
+// temperature is in the range of the mode.
+func tmp102EncodeLimit(temp float32, extended bool) (uint16, bool) {
+	shift, max := uint(4), 2048.0
+	if extended {
+		shift, max = 3, 4096.0
+	}
+	sixteenths := math.Floor(float64(temp)*16 + 0.5)
+	if !(sixteenths >= -max && sixteenths < max) {
+		return 0, false
+	}
+	return uint16(int16(sixteenths) << shift), true
+}
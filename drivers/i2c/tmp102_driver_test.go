@@ -0,0 +1,269 @@
+package i2c
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*TMP102Driver)(nil)
+
+// --------- HELPERS
+func initTestTMP102Driver() (driver *TMP102Driver) {
+	driver, _ = initTestTMP102DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestTMP102DriverWithStubbedAdaptor(options ...func(Config)) (*TMP102Driver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewTMP102Driver(adaptor, options...), adaptor
+}
+
+// newTMP102TestDevice returns a TMP102 in its power-up state, measuring 25
+// celsius degrees.
+func newTMP102TestDevice(adaptor *i2cTestAdaptor) *i2cTestWordDevice {
+	return newI2cTestWordDevice(adaptor, map[byte]uint16{
+		tmp102RegisterTemp:   0x1900,
+		tmp102RegisterConfig: 0x60A0,
+		tmp102RegisterLow:    0x4B00,
+		tmp102RegisterHigh:   0x5000,
+	})
+}
+
+// --------- TESTS
+
+func TestNewTMP102Driver(t *testing.T) {
+	// Does it return a pointer to an instance of TMP102Driver?
+	var tmp102 interface{} = NewTMP102Driver(newI2cTestAdaptor())
+	_, ok := tmp102.(*TMP102Driver)
+	if !ok {
+		t.Errorf("NewTMP102Driver() should have returned a *TMP102Driver")
+	}
+
+	d := NewTMP102Driver(newI2cTestAdaptor())
+	gobottest.Refute(t, d.Connection(), nil)
+	gobottest.Assert(t, d.rate, TMP102Rate4Hz)
+	gobottest.Assert(t, d.extended, false)
+}
+
+func TestTMP102DriverOptions(t *testing.T) {
+	d := NewTMP102Driver(newI2cTestAdaptor(), WithBus(2), WithTMP102ConversionRate(TMP102Rate8Hz),
+		WithTMP102ExtendedMode(true), WithTMP102PollInterval(time.Second))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.rate, TMP102Rate8Hz)
+	gobottest.Assert(t, d.extended, true)
+	gobottest.Assert(t, d.interval, time.Second)
+
+	d = NewTMP102Driver(newI2cTestAdaptor(), WithTMP102ConversionRate(4), WithTMP102PollInterval(-1))
+	gobottest.Assert(t, d.rate, TMP102Rate4Hz)
+	gobottest.Assert(t, d.interval, time.Duration(0))
+}
+
+func TestTMP102DriverSetName(t *testing.T) {
+	d := initTestTMP102Driver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "TESTME"), true)
+}
+
+func TestTMP102DriverStart(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor(WithTMP102ConversionRate(TMP102Rate1Hz),
+		WithTMP102ExtendedMode(true))
+	dev := newTMP102TestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, adaptor.address, 0x48)
+	gobottest.Assert(t, dev.get(tmp102RegisterConfig), uint16(0x6070))
+	gobottest.Assert(t, d.Start(), ErrAlreadyStarted)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestTMP102DriverStartConnectError(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestTMP102DriverStartNotFound(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor()
+	dev := newTMP102TestDevice(adaptor)
+	dev.set(tmp102RegisterConfig, 0x0000)
+	gobottest.Assert(t, d.Start(), errors.New("TMP102 device not found (config 0x0000)"))
+}
+
+func TestTMP102DriverTemperature(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor()
+	dev := newTMP102TestDevice(adaptor)
+	d.Start()
+	temp, err := d.Temperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temp, float32(25))
+
+	// 150 degrees, in extended mode.
+	dev.set(tmp102RegisterTemp, 0x4B01)
+	temp, _ = d.Temperature()
+	gobottest.Assert(t, temp, float32(150))
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err = d.Temperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestTMP102DecodeTemp(t *testing.T) {
+	tests := []struct {
+		val  uint16
+		temp float32
+	}{
+		// 12 bit.
+		{0x7FF0, 127.9375},
+		{0x6400, 100},
+		{0x1900, 25},
+		{0x0040, 0.25},
+		{0x0010, 0.0625},
+		{0x0000, 0},
+		{0xFFF0, -0.0625},
+		{0xFF00, -1},
+		{0xE700, -25},
+		{0xC900, -55},
+		{0x8000, -128},
+		// 13 bit, flagged by the low bit.
+		{0x4B01, 150},
+		{0x4001, 128},
+		{0x0C81, 25},
+		{0xFFF9, -0.0625},
+		{0xE481, -55},
+	}
+	for _, test := range tests {
+		gobottest.Assert(t, tmp102DecodeTemp(test.val), test.temp)
+	}
+}
+
+func TestTMP102EncodeLimit(t *testing.T) {
+	tests := []struct {
+		temp     float32
+		extended bool
+		val      uint16
+		ok       bool
+	}{
+		{75, false, 0x4B00, true},
+		{80, false, 0x5000, true},
+		{-10, false, 0xF600, true},
+		{-0.0625, false, 0xFFF0, true},
+		{127.9375, false, 0x7FF0, true},
+		{-128, false, 0x8000, true},
+		{128, false, 0, false},
+		{80, true, 0x2800, true},
+		{150, true, 0x4B00, true},
+		{-55, true, 0xE480, true},
+		{256, true, 0, false},
+		{float32(math.NaN()), true, 0, false},
+	}
+	for _, test := range tests {
+		val, ok := tmp102EncodeLimit(test.temp, test.extended)
+		gobottest.Assert(t, ok, test.ok)
+		gobottest.Assert(t, val, test.val)
+		if ok {
+			gobottest.Assert(t, tmp102DecodeLimit(val, test.extended), test.temp)
+		}
+	}
+}
+
+func TestTMP102DriverSetConversionRate(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor()
+	dev := newTMP102TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetConversionRate(TMP102Rate0_25Hz), nil)
+	gobottest.Assert(t, dev.get(tmp102RegisterConfig), uint16(0x6020))
+	gobottest.Assert(t, d.rate, TMP102Rate0_25Hz)
+	gobottest.Assert(t, d.SetConversionRate(4), ErrInvalidConversionRate)
+	gobottest.Assert(t, d.rate, TMP102Rate0_25Hz)
+}
+
+func TestTMP102DriverAlertLimits(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor()
+	dev := newTMP102TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetAlertLimits(-10, 30.5), nil)
+	gobottest.Assert(t, dev.get(tmp102RegisterLow), uint16(0xF600))
+	gobottest.Assert(t, dev.get(tmp102RegisterHigh), uint16(0x1E80))
+	low, high, err := d.AlertLimits()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, low, float32(-10))
+	gobottest.Assert(t, high, float32(30.5))
+
+	// no limit is written when any is invalid.
+	gobottest.Assert(t, d.SetAlertLimits(0, 130), ErrInvalidTemperatureLimit)
+	gobottest.Assert(t, dev.get(tmp102RegisterLow), uint16(0xF600))
+
+	// the limits are 13 bit in extended mode.
+	gobottest.Assert(t, d.SetExtendedMode(true), nil)
+	gobottest.Assert(t, dev.get(tmp102RegisterConfig)&tmp102ConfigExtended, uint16(tmp102ConfigExtended))
+	gobottest.Assert(t, d.SetAlertLimits(0, 130), nil)
+	gobottest.Assert(t, dev.get(tmp102RegisterHigh), uint16(0x4100))
+	_, high, _ = d.AlertLimits()
+	gobottest.Assert(t, high, float32(130))
+}
+
+func TestTMP102DriverAlertConfig(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor()
+	dev := newTMP102TestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetAlertConfig(TMP102AlertConfig{
+		ActiveHigh: true,
+		Interrupt:  true,
+		FaultQueue: TMP102Faults4,
+	}), nil)
+	gobottest.Assert(t, dev.get(tmp102RegisterConfig), uint16(0x76A0))
+
+	gobottest.Assert(t, d.SetAlertConfig(TMP102AlertConfig{}), nil)
+	gobottest.Assert(t, dev.get(tmp102RegisterConfig), uint16(0x60A0))
+}
+
+func TestTMP102DriverAlert(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor()
+	dev := newTMP102TestDevice(adaptor)
+	d.Start()
+	// the alert bit reads 1 when inactive, active low.
+	alert, err := d.Alert()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, alert, false)
+	dev.set(tmp102RegisterConfig, 0x6080)
+	alert, _ = d.Alert()
+	gobottest.Assert(t, alert, true)
+
+	// and 0 when inactive, active high.
+	dev.set(tmp102RegisterConfig, 0x6480)
+	alert, _ = d.Alert()
+	gobottest.Assert(t, alert, false)
+	dev.set(tmp102RegisterConfig, 0x64A0)
+	alert, _ = d.Alert()
+	gobottest.Assert(t, alert, true)
+}
+
+func TestTMP102DriverPoll(t *testing.T) {
+	d, adaptor := initTestTMP102DriverWithStubbedAdaptor(WithTMP102PollInterval(time.Millisecond))
+	newTMP102TestDevice(adaptor)
+
+	sem := make(chan float32, 1)
+	d.Once(d.Event(Temperature), func(data interface{}) {
+		sem <- data.(float32)
+	})
+	gobottest.Assert(t, d.Start(), nil)
+
+	select {
+	case temp := <-sem:
+		gobottest.Assert(t, temp, float32(25))
+	case <-time.After(1 * time.Second):
+		t.Errorf("TMP102 Event \"temperature\" was not published")
+	}
+
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, d.poller.halt == nil, true)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+}
@@ -0,0 +1,773 @@
+package i2c
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+const vl53l0xAddress = 0x29
+
+const vl53l0xRegisterSysrangeStart = 0x00
+const vl53l0xRegisterSystemSequenceConfig = 0x01
+const vl53l0xRegisterSystemIntermeasurementPeriod = 0x04
+const vl53l0xRegisterSystemInterruptConfigGPIO = 0x0A
+const vl53l0xRegisterSystemInterruptClear = 0x0B
+const vl53l0xRegisterResultInterruptStatus = 0x13
+const vl53l0xRegisterResultRange = 0x1E
+const vl53l0xRegisterFinalRangeMinCountRateRtnLimit = 0x44
+const vl53l0xRegisterMSRCConfigTimeoutMacrop = 0x46
+const vl53l0xRegisterPreRangeConfigVCSELPeriod = 0x50
+const vl53l0xRegisterPreRangeConfigTimeoutMacrop = 0x51
+const vl53l0xRegisterMSRCConfigControl = 0x60
+const vl53l0xRegisterFinalRangeConfigVCSELPeriod = 0x70
+const vl53l0xRegisterFinalRangeConfigTimeoutMacrop = 0x71
+const vl53l0xRegisterGPIOHVMuxActiveHigh = 0x84
+const vl53l0xRegisterVHVConfigPadSCLSDAExtsupHV = 0x89
+const vl53l0xRegisterGlobalConfigSpadEnablesRef = 0xB0
+const vl53l0xRegisterGlobalConfigRefEnStartSelect = 0xB6
+const vl53l0xRegisterDynamicSpadNumRequestedRefSpad = 0x4E
+const vl53l0xRegisterDynamicSpadRefEnStartOffset = 0x4F
+const vl53l0xRegisterIdentificationModelID = 0xC0
+const vl53l0xRegisterOscCalibrateVal = 0xF8
+
+const vl53l0xModelID = 0xEE
+
+const vl53l0xDefaultSignalRateLimit = 0.25
+const vl53l0xMaxSignalRateLimit = 511.99
+
+// overheads of the steps of a measurement, in microseconds, from the ST API.
+const vl53l0xStartOverhead = 1910
+const vl53l0xEndOverhead = 960
+const vl53l0xMSRCOverhead = 660
+const vl53l0xTCCOverhead = 590
+const vl53l0xDSSOverhead = 690
+const vl53l0xPreRangeOverhead = 660
+const vl53l0xFinalRangeOverhead = 550
+const vl53l0xMinTimingBudget = 20 * time.Millisecond
+
+const vl53l0xTimeout = 500 * time.Millisecond
+const vl53l0xPollInterval = 1 * time.Millisecond
+
+const (
+	// Range event with the distance, in millimeters, measured in continuous mode
+	Range = "range"
+)
+
+// ErrVL53L0XTimeout is returned when the VL53L0X did not complete an
+// operation in time.
+var ErrVL53L0XTimeout = errors.New("VL53L0X timeout")
+
+// ErrVL53L0XContinuous is returned when a single-shot measurement is requested
+// while the VL53L0X is ranging continuously.
+var ErrVL53L0XContinuous = errors.New("VL53L0X is ranging continuously")
+
+// vl53l0xTuningSettings are the default tuning settings of the ST API, as
+// register and value pairs. Register 0xFF selects the page of the registers.
+var vl53l0xTuningSettings = []byte{
+	0xFF, 0x01, 0x00, 0x00,
+	0xFF, 0x00, 0x09, 0x00, 0x10, 0x00, 0x11, 0x00, 0x24, 0x01, 0x25, 0xFF, 0x75, 0x00,
+	0xFF, 0x01, 0x4E, 0x2C, 0x48, 0x00, 0x30, 0x20,
+	0xFF, 0x00, 0x30, 0x09, 0x54, 0x00, 0x31, 0x04, 0x32, 0x03, 0x40, 0x83, 0x46, 0x25,
+	0x60, 0x00, 0x27, 0x00, 0x50, 0x06, 0x51, 0x00, 0x52, 0x96, 0x56, 0x08, 0x57, 0x30,
+	0x61, 0x00, 0x62, 0x00, 0x64, 0x00, 0x65, 0x00, 0x66, 0xA0,
+	0xFF, 0x01, 0x22, 0x32, 0x47, 0x14, 0x49, 0xFF, 0x4A, 0x00,
+	0xFF, 0x00, 0x7A, 0x0A, 0x7B, 0x00, 0x78, 0x21,
+	0xFF, 0x01, 0x23, 0x34, 0x42, 0x00, 0x44, 0xFF, 0x45, 0x26, 0x46, 0x05, 0x40, 0x40,
+	0x0E, 0x06, 0x20, 0x1A, 0x43, 0x40,
+	0xFF, 0x00, 0x34, 0x03, 0x35, 0x44,
+	0xFF, 0x01, 0x31, 0x04, 0x4B, 0x09, 0x4C, 0x05, 0x4D, 0x04,
+	0xFF, 0x00, 0x44, 0x00, 0x45, 0x20, 0x47, 0x08, 0x48, 0x28, 0x67, 0x00, 0x70, 0x04,
+	0x71, 0x01, 0x72, 0xFE, 0x76, 0x00, 0x77, 0x00,
+	0xFF, 0x01, 0x0D, 0x01,
+	0xFF, 0x00, 0x80, 0x01, 0x01, 0xF8,
+	0xFF, 0x01, 0x8E, 0x01, 0x00, 0x01, 0xFF, 0x00, 0x80, 0x00,
+}
+
+// VL53L0XDriver is the gobot driver for the ST time-of-flight ranging sensor
+// VL53L0X, which measures distances up to 2 meters.
+// Device datasheet: https://www.st.com/resource/en/datasheet/vl53l0x.pdf
+//
+// The initialization follows the ST API, as ported by Pololu:
+// https://github.com/pololu/vl53l0x-arduino
+type VL53L0XDriver struct {
+	name       string
+	connector  Connector
+	connection Connection
+	Config
+	gobot.Eventer
+	signalRateLimit float32
+	timingBudget    time.Duration
+	stopVariable    byte
+	halt            chan bool
+	done            chan bool
+	mutex           *sync.Mutex
+}
+
+// vl53l0xSequenceSteps are the enabled steps of a measurement, and their
+// timeouts.
+type vl53l0xSequenceSteps struct {
+	tcc, msrc, dss, preRange, finalRange bool
+
+	preRangeVCSELPeriod, finalRangeVCSELPeriod uint8
+	msrcDSSTCCMclks, preRangeMclks             uint16
+	msrcDSSTCCUs, preRangeUs, finalRangeUs     uint32
+}
+
+// NewVL53L0XDriver creates a new driver with the i2c interface for the VL53L0X device.
+// Params:
+//		conn Connector - the Adaptor to use with this Driver
+//
+// Optional params:
+//		i2c.WithBus(int):	bus to use with this driver
+//		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithVL53L0XSignalRateLimit(float32):	return signal rate limit, in MCPS, defaults to 0.25
+//		i2c.WithVL53L0XTimingBudget(time.Duration):	time allowed for a measurement, defaults to about 33ms
+//
+func NewVL53L0XDriver(c Connector, options ...func(Config)) *VL53L0XDriver {
+	v := &VL53L0XDriver{
+		name:            gobot.DefaultName("VL53L0X"),
+		connector:       c,
+		Config:          NewConfig(),
+		Eventer:         gobot.NewEventer(),
+		signalRateLimit: vl53l0xDefaultSignalRateLimit,
+		mutex:           &sync.Mutex{},
+	}
+
+	for _, option := range options {
+		option(v)
+	}
+
+	v.AddEvent(Range)
+	v.AddEvent(Error)
+
+	// TODO: expose commands to API
+	return v
+}
+
+// WithVL53L0XSignalRateLimit option sets the return signal rate limit of the
+// VL53L0X, in mega counts per second, from 0 to 511.99. A lower limit extends
+// the range, but increases the inaccuracy.
+func WithVL53L0XSignalRateLimit(val float32) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*VL53L0XDriver)
+		if ok && val >= 0 && val <= vl53l0xMaxSignalRateLimit {
+			d.signalRateLimit = val
+		}
+	}
+}
+
+// WithVL53L0XTimingBudget option sets the time allowed for a measurement of
+// the VL53L0X, at least 20ms. A longer budget gives more accurate measurements.
+func WithVL53L0XTimingBudget(val time.Duration) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*VL53L0XDriver)
+		if ok && val >= vl53l0xMinTimingBudget {
+			d.timingBudget = val
+		}
+	}
+}
+
+// Name returns the name of the device.
+func (d *VL53L0XDriver) Name() string {
+	return d.name
+}
+
+// SetName sets the name of the device.
+func (d *VL53L0XDriver) SetName(n string) {
+	d.name = n
+}
+
+// Connection returns the connection of the device.
+func (d *VL53L0XDriver) Connection() gobot.Connection {
+	return d.connector.(gobot.Connection)
+}
+
+// Start initializes and calibrates the VL53L0X.
+func (d *VL53L0XDriver) Start() (err error) {
+	bus := d.GetBusOrDefault(d.connector.GetDefaultBus())
+	address := d.GetAddressOrDefault(vl53l0xAddress)
+
+	if d.connection, err = d.connector.GetConnection(address, bus); err != nil {
+		return err
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err := d.initialization(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Halt stops the continuous ranging, if any.
+func (d *VL53L0XDriver) Halt() (err error) {
+	return d.StopContinuous()
+}
+
+// Range returns a single-shot measurement of the distance, in millimeters.
+// Distances beyond the range of the sensor are reported as 8190 or more.
+func (d *VL53L0XDriver) Range() (rng uint16, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.halt != nil {
+		return 0, ErrVL53L0XContinuous
+	}
+	if err = d.restoreStopVariable(); err != nil {
+		return 0, err
+	}
+	if err = d.writeRegs(vl53l0xRegisterSysrangeStart, 0x01); err != nil {
+		return 0, err
+	}
+	// the start bit is cleared once the measurement has started.
+	if err = d.waitFor(vl53l0xRegisterSysrangeStart, 0x01, false); err != nil {
+		return 0, err
+	}
+	return d.readRange()
+}
+
+// StartContinuous starts ranging continuously, and publishes each distance,
+// in millimeters, in a Range event, or an Error event, with ErrVL53L0XTimeout
+// for a measurement that does not end. With a zero period the
+// measurements are back-to-back, otherwise they start every period, which
+// must be longer than the timing budget.
+func (d *VL53L0XDriver) StartContinuous(period time.Duration) (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.halt != nil {
+		return ErrVL53L0XContinuous
+	}
+	if err = d.restoreStopVariable(); err != nil {
+		return err
+	}
+	if period > 0 {
+		ms := uint32(period / time.Millisecond)
+		var osc uint16
+		if osc, err = d.readReg16(vl53l0xRegisterOscCalibrateVal); err != nil {
+			return err
+		}
+		if osc != 0 {
+			ms *= uint32(osc)
+		}
+		buf := []byte{vl53l0xRegisterSystemIntermeasurementPeriod, byte(ms >> 24), byte(ms >> 16), byte(ms >> 8), byte(ms)}
+		if _, err = d.connection.Write(buf); err != nil {
+			return err
+		}
+		err = d.writeRegs(vl53l0xRegisterSysrangeStart, 0x04)
+	} else {
+		err = d.writeRegs(vl53l0xRegisterSysrangeStart, 0x02)
+	}
+	if err != nil {
+		return err
+	}
+
+	d.halt = make(chan bool)
+	d.done = make(chan bool)
+	go d.rangeContinuous(period, d.halt, d.done)
+	return nil
+}
+
+// StopContinuous stops ranging continuously.
+func (d *VL53L0XDriver) StopContinuous() (err error) {
+	d.mutex.Lock()
+	halt, done := d.halt, d.done
+	d.halt, d.done = nil, nil
+	d.mutex.Unlock()
+
+	if halt == nil {
+		return nil
+	}
+	close(halt)
+	<-done
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.writeRegs(
+		vl53l0xRegisterSysrangeStart, 0x01,
+		0xFF, 0x01, 0x00, 0x00, 0x91, 0x00, 0x00, 0x01, 0xFF, 0x00,
+	)
+}
+
+// SetSignalRateLimit sets the return signal rate limit of the VL53L0X, in
+// mega counts per second, from 0 to 511.99.
+func (d *VL53L0XDriver) SetSignalRateLimit(limit float32) (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err = d.setSignalRateLimit(limit); err != nil {
+		return err
+	}
+	d.signalRateLimit = limit
+	return nil
+}
+
+// TimingBudget returns the time allowed for a measurement of the VL53L0X.
+func (d *VL53L0XDriver) TimingBudget() time.Duration {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.timingBudget
+}
+
+// SetTimingBudget sets the time allowed for a measurement of the VL53L0X, at
+// least 20ms. A longer budget gives more accurate measurements.
+func (d *VL53L0XDriver) SetTimingBudget(budget time.Duration) (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err = d.setMeasurementTimingBudget(budget); err != nil {
+		return err
+	}
+	d.timingBudget = budget
+	return nil
+}
+
+func (d *VL53L0XDriver) initialization() (err error) {
+	var id []byte
+	if id, err = d.read(vl53l0xRegisterIdentificationModelID, 1); err != nil {
+		return err
+	}
+	if id[0] != vl53l0xModelID {
+		return fmt.Errorf("VL53L0X device not found (MODEL_ID 0x%02X)", id[0])
+	}
+
+	// data init: switch the I/O to 2V8 and save the stop variable.
+	if err = d.updateReg(vl53l0xRegisterVHVConfigPadSCLSDAExtsupHV, 0x01, 0x00); err != nil {
+		return err
+	}
+	if err = d.writeRegs(0x88, 0x00, 0x80, 0x01, 0xFF, 0x01, 0x00, 0x00); err != nil {
+		return err
+	}
+	var stop []byte
+	if stop, err = d.read(0x91, 1); err != nil {
+		return err
+	}
+	d.stopVariable = stop[0]
+	if err = d.writeRegs(0x00, 0x01, 0xFF, 0x00, 0x80, 0x00); err != nil {
+		return err
+	}
+	// disable the MSRC and pre-range signal rate limit checks.
+	if err = d.updateReg(vl53l0xRegisterMSRCConfigControl, 0x12, 0x00); err != nil {
+		return err
+	}
+	if err = d.setSignalRateLimit(d.signalRateLimit); err != nil {
+		return err
+	}
+	if err = d.writeRegs(vl53l0xRegisterSystemSequenceConfig, 0xFF); err != nil {
+		return err
+	}
+
+	// static init: reference SPADs, tuning and interrupt on new sample.
+	if err = d.setReferenceSpads(); err != nil {
+		return err
+	}
+	if err = d.writeRegs(vl53l0xTuningSettings...); err != nil {
+		return err
+	}
+	if err = d.writeRegs(vl53l0xRegisterSystemInterruptConfigGPIO, 0x04); err != nil {
+		return err
+	}
+	if err = d.updateReg(vl53l0xRegisterGPIOHVMuxActiveHigh, 0x00, 0x10); err != nil {
+		return err
+	}
+	if err = d.writeRegs(vl53l0xRegisterSystemInterruptClear, 0x01); err != nil {
+		return err
+	}
+
+	// the timing budget depends on the enabled steps, so it is set again
+	// once they are final.
+	if d.timingBudget == 0 {
+		var budget uint32
+		if budget, err = d.measurementTimingBudget(); err != nil {
+			return err
+		}
+		d.timingBudget = time.Duration(budget) * time.Microsecond
+	}
+	if err = d.writeRegs(vl53l0xRegisterSystemSequenceConfig, 0xE8); err != nil {
+		return err
+	}
+	if err = d.setMeasurementTimingBudget(d.timingBudget); err != nil {
+		return err
+	}
+
+	// reference calibrations, VHV then phase.
+	if err = d.writeRegs(vl53l0xRegisterSystemSequenceConfig, 0x01); err != nil {
+		return err
+	}
+	if err = d.singleRefCalibration(0x40); err != nil {
+		return err
+	}
+	if err = d.writeRegs(vl53l0xRegisterSystemSequenceConfig, 0x02); err != nil {
+		return err
+	}
+	if err = d.singleRefCalibration(0x00); err != nil {
+		return err
+	}
+	return d.writeRegs(vl53l0xRegisterSystemSequenceConfig, 0xE8)
+}
+
+// setReferenceSpads enables the reference SPADs given by the NVM of the
+// VL53L0X.
+func (d *VL53L0XDriver) setReferenceSpads() (err error) {
+	if err = d.writeRegs(0x80, 0x01, 0xFF, 0x01, 0x00, 0x00, 0xFF, 0x06); err != nil {
+		return err
+	}
+	if err = d.updateReg(0x83, 0x04, 0x00); err != nil {
+		return err
+	}
+	if err = d.writeRegs(0xFF, 0x07, 0x81, 0x01, 0x80, 0x01, 0x94, 0x6B, 0x83, 0x00); err != nil {
+		return err
+	}
+	if err = d.waitFor(0x83, 0xFF, true); err != nil {
+		return err
+	}
+	if err = d.writeRegs(0x83, 0x01); err != nil {
+		return err
+	}
+	var info []byte
+	if info, err = d.read(0x92, 1); err != nil {
+		return err
+	}
+	count := info[0] & 0x7F
+	aperture := info[0]&0x80 != 0
+	if err = d.writeRegs(0x81, 0x00, 0xFF, 0x06); err != nil {
+		return err
+	}
+	if err = d.updateReg(0x83, 0x00, 0x04); err != nil {
+		return err
+	}
+	if err = d.writeRegs(0xFF, 0x01, 0x00, 0x01, 0xFF, 0x00, 0x80, 0x00); err != nil {
+		return err
+	}
+
+	var spads []byte
+	if spads, err = d.read(vl53l0xRegisterGlobalConfigSpadEnablesRef, 6); err != nil {
+		return err
+	}
+	if err = d.writeRegs(
+		0xFF, 0x01,
+		vl53l0xRegisterDynamicSpadRefEnStartOffset, 0x00,
+		vl53l0xRegisterDynamicSpadNumRequestedRefSpad, 0x2C,
+		0xFF, 0x00,
+		vl53l0xRegisterGlobalConfigRefEnStartSelect, 0xB4,
+	); err != nil {
+		return err
+	}
+	// the aperture SPADs start at 12, keep only the first count enabled ones.
+	first := 0
+	if aperture {
+		first = 12
+	}
+	var enabled byte
+	for i := 0; i < 48; i++ {
+		if i < first || enabled == count {
+			spads[i/8] &^= 1 << uint(i%8)
+		} else if spads[i/8]>>uint(i%8)&0x01 != 0 {
+			enabled++
+		}
+	}
+	_, err = d.connection.Write(append([]byte{vl53l0xRegisterGlobalConfigSpadEnablesRef}, spads...))
+	return err
+}
+
+func (d *VL53L0XDriver) singleRefCalibration(vhvInit byte) (err error) {
+	if err = d.writeRegs(vl53l0xRegisterSysrangeStart, 0x01|vhvInit); err != nil {
+		return err
+	}
+	if err = d.waitFor(vl53l0xRegisterResultInterruptStatus, 0x07, true); err != nil {
+		return err
+	}
+	return d.writeRegs(vl53l0xRegisterSystemInterruptClear, 0x01, vl53l0xRegisterSysrangeStart, 0x00)
+}
+
+func (d *VL53L0XDriver) setSignalRateLimit(limit float32) error {
+	if limit < 0 || limit > vl53l0xMaxSignalRateLimit {
+		return fmt.Errorf("Invalid VL53L0X signal rate limit %v", limit)
+	}
+	// Q9.7 fixed point.
+	return WriteWord(d.connection, vl53l0xRegisterFinalRangeMinCountRateRtnLimit, uint16(limit*(1<<7)), binary.BigEndian)
+}
+
+// measurementTimingBudget returns the timing budget of the VL53L0X, in
+// microseconds, computed from the timeouts of its enabled steps.
+func (d *VL53L0XDriver) measurementTimingBudget() (uint32, error) {
+	steps, err := d.sequenceSteps()
+	if err != nil {
+		return 0, err
+	}
+	budget := steps.overhead()
+	if steps.finalRange {
+		budget += steps.finalRangeUs + vl53l0xFinalRangeOverhead
+	}
+	return budget, nil
+}
+
+func (d *VL53L0XDriver) setMeasurementTimingBudget(budget time.Duration) error {
+	if budget < vl53l0xMinTimingBudget {
+		return fmt.Errorf("Invalid VL53L0X timing budget %v", budget)
+	}
+	steps, err := d.sequenceSteps()
+	if err != nil {
+		return err
+	}
+	if !steps.finalRange {
+		return nil
+	}
+
+	// the final range step gets what is left of the budget.
+	budgetUs := uint32(budget / time.Microsecond)
+	used := steps.overhead() + vl53l0xFinalRangeOverhead
+	if used > budgetUs {
+		return fmt.Errorf("Invalid VL53L0X timing budget %v", budget)
+	}
+	mclks := vl53l0xMicrosecondsToMclks(budgetUs-used, steps.finalRangeVCSELPeriod)
+	if steps.preRange {
+		mclks += uint32(steps.preRangeMclks)
+	}
+	return WriteWord(d.connection, vl53l0xRegisterFinalRangeConfigTimeoutMacrop, vl53l0xEncodeTimeout(mclks), binary.BigEndian)
+}
+
+func (d *VL53L0XDriver) sequenceSteps() (steps vl53l0xSequenceSteps, err error) {
+	var data []byte
+	if data, err = d.read(vl53l0xRegisterSystemSequenceConfig, 1); err != nil {
+		return steps, err
+	}
+	config := data[0]
+	steps.tcc = config&0x10 != 0
+	steps.dss = config&0x08 != 0
+	steps.msrc = config&0x04 != 0
+	steps.preRange = config&0x40 != 0
+	steps.finalRange = config&0x80 != 0
+
+	if data, err = d.read(vl53l0xRegisterPreRangeConfigVCSELPeriod, 1); err != nil {
+		return steps, err
+	}
+	steps.preRangeVCSELPeriod = vl53l0xDecodeVCSELPeriod(data[0])
+	if data, err = d.read(vl53l0xRegisterMSRCConfigTimeoutMacrop, 1); err != nil {
+		return steps, err
+	}
+	steps.msrcDSSTCCMclks = uint16(data[0]) + 1
+	steps.msrcDSSTCCUs = vl53l0xMclksToMicroseconds(uint32(steps.msrcDSSTCCMclks), steps.preRangeVCSELPeriod)
+
+	var timeout uint16
+	if timeout, err = d.readReg16(vl53l0xRegisterPreRangeConfigTimeoutMacrop); err != nil {
+		return steps, err
+	}
+	steps.preRangeMclks = vl53l0xDecodeTimeout(timeout)
+	steps.preRangeUs = vl53l0xMclksToMicroseconds(uint32(steps.preRangeMclks), steps.preRangeVCSELPeriod)
+
+	if data, err = d.read(vl53l0xRegisterFinalRangeConfigVCSELPeriod, 1); err != nil {
+		return steps, err
+	}
+	steps.finalRangeVCSELPeriod = vl53l0xDecodeVCSELPeriod(data[0])
+	if timeout, err = d.readReg16(vl53l0xRegisterFinalRangeConfigTimeoutMacrop); err != nil {
+		return steps, err
+	}
+	// the final range timeout includes the pre-range one.
+	finalRangeMclks := vl53l0xDecodeTimeout(timeout)
+	if steps.preRange {
+		finalRangeMclks -= steps.preRangeMclks
+	}
+	steps.finalRangeUs = vl53l0xMclksToMicroseconds(uint32(finalRangeMclks), steps.finalRangeVCSELPeriod)
+	return steps, nil
+}
+
+// overhead returns the time, in microseconds, used by the enabled steps
+// before the final range one.
+func (s vl53l0xSequenceSteps) overhead() uint32 {
+	us := uint32(vl53l0xStartOverhead + vl53l0xEndOverhead)
+	if s.tcc {
+		us += s.msrcDSSTCCUs + vl53l0xTCCOverhead
+	}
+	if s.dss {
+		us += 2 * (s.msrcDSSTCCUs + vl53l0xDSSOverhead)
+	} else if s.msrc {
+		us += s.msrcDSSTCCUs + vl53l0xMSRCOverhead
+	}
+	if s.preRange {
+		us += s.preRangeUs + vl53l0xPreRangeOverhead
+	}
+	return us
+}
+
+// rangeContinuous polls the interrupt status for the end of each measurement,
+// checking halt between the polls, and publishes an Error event with
+// ErrVL53L0XTimeout when no measurement ends within the period and the timeout.
+func (d *VL53L0XDriver) rangeContinuous(period time.Duration, halt chan bool, done chan bool) {
+	defer close(done)
+	deadline := time.Now().Add(period + vl53l0xTimeout)
+	for {
+		d.mutex.Lock()
+		rng, ready, err := d.pollRange()
+		d.mutex.Unlock()
+		switch {
+		case err != nil:
+			d.Publish(d.Event(Error), err)
+		case ready:
+			d.Publish(d.Event(Range), rng)
+		case time.Now().After(deadline):
+			err = ErrVL53L0XTimeout
+			d.Publish(d.Event(Error), err)
+		}
+		if err != nil || ready {
+			deadline = time.Now().Add(period + vl53l0xTimeout)
+		}
+		// a failing sensor is polled again only once a measurement is due,
+		// rather than publishing an error at each poll.
+		wait := vl53l0xPollInterval
+		if err != nil {
+			wait = period + vl53l0xTimeout
+		}
+
+		select {
+		case <-halt:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// readRange waits for the end of a measurement and returns its distance.
+func (d *VL53L0XDriver) readRange() (rng uint16, err error) {
+	if err = d.waitFor(vl53l0xRegisterResultInterruptStatus, 0x07, true); err != nil {
+		return 0, err
+	}
+	return d.rangeResult()
+}
+
+// pollRange returns the distance of the measurement that has ended, if any.
+func (d *VL53L0XDriver) pollRange() (rng uint16, ready bool, err error) {
+	data, err := d.read(vl53l0xRegisterResultInterruptStatus, 1)
+	if err != nil {
+		return 0, false, err
+	}
+	if data[0]&0x07 == 0 {
+		return 0, false, nil
+	}
+	if rng, err = d.rangeResult(); err != nil {
+		return 0, false, err
+	}
+	return rng, true, nil
+}
+
+// rangeResult reads the distance of the ended measurement, and clears its
+// interrupt.
+func (d *VL53L0XDriver) rangeResult() (rng uint16, err error) {
+	if rng, err = d.readReg16(vl53l0xRegisterResultRange); err != nil {
+		return 0, err
+	}
+	if err = d.writeRegs(vl53l0xRegisterSystemInterruptClear, 0x01); err != nil {
+		return 0, err
+	}
+	return rng, nil
+}
+
+func (d *VL53L0XDriver) restoreStopVariable() error {
+	return d.writeRegs(0x80, 0x01, 0xFF, 0x01, 0x00, 0x00, 0x91, d.stopVariable, 0x00, 0x01, 0xFF, 0x00, 0x80, 0x00)
+}
+
+// waitFor polls the register until the bits of the mask are, or are not,
+// all cleared.
+func (d *VL53L0XDriver) waitFor(reg byte, mask byte, set bool) error {
+	deadline := time.Now().Add(vl53l0xTimeout)
+	for {
+		data, err := d.read(reg, 1)
+		if err != nil {
+			return err
+		}
+		if (data[0]&mask != 0) == set {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrVL53L0XTimeout
+		}
+		time.Sleep(vl53l0xPollInterval)
+	}
+}
+
+// writeRegs writes the given register and value pairs, one by one.
+func (d *VL53L0XDriver) writeRegs(regs ...byte) error {
+	for i := 0; i+1 < len(regs); i += 2 {
+		if _, err := d.connection.Write([]byte{regs[i], regs[i+1]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateReg sets and clears the given bits of the register.
+func (d *VL53L0XDriver) updateReg(reg byte, set byte, clear byte) error {
+	data, err := d.read(reg, 1)
+	if err != nil {
+		return err
+	}
+	return d.writeRegs(reg, data[0]&^clear|set)
+}
+
+func (d *VL53L0XDriver) readReg16(reg byte) (uint16, error) {
+	return ReadWord(d.connection, reg, binary.BigEndian)
+}
+
+func (d *VL53L0XDriver) read(address byte, n int) ([]byte, error) {
+	if _, err := d.connection.Write([]byte{address}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	bytesRead, err := d.connection.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if bytesRead != n {
+		return nil, ErrNotEnoughBytes
+	}
+	return buf, nil
+}
+
+// vl53l0xDecodeVCSELPeriod returns the VCSEL pulse period, in PCLKs, of the
+// register value.
+func vl53l0xDecodeVCSELPeriod(reg byte) uint8 {
+	return (reg + 1) << 1
+}
+
+// vl53l0xDecodeTimeout returns the timeout, in MCLKs, of the register value,
+// a LSB * 2^MSB + 1 format.
+func vl53l0xDecodeTimeout(reg uint16) uint16 {
+	return (reg&0x00FF)<<(reg>>8) + 1
+}
+
+// vl53l0xEncodeTimeout returns the register value of the timeout, in MCLKs.
+func vl53l0xEncodeTimeout(mclks uint32) uint16 {
+	if mclks == 0 {
+		return 0
+	}
+	lsb := mclks - 1
+	var msb uint16
+	for lsb&0xFFFFFF00 != 0 {
+		lsb >>= 1
+		msb++
+	}
+	return msb<<8 | uint16(lsb&0xFF)
+}
+
+// vl53l0xMacroPeriod returns the macro period, in nanoseconds, of the VCSEL
+// period, in PCLKs.
+func vl53l0xMacroPeriod(vcselPeriod uint8) uint32 {
+	return (2304*uint32(vcselPeriod)*1655 + 500) / 1000
+}
+
+func vl53l0xMclksToMicroseconds(mclks uint32, vcselPeriod uint8) uint32 {
+	macro := vl53l0xMacroPeriod(vcselPeriod)
+	return (mclks*macro + 500) / 1000
+}
+
+func vl53l0xMicrosecondsToMclks(us uint32, vcselPeriod uint8) uint32 {
+	macro := vl53l0xMacroPeriod(vcselPeriod)
+	return (us*1000 + macro/2) / macro
+}
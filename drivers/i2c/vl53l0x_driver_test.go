@@ -0,0 +1,371 @@
+package i2c
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+// the VL53L0XDriver is a Driver
+var _ gobot.Driver = (*VL53L0XDriver)(nil)
+
+// --------- HELPERS
+func initTestVL53L0XDriver() (driver *VL53L0XDriver) {
+	driver, _ = initTestVL53L0XDriverWithStubbedAdaptor()
+	return
+}
+
+func initTestVL53L0XDriverWithStubbedAdaptor() (*VL53L0XDriver, *i2cTestAdaptor) {
+	adaptor := newI2cTestAdaptor()
+	return NewVL53L0XDriver(adaptor), adaptor
+}
+
+// vl53l0xTestDevice simulates the registers of a VL53L0X, in pages selected
+// by register 0xFF. A start of the ranging ends a measurement at once, unless
+// stalled, which sets the interrupt status until the interrupt is cleared;
+// the next measurements of the continuous ranging are ended with measure.
+type vl53l0xTestDevice struct {
+	mtx     sync.Mutex
+	regs    [256][256]byte
+	page    byte
+	reg     byte
+	stalled bool
+	failing bool
+}
+
+func newVL53L0XTestDevice(adaptor *i2cTestAdaptor) *vl53l0xTestDevice {
+	dev := &vl53l0xTestDevice{}
+	dev.regs[0][vl53l0xRegisterIdentificationModelID] = vl53l0xModelID
+	// 300mm
+	dev.regs[0][vl53l0xRegisterResultRange] = 0x01
+	dev.regs[0][vl53l0xRegisterResultRange+1] = 0x2C
+	for i := 0; i < 6; i++ {
+		dev.regs[0][vl53l0xRegisterGlobalConfigSpadEnablesRef+i] = 0xFF
+	}
+	dev.regs[1][0x91] = 0x3C
+	// SPAD info ready, 5 aperture SPADs
+	dev.regs[7][0x83] = 0x10
+	dev.regs[7][0x92] = 0x85
+
+	adaptor.i2cWriteImpl = dev.write
+	adaptor.i2cReadImpl = dev.read
+	return dev
+}
+
+func (dev *vl53l0xTestDevice) write(b []byte) (int, error) {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	dev.reg = b[0]
+	for i, val := range b[1:] {
+		reg := b[0] + byte(i)
+		switch {
+		case reg == 0xFF:
+			dev.page = val
+		case reg == 0x83:
+			// the SPAD info status is read only here
+		case dev.page == 0 && reg == vl53l0xRegisterSysrangeStart:
+			// the start bit clears once the measurement started
+			dev.regs[0][reg] = val &^ 0x01
+			if val != 0 && !dev.stalled {
+				dev.regs[0][vl53l0xRegisterResultInterruptStatus] = 0x07
+			}
+		case dev.page == 0 && reg == vl53l0xRegisterSystemInterruptClear:
+			dev.regs[0][reg] = val
+			if val&0x01 != 0 {
+				dev.regs[0][vl53l0xRegisterResultInterruptStatus] = 0x00
+			}
+		default:
+			dev.regs[dev.page][reg] = val
+		}
+	}
+	return len(b), nil
+}
+
+func (dev *vl53l0xTestDevice) read(b []byte) (int, error) {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	if dev.failing {
+		return 0, errors.New("read error")
+	}
+	for i := range b {
+		b[i] = dev.regs[dev.page][dev.reg+byte(i)]
+	}
+	return len(b), nil
+}
+
+func (dev *vl53l0xTestDevice) get(page byte, reg byte) byte {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	return dev.regs[page][reg]
+}
+
+func (dev *vl53l0xTestDevice) set(page byte, reg byte, val byte) {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	dev.regs[page][reg] = val
+}
+
+// measure ends a measurement of the given distance.
+func (dev *vl53l0xTestDevice) measure(rng uint16) {
+	dev.mtx.Lock()
+	defer dev.mtx.Unlock()
+
+	dev.regs[0][vl53l0xRegisterResultRange] = byte(rng >> 8)
+	dev.regs[0][vl53l0xRegisterResultRange+1] = byte(rng)
+	dev.regs[0][vl53l0xRegisterResultInterruptStatus] = 0x07
+}
+
+// --------- TESTS
+
+func TestNewVL53L0XDriver(t *testing.T) {
+	// Does it return a pointer to an instance of VL53L0XDriver?
+	var vl53l0x interface{} = NewVL53L0XDriver(newI2cTestAdaptor())
+	_, ok := vl53l0x.(*VL53L0XDriver)
+	if !ok {
+		t.Errorf("NewVL53L0XDriver() should have returned a *VL53L0XDriver")
+	}
+}
+
+func TestVL53L0XDriverOptions(t *testing.T) {
+	d := NewVL53L0XDriver(newI2cTestAdaptor(), WithBus(2),
+		WithVL53L0XSignalRateLimit(0.1), WithVL53L0XTimingBudget(200*time.Millisecond))
+	gobottest.Assert(t, d.GetBusOrDefault(1), 2)
+	gobottest.Assert(t, d.signalRateLimit, float32(0.1))
+	gobottest.Assert(t, d.timingBudget, 200*time.Millisecond)
+
+	d = NewVL53L0XDriver(newI2cTestAdaptor(),
+		WithVL53L0XSignalRateLimit(600), WithVL53L0XTimingBudget(10*time.Millisecond))
+	gobottest.Assert(t, d.signalRateLimit, float32(vl53l0xDefaultSignalRateLimit))
+	gobottest.Assert(t, d.timingBudget, time.Duration(0))
+}
+
+func TestVL53L0XDriverSetName(t *testing.T) {
+	d := initTestVL53L0XDriver()
+	d.SetName("TESTME")
+	gobottest.Assert(t, d.Name(), "TESTME")
+}
+
+func TestVL53L0XDriverStart(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, adaptor.address, vl53l0xAddress)
+	gobottest.Assert(t, d.stopVariable, byte(0x3C))
+	// I/O in 2V8 mode, 0.25 MCPS signal rate limit
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterVHVConfigPadSCLSDAExtsupHV), byte(0x01))
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterFinalRangeMinCountRateRtnLimit+1), byte(0x20))
+	// the 5 first aperture SPADs are enabled
+	spads := []byte{}
+	for i := 0; i < 6; i++ {
+		spads = append(spads, dev.get(0, vl53l0xRegisterGlobalConfigSpadEnablesRef+byte(i)))
+	}
+	gobottest.Assert(t, spads, []byte{0x00, 0xF0, 0x01, 0x00, 0x00, 0x00})
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterSystemSequenceConfig), byte(0xE8))
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterSystemInterruptConfigGPIO), byte(0x04))
+	gobottest.Assert(t, d.TimingBudget() > 30*time.Millisecond, true)
+	gobottest.Assert(t, d.TimingBudget() < 35*time.Millisecond, true)
+}
+
+func TestVL53L0XDriverStartModelID(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	dev.set(0, vl53l0xRegisterIdentificationModelID, 0xAA)
+	gobottest.Assert(t, d.Start().Error(), "VL53L0X device not found (MODEL_ID 0xAA)")
+}
+
+func TestVL53L0XDriverStartConnectError(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid i2c connection"))
+}
+
+func TestVL53L0XDriverStartWriteError(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	newVL53L0XTestDevice(adaptor)
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, d.Start(), errors.New("write error"))
+}
+
+func TestVL53L0XDriverHalt(t *testing.T) {
+	d := initTestVL53L0XDriver()
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestVL53L0XDriverRange(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	d.Start()
+	rng, err := d.Range()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, rng, uint16(300))
+	// the stop variable is restored before the measurement
+	gobottest.Assert(t, dev.get(1, 0x91), byte(0x3C))
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterSystemInterruptClear), byte(0x01))
+}
+
+func TestVL53L0XDriverRangeTimeout(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	d.Start()
+	dev.mtx.Lock()
+	dev.stalled = true
+	dev.mtx.Unlock()
+	_, err := d.Range()
+	gobottest.Assert(t, err, ErrVL53L0XTimeout)
+}
+
+func TestVL53L0XDriverContinuous(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	d.Start()
+
+	sem := make(chan uint16, 1)
+	d.On(d.Event(Range), func(data interface{}) {
+		sem <- data.(uint16)
+	})
+	gobottest.Assert(t, d.StartContinuous(0), nil)
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterSysrangeStart), byte(0x02))
+
+	select {
+	case rng := <-sem:
+		gobottest.Assert(t, rng, uint16(300))
+	case <-time.After(1 * time.Second):
+		t.Errorf("VL53L0X Event \"range\" was not published")
+	}
+	// the interrupt is cleared, and the next measurement published.
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterSystemInterruptClear), byte(0x01))
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterResultInterruptStatus), byte(0x00))
+	dev.measure(310)
+	select {
+	case rng := <-sem:
+		gobottest.Assert(t, rng, uint16(310))
+	case <-time.After(1 * time.Second):
+		t.Errorf("VL53L0X Event \"range\" was not published")
+	}
+
+	gobottest.Assert(t, d.StartContinuous(0), ErrVL53L0XContinuous)
+	_, err := d.Range()
+	gobottest.Assert(t, err, ErrVL53L0XContinuous)
+
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, dev.get(1, 0x91), byte(0x00))
+	_, err = d.Range()
+	gobottest.Assert(t, err, nil)
+}
+
+func TestVL53L0XDriverContinuousTimeout(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	d.Start()
+	dev.mtx.Lock()
+	dev.stalled = true
+	dev.mtx.Unlock()
+
+	sem := make(chan error, 1)
+	d.Once(d.Event(Error), func(data interface{}) {
+		sem <- data.(error)
+	})
+	gobottest.Assert(t, d.StartContinuous(0), nil)
+
+	select {
+	case err := <-sem:
+		gobottest.Assert(t, err, ErrVL53L0XTimeout)
+	case <-time.After(1 * time.Second):
+		t.Errorf("VL53L0X Event \"error\" was not published")
+	}
+
+	// the halt is not held up by the wait for the next measurement.
+	start := time.Now()
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, time.Since(start) < vl53l0xTimeout, true)
+}
+
+func TestVL53L0XDriverContinuousBusError(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	d.Start()
+	dev.mtx.Lock()
+	dev.stalled = true
+	dev.mtx.Unlock()
+
+	var mtx sync.Mutex
+	errs := 0
+	d.On(d.Event(Error), func(data interface{}) {
+		mtx.Lock()
+		errs++
+		mtx.Unlock()
+	})
+	gobottest.Assert(t, d.StartContinuous(0), nil)
+	dev.mtx.Lock()
+	dev.failing = true
+	dev.mtx.Unlock()
+
+	// the sensor is polled again after the timeout of a measurement, not at
+	// each poll interval.
+	time.Sleep(vl53l0xTimeout / 2)
+	mtx.Lock()
+	gobottest.Assert(t, errs, 1)
+	mtx.Unlock()
+
+	dev.mtx.Lock()
+	dev.failing = false
+	dev.mtx.Unlock()
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestVL53L0XDriverContinuousTimed(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	d.Start()
+	dev.set(0, vl53l0xRegisterOscCalibrateVal+1, 0x02)
+	gobottest.Assert(t, d.StartContinuous(100*time.Millisecond), nil)
+	defer d.Halt()
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterSysrangeStart), byte(0x04))
+	// the period is in units of the oscillator calibration
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterSystemIntermeasurementPeriod+3), byte(200))
+}
+
+func TestVL53L0XDriverSetSignalRateLimit(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	dev := newVL53L0XTestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetSignalRateLimit(0.5), nil)
+	gobottest.Assert(t, dev.get(0, vl53l0xRegisterFinalRangeMinCountRateRtnLimit+1), byte(0x40))
+	gobottest.Assert(t, d.SetSignalRateLimit(-1).Error(), "Invalid VL53L0X signal rate limit -1")
+	gobottest.Assert(t, d.signalRateLimit, float32(0.5))
+}
+
+func TestVL53L0XDriverSetTimingBudget(t *testing.T) {
+	d, adaptor := initTestVL53L0XDriverWithStubbedAdaptor()
+	newVL53L0XTestDevice(adaptor)
+	d.Start()
+	gobottest.Assert(t, d.SetTimingBudget(200*time.Millisecond), nil)
+	gobottest.Assert(t, d.TimingBudget(), 200*time.Millisecond)
+	// the budget read back only differs by the precision of the timeouts
+	budget, err := d.measurementTimingBudget()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, budget > 198500 && budget < 201500, true)
+
+	gobottest.Assert(t, d.SetTimingBudget(10*time.Millisecond).Error(), "Invalid VL53L0X timing budget 10ms")
+	gobottest.Assert(t, d.TimingBudget(), 200*time.Millisecond)
+}
+
+func TestVL53L0XTimeouts(t *testing.T) {
+	gobottest.Assert(t, vl53l0xDecodeVCSELPeriod(0x04), uint8(10))
+	gobottest.Assert(t, vl53l0xDecodeTimeout(0x01FE), uint16(509))
+	gobottest.Assert(t, vl53l0xEncodeTimeout(509), uint16(0x01FE))
+	gobottest.Assert(t, vl53l0xEncodeTimeout(0), uint16(0))
+	gobottest.Assert(t, vl53l0xMacroPeriod(14), uint32(53384))
+	gobottest.Assert(t, vl53l0xMclksToMicroseconds(509, 10), uint32(19409))
+	gobottest.Assert(t, vl53l0xMicrosecondsToMclks(19409, 10), uint32(509))
+}
@@ -0,0 +1,124 @@
+package i2c
+
+import (
+	"math"
+	"time"
+)
+
+// TemperatureSensor is a driver measuring the temperature, in celsius
+// degrees, like the BMP180 or the HTU21D.
+type TemperatureSensor interface {
+	Temperature() (float32, error)
+}
+
+// PressureSensor is a driver measuring the barometric pressure, in pascals,
+// like the BMP180 or the BMP280.
+type PressureSensor interface {
+	Pressure() (float32, error)
+}
+
+// HumiditySensor is a driver measuring the relative humidity, in percent,
+// like the BME280 or the HTU21D.
+type HumiditySensor interface {
+	Humidity() (float32, error)
+}
+
+// WeatherSnapshot is a sample of all the measurements of a WeatherStation,
+// taken together at Time. The Has fields tell which of the values could be
+// measured or computed with the sensors of the station.
+type WeatherSnapshot struct {
+	Temperature    float32
+	Pressure       float32
+	Humidity       float32
+	DewPoint       float32
+	Altitude       float32
+	HasTemperature bool
+	HasPressure    bool
+	HasHumidity    bool
+	HasDewPoint    bool
+	HasAltitude    bool
+	Time           time.Time
+}
+
+// WeatherStation combines the measurements of several environmental drivers,
+// e.g. a BMP180 for the pressure and an HTU21D for the humidity. The drivers
+// must be started. The pressures are in pascals: those of a BMP180 set to
+// another unit are converted back.
+type WeatherStation struct {
+	temperature      TemperatureSensor
+	pressure         PressureSensor
+	humidity         HumiditySensor
+	seaLevelPressure float32
+}
+
+// NewWeatherStation creates a new WeatherStation measuring each value with
+// the first of the sensors able to, in the given order. A sensor with
+// a SupportsHumidity method returning false, like the BMP280, is not used
+// for the humidity.
+func NewWeatherStation(sensors ...interface{}) *WeatherStation {
+	w := &WeatherStation{seaLevelPressure: bmp180SeaLevelPressure}
+	for _, sensor := range sensors {
+		if s, ok := sensor.(TemperatureSensor); ok && w.temperature == nil {
+			w.temperature = s
+		}
+		if s, ok := sensor.(PressureSensor); ok && w.pressure == nil {
+			w.pressure = s
+		}
+		if s, ok := sensor.(HumiditySensor); ok && w.humidity == nil {
+			if h, ok := sensor.(interface{ SupportsHumidity() bool }); !ok || h.SupportsHumidity() {
+				w.humidity = s
+			}
+		}
+	}
+	return w
+}
+
+// SetSeaLevelPressure sets the pressure at sea level, in pascals, used as
+// the reference for the altitude, or returns ErrInvalidSeaLevelPressure for
+// a pressure that is not positive. It defaults to the standard 101325 Pa.
+func (w *WeatherStation) SetSeaLevelPressure(p float32) error {
+	if p <= 0 {
+		return ErrInvalidSeaLevelPressure
+	}
+	w.seaLevelPressure = p
+	return nil
+}
+
+// Snapshot measures all the values the sensors of the station can, and
+// computes the dew point when both the temperature and a positive humidity
+// are measured, and the altitude when the pressure is. It returns the first
+// error of the sensors.
+func (w *WeatherStation) Snapshot() (s WeatherSnapshot, err error) {
+	if w.temperature != nil {
+		if s.Temperature, err = w.temperature.Temperature(); err != nil {
+			return WeatherSnapshot{}, err
+		}
+		s.HasTemperature = true
+	}
+	if w.pressure != nil {
+		if s.Pressure, err = w.pressure.Pressure(); err != nil {
+			return WeatherSnapshot{}, err
+		}
+		if u, ok := w.pressure.(interface{ PressureUnit() BMP180PressureUnit }); ok {
+			s.Pressure = u.PressureUnit().toPascals(s.Pressure)
+		}
+		s.HasPressure = true
+		s.Altitude = float32(44330.0 * (1.0 - math.Pow(float64(s.Pressure/w.seaLevelPressure), 1/5.255)))
+		s.HasAltitude = true
+	}
+	if w.humidity != nil {
+		if s.Humidity, err = w.humidity.Humidity(); err != nil {
+			return WeatherSnapshot{}, err
+		}
+		s.HasHumidity = true
+	}
+	if s.HasTemperature && s.HasHumidity {
+		// a dry air has no dew point.
+		if dewPoint, err := DewPoint(s.Temperature, s.Humidity); err == nil {
+			s.DewPoint = dewPoint
+			s.HasDewPoint = true
+		}
+	}
+	s.Time = time.Now()
+	return s, nil
+}
@@ -0,0 +1,123 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+// weatherTestSensor is a sensor with a humidity method, which it may not
+// support.
+type weatherTestSensor struct {
+	supportsHumidity bool
+	err              error
+}
+
+func (s *weatherTestSensor) Humidity() (float32, error) { return 50, s.err }
+
+func (s *weatherTestSensor) SupportsHumidity() bool { return s.supportsHumidity }
+
+func initTestWeatherStationSensors() (*BMP180Driver, *HTU21DDriver) {
+	bmp180, bmp180Adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	bmp180Adaptor.i2cReadImpl = bmp180TestReadImpl(bmp180Adaptor)
+	bmp180.Start()
+	htu21d, htu21dAdaptor := initTestHTU21DDriverWithStubbedAdaptor()
+	htu21dAdaptor.i2cReadImpl = htu21dTestReadImpl(htu21dAdaptor)
+	htu21d.Start()
+	return bmp180, htu21d
+}
+
+func TestWeatherStationBMP180(t *testing.T) {
+	bmp180, _ := initTestWeatherStationSensors()
+	s, err := NewWeatherStation(bmp180).Snapshot()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, s.HasTemperature, true)
+	gobottest.Assert(t, s.Temperature, float32(15.0))
+	gobottest.Assert(t, s.HasPressure, true)
+	gobottest.Assert(t, s.Pressure, float32(69964))
+	gobottest.Assert(t, s.HasAltitude, true)
+	gobottest.Assert(t, s.Altitude, float32(3016.6592))
+	// no humidity, thus no dew point.
+	gobottest.Assert(t, s.HasHumidity, false)
+	gobottest.Assert(t, s.HasDewPoint, false)
+	gobottest.Assert(t, s.Time.IsZero(), false)
+}
+
+func TestWeatherStationBMP180HTU21D(t *testing.T) {
+	bmp180, htu21d := initTestWeatherStationSensors()
+	s, err := NewWeatherStation(bmp180, htu21d).Snapshot()
+	gobottest.Assert(t, err, nil)
+	// the temperature comes from the first sensor.
+	gobottest.Assert(t, s.Temperature, float32(15.0))
+	gobottest.Assert(t, s.Pressure, float32(69964))
+	gobottest.Assert(t, s.HasHumidity, true)
+	gobottest.Assert(t, s.Humidity, float32(32.337708))
+	gobottest.Assert(t, s.HasDewPoint, true)
+	dewPoint, _ := DewPoint(15.0, 32.337708)
+	gobottest.Assert(t, s.DewPoint, dewPoint)
+
+	s, err = NewWeatherStation(htu21d, bmp180).Snapshot()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, s.Temperature, float32(24.686401))
+	gobottest.Assert(t, s.Pressure, float32(69964))
+	dewPoint, _ = DewPoint(24.686401, 32.337708)
+	gobottest.Assert(t, s.DewPoint, dewPoint)
+}
+
+func TestWeatherStationHTU21D(t *testing.T) {
+	_, htu21d := initTestWeatherStationSensors()
+	s, err := NewWeatherStation(htu21d).Snapshot()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, s.HasTemperature, true)
+	gobottest.Assert(t, s.HasHumidity, true)
+	gobottest.Assert(t, s.HasDewPoint, true)
+	// no pressure, thus no altitude.
+	gobottest.Assert(t, s.HasPressure, false)
+	gobottest.Assert(t, s.HasAltitude, false)
+}
+
+func TestWeatherStationNoSensor(t *testing.T) {
+	s, err := NewWeatherStation().Snapshot()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, s.HasTemperature || s.HasPressure || s.HasHumidity, false)
+	gobottest.Assert(t, s.HasDewPoint || s.HasAltitude, false)
+}
+
+func TestWeatherStationSupportsHumidity(t *testing.T) {
+	_, htu21d := initTestWeatherStationSensors()
+	s, _ := NewWeatherStation(&weatherTestSensor{supportsHumidity: false}, htu21d).Snapshot()
+	gobottest.Assert(t, s.Humidity, float32(32.337708))
+
+	s, _ = NewWeatherStation(&weatherTestSensor{supportsHumidity: true}, htu21d).Snapshot()
+	gobottest.Assert(t, s.Humidity, float32(50))
+}
+
+func TestWeatherStationSetSeaLevelPressure(t *testing.T) {
+	bmp180, _ := initTestWeatherStationSensors()
+	w := NewWeatherStation(bmp180)
+	gobottest.Assert(t, w.SetSeaLevelPressure(69964), nil)
+	s, _ := w.Snapshot()
+	gobottest.Assert(t, s.Altitude, float32(0))
+
+	gobottest.Assert(t, w.SetSeaLevelPressure(0), ErrInvalidSeaLevelPressure)
+	gobottest.Assert(t, w.SetSeaLevelPressure(-1), ErrInvalidSeaLevelPressure)
+	gobottest.Assert(t, w.seaLevelPressure, float32(69964))
+}
+
+func TestWeatherStationPressureUnit(t *testing.T) {
+	bmp180, _ := initTestWeatherStationSensors()
+	bmp180.SetPressureUnit(BMP180Hectopascal)
+	s, err := NewWeatherStation(bmp180).Snapshot()
+	gobottest.Assert(t, err, nil)
+	// the pressure is converted back to pascals.
+	gobottest.Assert(t, s.Pressure, float32(69964))
+	gobottest.Assert(t, s.Altitude, float32(3016.6592))
+}
+
+func TestWeatherStationError(t *testing.T) {
+	bmp180, _ := initTestWeatherStationSensors()
+	sensor := &weatherTestSensor{supportsHumidity: true, err: errors.New("humidity error")}
+	_, err := NewWeatherStation(bmp180, sensor).Snapshot()
+	gobottest.Assert(t, err, errors.New("humidity error"))
+}